@@ -0,0 +1,552 @@
+package coll
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hairyhenderson/gomplate/data"
+)
+
+// Dict creates a map from pairs of keys and values. Keys are converted to
+// strings, and a missing final value is taken to be the empty string.
+func Dict(v ...interface{}) map[string]interface{} {
+	m := make(map[string]interface{}, (len(v)+1)/2)
+	for i := 0; i < len(v); i += 2 {
+		k := fmt.Sprint(v[i])
+		if i+1 < len(v) {
+			m[k] = v[i+1]
+		} else {
+			m[k] = ""
+		}
+	}
+	return m
+}
+
+// List creates a list from the given values
+func List(v ...interface{}) []interface{} {
+	return v
+}
+
+// Append returns a copy of the list, with the value added to the end
+func Append(v interface{}, list interface{}) ([]interface{}, error) {
+	l, err := toList(list)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, len(l), len(l)+1)
+	copy(out, l)
+	return append(out, v), nil
+}
+
+// Prepend returns a copy of the list, with the value added to the start
+func Prepend(v interface{}, list interface{}) ([]interface{}, error) {
+	l, err := toList(list)
+	if err != nil {
+		return nil, err
+	}
+	return append([]interface{}{v}, l...), nil
+}
+
+// Uniq returns a copy of the list with duplicate values removed, keeping the
+// first of each
+func Uniq(list interface{}) ([]interface{}, error) {
+	l, err := toList(list)
+	if err != nil {
+		return nil, err
+	}
+	out := []interface{}{}
+	for _, v := range l {
+		if !contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+// Flatten flattens nested lists into a single list. The optional depth limits
+// how many levels of nesting are flattened - by default all are.
+func Flatten(args ...interface{}) ([]interface{}, error) {
+	depth := -1
+	var list interface{}
+	switch len(args) {
+	case 1:
+		list = args[0]
+	case 2:
+		d, err := toInt(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("wrong depth for Flatten: %v", err)
+		}
+		depth = d
+		list = args[1]
+	default:
+		return nil, fmt.Errorf("wrong number of args for Flatten: wanted 1 or 2, got %d", len(args))
+	}
+	l, err := toList(list)
+	if err != nil {
+		return nil, err
+	}
+	return flatten(l, depth), nil
+}
+
+func flatten(l []interface{}, depth int) []interface{} {
+	out := []interface{}{}
+	for _, v := range l {
+		if sub, err := toList(v); err == nil && v != nil && depth != 0 {
+			out = append(out, flatten(sub, depth-1)...)
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+// Reverse returns a copy of the list in reverse order
+func Reverse(list interface{}) ([]interface{}, error) {
+	l, err := toList(list)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, len(l))
+	for i, v := range l {
+		out[len(l)-1-i] = v
+	}
+	return out, nil
+}
+
+// Sort returns a sorted copy of the list. Numbers are sorted numerically, and
+// other values by their string representations. When a key is given, the list
+// must contain maps, which are sorted by the value at that key (which may be a
+// dot-separated path, like `metadata.name`).
+func Sort(args ...interface{}) ([]interface{}, error) {
+	key := ""
+	var list interface{}
+	switch len(args) {
+	case 1:
+		list = args[0]
+	case 2:
+		key = fmt.Sprint(args[0])
+		list = args[1]
+	default:
+		return nil, fmt.Errorf("wrong number of args for Sort: wanted 1 or 2, got %d", len(args))
+	}
+	l, err := toList(list)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]interface{}, len(l))
+	copy(out, l)
+	sortKey := func(v interface{}) interface{} {
+		if key == "" {
+			return v
+		}
+		k, _ := lookup(v, key)
+		return k
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return compare(sortKey(out[i]), sortKey(out[j])) < 0
+	})
+	return out, nil
+}
+
+// Keys returns the keys of one or more maps, in order for OrderedMaps (as read
+// by the data package with key order kept), and otherwise sorted. Keys of each
+// map are listed in turn.
+func Keys(in ...interface{}) ([]string, error) {
+	if len(in) == 0 {
+		return nil, fmt.Errorf("need at least one argument")
+	}
+	keys := []string{}
+	for _, m := range in {
+		k, err := keysOf(m)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k...)
+	}
+	return keys, nil
+}
+
+// Values returns the values of one or more maps, in the same order as Keys
+func Values(in ...interface{}) ([]interface{}, error) {
+	if len(in) == 0 {
+		return nil, fmt.Errorf("need at least one argument")
+	}
+	values := []interface{}{}
+	for _, m := range in {
+		keys, err := keysOf(m)
+		if err != nil {
+			return nil, err
+		}
+		sm, _ := toMap(m)
+		for _, k := range keys {
+			values = append(values, sm[k])
+		}
+	}
+	return values, nil
+}
+
+// Merge deeply merges maps together, returning a new map. Values in dst take
+// precedence over those in the srcs, which take precedence over those in later
+// srcs. Nested maps are merged recursively, while other values (including
+// lists) are replaced. When dst is an OrderedMap, so is the result, with keys
+// from the srcs added at the end.
+func Merge(dst interface{}, srcs ...interface{}) (interface{}, error) {
+	if _, err := toMap(dst); err != nil {
+		return nil, err
+	}
+	out := dst
+	if _, ok := dst.(data.OrderedMap); !ok {
+		m, _ := toMap(dst)
+		out = copyMap(m)
+	}
+	for _, src := range srcs {
+		if _, err := toMap(src); err != nil {
+			return nil, err
+		}
+		out = merge(out, src)
+	}
+	return out, nil
+}
+
+// merge - merge src into dst (both maps), returning the result. Plain maps in
+// dst are modified, so must be copies, while OrderedMaps are copied as
+// they're changed.
+func merge(dst, src interface{}) interface{} {
+	keys, _ := keysOf(src)
+	sm, _ := toMap(src)
+	if d, ok := dst.(data.OrderedMap); ok {
+		for _, k := range keys {
+			if !d.Has(k) {
+				d = d.Set(k, sm[k])
+			} else if isMap(d.Get(k)) && isMap(sm[k]) {
+				d = d.Set(k, merge(mergeable(d.Get(k)), sm[k]))
+			}
+		}
+		return d
+	}
+	d, _ := toMap(dst)
+	for _, k := range keys {
+		dv, ok := d[k]
+		if !ok {
+			d[k] = sm[k]
+		} else if isMap(dv) && isMap(sm[k]) {
+			d[k] = merge(mergeable(dv), sm[k])
+		}
+	}
+	return d
+}
+
+// mergeable - a copy of the map which merge can modify
+func mergeable(m interface{}) interface{} {
+	if o, ok := m.(data.OrderedMap); ok {
+		return o
+	}
+	sm, _ := toMap(m)
+	return copyMap(sm)
+}
+
+// Pick returns a copy of the map (given last) containing only the given keys
+func Pick(args ...interface{}) (interface{}, error) {
+	return filterKeys("Pick", args, true)
+}
+
+// Omit returns a copy of the map (given last) without the given keys
+func Omit(args ...interface{}) (interface{}, error) {
+	return filterKeys("Omit", args, false)
+}
+
+// filterKeys - a copy of the map (the last arg), with only the keys listed in
+// the other args when keep is true, or without them otherwise. OrderedMaps
+// stay in order.
+func filterKeys(name string, args []interface{}, keep bool) (interface{}, error) {
+	m, keys, err := mapAndKeys(name, args)
+	if err != nil {
+		return nil, err
+	}
+	if o, ok := args[len(args)-1].(data.OrderedMap); ok {
+		out := data.OrderedMap{}
+		for _, item := range o {
+			if inList(keys, item.Key) == keep {
+				out = append(out, item)
+			}
+		}
+		return out, nil
+	}
+	out := make(map[string]interface{})
+	for k, v := range m {
+		if inList(keys, k) == keep {
+			out[k] = v
+		}
+	}
+	return out, nil
+}
+
+func mapAndKeys(name string, args []interface{}) (map[string]interface{}, []string, error) {
+	if len(args) < 2 {
+		return nil, nil, fmt.Errorf("wrong number of args for %s: wanted 2 or more, got %d", name, len(args))
+	}
+	m, err := toMap(args[len(args)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := []string{}
+	for _, k := range args[:len(args)-1] {
+		if l, err := toList(k); err == nil {
+			for _, item := range l {
+				keys = append(keys, fmt.Sprint(item))
+			}
+			continue
+		}
+		keys = append(keys, fmt.Sprint(k))
+	}
+	return m, keys, nil
+}
+
+// Has reports whether the map or list has a value at the given key. The key
+// can be a dot-separated path (like `a.b.0.c`) to look in nested maps and
+// lists.
+func Has(in interface{}, key string) bool {
+	_, ok := lookup(in, key)
+	return ok
+}
+
+// Where returns the elements of the list (given last) for which the value at
+// the given key (which may be a dot-separated path) matches. An operator
+// (`==`, `!=`, `<`, `<=`, `>`, or `>=`) can be given between the key and the
+// value - the default is `==`.
+func Where(args ...interface{}) ([]interface{}, error) {
+	op := "=="
+	var key string
+	var value, list interface{}
+	switch len(args) {
+	case 3:
+		key, value, list = fmt.Sprint(args[0]), args[1], args[2]
+	case 4:
+		key, op, value, list = fmt.Sprint(args[0]), fmt.Sprint(args[1]), args[2], args[3]
+	default:
+		return nil, fmt.Errorf("wrong number of args for Where: wanted 3 or 4, got %d", len(args))
+	}
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+	default:
+		return nil, fmt.Errorf("unknown operator %q for Where", op)
+	}
+	l, err := toList(list)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []interface{}{}
+	for _, item := range l {
+		v, ok := lookup(item, key)
+		if !ok {
+			continue
+		}
+		c := compare(v, value)
+		match := false
+		switch op {
+		case "==":
+			match = c == 0
+		case "!=":
+			match = c != 0
+		case "<":
+			match = c < 0
+		case "<=":
+			match = c <= 0
+		case ">":
+			match = c > 0
+		case ">=":
+			match = c >= 0
+		}
+		if match {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+// GroupBy groups the elements of the list by the value at the given key (which
+// may be a dot-separated path), returning a map of lists. Elements without the
+// key are left out.
+func GroupBy(key string, list interface{}) (map[string]interface{}, error) {
+	l, err := toList(list)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]interface{})
+	for _, item := range l {
+		v, ok := lookup(item, key)
+		if !ok {
+			continue
+		}
+		k := fmt.Sprint(v)
+		if _, ok := out[k]; !ok {
+			out[k] = []interface{}{}
+		}
+		out[k] = append(out[k].([]interface{}), item)
+	}
+	return out, nil
+}
+
+// lookup - the value at the given key or dot-separated path in nested maps
+// and lists. Keys containing dots are matched directly when possible.
+func lookup(in interface{}, path string) (interface{}, bool) {
+	if v, ok := lookupKey(in, path); ok {
+		return v, true
+	}
+	parts := strings.Split(path, ".")
+	if len(parts) == 1 {
+		return nil, false
+	}
+	for _, p := range parts {
+		v, ok := lookupKey(in, p)
+		if !ok {
+			return nil, false
+		}
+		in = v
+	}
+	return in, true
+}
+
+func lookupKey(in interface{}, key string) (interface{}, bool) {
+	if m, ok := in.(data.OrderedMap); ok {
+		return m.Get(key), m.Has(key)
+	}
+	v := reflect.ValueOf(in)
+	switch v.Kind() {
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			if fmt.Sprint(k.Interface()) == key {
+				return v.MapIndex(k).Interface(), true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(key)
+		if err == nil && i >= 0 && i < v.Len() {
+			return v.Index(i).Interface(), true
+		}
+	}
+	return nil, false
+}
+
+// compare - compare numbers numerically, and other values as strings
+func compare(a, b interface{}) int {
+	af, aok := toFloat(a)
+	bf, bok := toFloat(b)
+	if aok && bok {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func toFloat(in interface{}) (float64, bool) {
+	v := reflect.ValueOf(in)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+func toInt(in interface{}) (int, error) {
+	if f, ok := toFloat(in); ok {
+		return int(f), nil
+	}
+	return strconv.Atoi(fmt.Sprint(in))
+}
+
+func toList(in interface{}) ([]interface{}, error) {
+	if l, ok := in.([]interface{}); ok {
+		return l, nil
+	}
+	v := reflect.ValueOf(in)
+	if _, ok := in.(data.OrderedMap); ok || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+		return nil, fmt.Errorf("expected a list, got %T", in)
+	}
+	out := make([]interface{}, v.Len())
+	for i := range out {
+		out[i] = v.Index(i).Interface()
+	}
+	return out, nil
+}
+
+// toMap - the map with its keys converted to strings
+func toMap(in interface{}) (map[string]interface{}, error) {
+	switch m := in.(type) {
+	case map[string]interface{}:
+		return m, nil
+	case data.OrderedMap:
+		return m.Map(), nil
+	}
+	v := reflect.ValueOf(in)
+	if v.Kind() != reflect.Map {
+		return nil, fmt.Errorf("expected a map, got %T", in)
+	}
+	out := make(map[string]interface{}, v.Len())
+	for _, k := range v.MapKeys() {
+		out[fmt.Sprint(k.Interface())] = v.MapIndex(k).Interface()
+	}
+	return out, nil
+}
+
+// keysOf - the map's keys, in order for an OrderedMap, and otherwise sorted
+func keysOf(in interface{}) ([]string, error) {
+	if m, ok := in.(data.OrderedMap); ok {
+		return m.Keys(), nil
+	}
+	m, err := toMap(in)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func isMap(in interface{}) bool {
+	_, err := toMap(in)
+	return err == nil
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func contains(list []interface{}, v interface{}) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func inList(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package coll
+
+import (
+	"testing"
+
+	"github.com/hairyhenderson/gomplate/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDict(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{}, Dict())
+	assert.Equal(t, map[string]interface{}{"a": 1, "b": ""}, Dict("a", 1, "b"))
+	assert.Equal(t, map[string]interface{}{"1": "one", "true": false}, Dict(1, "one", true, false))
+
+	assert.Equal(t, `{"a":{"b":3,"y":2},"z":1}`, data.ToJSON(Dict("z", 1, "a", Dict("y", 2, "b", 3))))
+}
+
+func TestAppendPrepend(t *testing.T) {
+	in := []interface{}{1, 2}
+	out, err := Append(3, in)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{1, 2, 3}, out)
+	assert.Equal(t, []interface{}{1, 2}, in)
+
+	out, err = Prepend(0, []string{"a"})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{0, "a"}, out)
+
+	_, err = Append(1, "not a list")
+	assert.Error(t, err)
+}
+
+func TestUniqReverseFlatten(t *testing.T) {
+	out, err := Uniq([]interface{}{1, 2, 1, "a", "a", []int{1}, []int{1}})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{1, 2, "a", []int{1}}, out)
+
+	out, err = Reverse([]int{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{3, 2, 1}, out)
+
+	nested := []interface{}{1, []interface{}{2, []interface{}{3, []int{4}}}, []string{}}
+	out, err = Flatten(nested)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{1, 2, 3, 4}, out)
+
+	out, err = Flatten(1, nested)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{1, 2, []interface{}{3, []int{4}}}, out)
+
+	out, err = Flatten(0, nested)
+	assert.NoError(t, err)
+	assert.Equal(t, nested, out)
+
+	_, err = Flatten()
+	assert.Error(t, err)
+}
+
+func TestSort(t *testing.T) {
+	out, err := Sort([]interface{}{10, 2, 1.5, "9"})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{1.5, 2, 10, "9"}, out)
+
+	out, err = Sort([]string{"b", "c", "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "b", "c"}, out)
+
+	in := data.JSONArray(`[
+		{"metadata": {"name": "web"}, "n": 2},
+		{"metadata": {"name": "db"}, "n": 10},
+		{"metadata": {"name": "cache"}, "n": 1}
+	]`)
+	out, err = Sort("metadata.name", in)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"metadata":{"name":"cache"},"n":1},{"metadata":{"name":"db"},"n":10},{"metadata":{"name":"web"},"n":2}]`, data.ToJSON(out))
+
+	out, err = Sort("n", in)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{in[2], in[0], in[1]}, out)
+}
+
+func TestKeysValues(t *testing.T) {
+	in := data.JSONOrdered(`{"z": 1, "a": 2, "m": {"y": 3, "b": 4}}`)
+	keys, err := Keys(in)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"z", "a", "m"}, keys)
+
+	keys, err = Keys(in.Get("m"), map[string]int{"b": 1, "a": 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"y", "b", "a", "b"}, keys)
+
+	values, err := Values(in)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{1, 2, in.Get("m")}, values)
+
+	keys, err = Keys(data.JSON(`{"z": 1, "a": 2}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "z"}, keys)
+
+	values, err = Values(map[string]int{"b": 1, "a": 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{2, 1}, values)
+
+	_, err = Keys("foo")
+	assert.Error(t, err)
+	_, err = Values()
+	assert.Error(t, err)
+}
+
+func TestMerge(t *testing.T) {
+	defaults := data.YAMLOrdered(`
+name: default
+spec:
+  replicas: 1
+  image: nginx
+  ports: [80]
+`)
+	overrides := data.YAMLOrdered(`
+spec:
+  replicas: 3
+  ports: [443]
+labels:
+  env: prod
+`)
+	out, err := Merge(overrides, defaults)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"spec":{"replicas":3,"ports":[443],"image":"nginx"},"labels":{"env":"prod"},"name":"default"}`, data.ToJSON(out))
+
+	// the inputs are unchanged
+	assert.Equal(t, `{"spec":{"replicas":3,"ports":[443]},"labels":{"env":"prod"}}`, data.ToJSON(overrides))
+
+	// plain maps stay plain, and the inputs are unchanged
+	dst := map[string]interface{}{"b": 1, "m": map[string]interface{}{"x": 1}}
+	out, err = Merge(dst, map[string]interface{}{"a": 2, "b": 3, "m": map[string]interface{}{"y": 2}}, map[string]interface{}{"c": 4})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": 2, "b": 1, "c": 4, "m": map[string]interface{}{"x": 1, "y": 2}}, out)
+	assert.Equal(t, map[string]interface{}{"b": 1, "m": map[string]interface{}{"x": 1}}, dst)
+
+	_, err = Merge("foo")
+	assert.Error(t, err)
+}
+
+func TestPickOmit(t *testing.T) {
+	in := data.JSONOrdered(`{"c": 1, "b": 2, "a": 3}`)
+	out, err := Pick("a", "c", in)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"c":1,"a":3}`, data.ToJSON(out))
+
+	out, err = Pick([]string{"b", "x"}, in.Map())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"b": 2}, out)
+
+	out, err = Omit("a", in)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"c":1,"b":2}`, data.ToJSON(out))
+
+	_, err = Omit(in)
+	assert.Error(t, err)
+}
+
+func TestHas(t *testing.T) {
+	in := data.JSON(`{"a": {"b": [{"c": 1}]}, "x.y": true, "n": null}`)
+	assert.True(t, Has(in, "a"))
+	assert.True(t, Has(in, "a.b"))
+	assert.True(t, Has(in, "a.b.0.c"))
+	assert.True(t, Has(in, "x.y"))
+	assert.True(t, Has(in, "n"))
+	assert.False(t, Has(in, "a.b.1"))
+	assert.False(t, Has(in, "a.c"))
+	assert.False(t, Has(in, "z"))
+	assert.False(t, Has("foo", "z"))
+	assert.True(t, Has([]int{1, 2}, "1"))
+
+	ordered := data.JSONOrdered(`{"a": {"b": [{"c": 1}]}}`)
+	assert.True(t, Has(ordered, "a.b.0.c"))
+	assert.False(t, Has(ordered, "0"))
+}
+
+func TestWhereGroupBy(t *testing.T) {
+	in := data.JSONArray(`[
+		{"name": "a", "env": "prod", "size": 3},
+		{"name": "b", "env": "dev", "size": 10},
+		{"name": "c", "env": "prod", "size": 7},
+		{"name": "d"}
+	]`)
+
+	out, err := Where("env", "prod", in)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{in[0], in[2]}, out)
+
+	out, err = Where("size", ">=", 7, in)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{in[1], in[2]}, out)
+
+	out, err = Where("env", "!=", "prod", in)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{in[1]}, out)
+
+	_, err = Where("env", "~", "prod", in)
+	assert.Error(t, err)
+	_, err = Where("env", in)
+	assert.Error(t, err)
+
+	groups, err := GroupBy("env", in)
+	assert.NoError(t, err)
+	assert.Len(t, groups, 2)
+	assert.Equal(t, []interface{}{in[0], in[2]}, groups["prod"])
+	assert.Equal(t, []interface{}{in[1]}, groups["dev"])
+}
@@ -1,13 +1,20 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"strings"
+
+	"github.com/hairyhenderson/gomplate/data"
 )
 
-// Context for templates
-type Context struct {
-}
+// Context for templates. Along with the environment (as .Env), it holds
+// datasources added with --context, values from --set, and information about
+// the template being rendered (as .Template).
+type Context map[string]interface{}
+
+// keys which can't be overridden by --context or --set
+var reservedContextKeys = []string{"Env", "Template"}
 
 // Env - Map environment variables for use in a template
 func (c *Context) Env() map[string]string {
@@ -18,3 +25,111 @@ func (c *Context) Env() map[string]string {
 	}
 	return env
 }
+
+// newContext - build the context shared by all templates, from the
+// datasources named in contexts (which must already be defined in d), then
+// the key=value pairs in sets (with dotted keys setting nested values)
+func newContext(d *data.Data, contexts, sets []string) (Context, error) {
+	c := Context{}
+	for _, arg := range contexts {
+		s, err := data.ParseSource(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid context %s: %v", arg, err)
+		}
+		if isReservedContextKey(s.Alias) {
+			return nil, fmt.Errorf("invalid context %s: %s is reserved", arg, s.Alias)
+		}
+		v, err := d.Datasource(s.Alias)
+		if err != nil {
+			return nil, fmt.Errorf("invalid context %s: %v", arg, err)
+		}
+		c[s.Alias] = v
+	}
+	for _, arg := range sets {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid --set %q: must be in key=value form", arg)
+		}
+		if err := c.set(parts[0], parts[1]); err != nil {
+			return nil, fmt.Errorf("invalid --set %q: %v", arg, err)
+		}
+	}
+	return c, nil
+}
+
+func isReservedContextKey(key string) bool {
+	for _, k := range reservedContextKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// set - set the value at the given dotted path, creating (or replacing) maps
+// along the way as needed
+func (c Context) set(path string, value interface{}) error {
+	keys := strings.Split(path, ".")
+	for _, k := range keys {
+		if k == "" {
+			return fmt.Errorf("empty key in %s", path)
+		}
+	}
+	if isReservedContextKey(keys[0]) {
+		return fmt.Errorf("%s is reserved", keys[0])
+	}
+	var m interface{} = map[string]interface{}(c)
+	for _, k := range keys[:len(keys)-1] {
+		next := mapGet(m, k)
+		switch next.(type) {
+		case map[string]interface{}, map[interface{}]interface{}:
+		default:
+			next = map[string]interface{}{}
+			mapSet(m, k, next)
+		}
+		m = next
+	}
+	mapSet(m, keys[len(keys)-1], value)
+	return nil
+}
+
+// mapGet/mapSet - access either kind of map that datasources can produce
+func mapGet(m interface{}, k string) interface{} {
+	switch m := m.(type) {
+	case map[string]interface{}:
+		return m[k]
+	case map[interface{}]interface{}:
+		return m[k]
+	}
+	return nil
+}
+
+func mapSet(m interface{}, k string, v interface{}) {
+	switch m := m.(type) {
+	case map[string]interface{}:
+		m[k] = v
+	case map[interface{}]interface{}:
+		m[k] = v
+	}
+}
+
+// forTemplate - a copy of the context for rendering the given template
+func (c Context) forTemplate(t *tplate) *Context {
+	out := make(Context, len(c)+1)
+	// values from the template's front matter are defaults, which can be
+	// overridden on the command line
+	if t.frontMatter != nil {
+		for k, v := range t.frontMatter.Context {
+			out[k] = v
+		}
+	}
+	for k, v := range c {
+		out[k] = v
+	}
+	out["Template"] = map[string]interface{}{
+		"Name":   t.name,
+		"Path":   t.path(),
+		"Output": t.targetPath,
+	}
+	return &out
+}
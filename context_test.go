@@ -1,9 +1,11 @@
 package main
 
 import (
+	"io/ioutil"
 	"os"
 	"testing"
 
+	"github.com/hairyhenderson/gomplate/data"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,3 +21,72 @@ func TestEnvGetsUpdatedEnvironment(t *testing.T) {
 	assert.NoError(t, os.Setenv("FOO", "foo"))
 	assert.Equal(t, c.Env()["FOO"], "foo")
 }
+
+func TestContextSet(t *testing.T) {
+	c := Context{}
+	assert.NoError(t, c.set("foo", "bar"))
+	assert.NoError(t, c.set("a.b.c", "1"))
+	assert.NoError(t, c.set("a.b.d", "2"))
+	assert.NoError(t, c.set("a.e", "3"))
+	assert.Equal(t, Context{
+		"foo": "bar",
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{"c": "1", "d": "2"},
+			"e": "3",
+		},
+	}, c)
+
+	// non-map values along the path are replaced
+	assert.NoError(t, c.set("foo.baz", "qux"))
+	assert.Equal(t, map[string]interface{}{"baz": "qux"}, c["foo"])
+
+	assert.Error(t, c.set("a..b", "x"))
+	assert.Error(t, c.set("Env.FOO", "x"))
+	assert.Error(t, c.set("Template", "x"))
+}
+
+func TestNewContext(t *testing.T) {
+	f, err := ioutil.TempFile("", "gomplate-context")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(`{"name": "app", "db": {"host": "localhost", "port": 5432}}`)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	arg := "config=file://" + f.Name() + "?type=application/json"
+	d := data.NewData([]string{arg}, nil)
+	c, err := newContext(d, []string{arg}, []string{"db.host=db.example.com", "replicas=3"})
+	assert.NoError(t, err)
+	assert.Equal(t, "app", c["config"].(map[string]interface{})["name"])
+	assert.Equal(t, "localhost", c["config"].(map[string]interface{})["db"].(map[interface{}]interface{})["host"])
+	assert.Equal(t, map[string]interface{}{"host": "db.example.com"}, c["db"])
+	assert.Equal(t, "3", c["replicas"])
+
+	c, err = newContext(d, []string{arg}, []string{"config.db.host=override"})
+	assert.NoError(t, err)
+	assert.Equal(t, "override", c["config"].(map[string]interface{})["db"].(map[interface{}]interface{})["host"])
+	assert.Equal(t, 5432, c["config"].(map[string]interface{})["db"].(map[interface{}]interface{})["port"])
+
+	_, err = newContext(d, nil, []string{"novalue"})
+	assert.Error(t, err)
+	_, err = newContext(d, nil, []string{"=foo"})
+	assert.Error(t, err)
+	_, err = newContext(d, []string{"Env=file:///tmp/foo.json"}, nil)
+	assert.Error(t, err)
+}
+
+func TestContextForTemplate(t *testing.T) {
+	c := Context{"foo": "bar"}
+	tc := c.forTemplate(&tplate{name: "in/a.tmpl", targetPath: "out/a.tmpl"})
+	assert.Equal(t, "bar", (*tc)["foo"])
+	assert.Equal(t, map[string]interface{}{
+		"Name":   "in/a.tmpl",
+		"Path":   "in/a.tmpl",
+		"Output": "out/a.tmpl",
+	}, (*tc)["Template"])
+	// the shared context isn't modified
+	assert.Nil(t, c["Template"])
+
+	tc = Context(nil).forTemplate(&tplate{name: "<arg>", targetPath: "-"})
+	assert.Equal(t, "", (*tc)["Template"].(map[string]interface{})["Path"])
+}
@@ -1,6 +1,7 @@
 package conv
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
@@ -67,6 +68,12 @@ func interfaceSlice(slice interface{}) ([]interface{}, error) {
 
 // Has determines whether or not a given object has a property with the given key
 func Has(in interface{}, key string) bool {
+	// objects which aren't maps (like data.OrderedMap) can say for themselves
+	if h, ok := in.(interface {
+		Has(string) bool
+	}); ok {
+		return h.Has(key)
+	}
 	av := reflect.ValueOf(in)
 	kv := reflect.ValueOf(key)
 
@@ -234,3 +241,40 @@ func ToFloat64s(in ...interface{}) []float64 {
 	}
 	return out
 }
+
+// Default returns def when in is missing (nil) or an empty string, and
+// otherwise returns in. Other "empty" values like false, 0, and empty slices or
+// maps are returned as-is, since they're usually set deliberately.
+func Default(def, in interface{}) interface{} {
+	if isMissing(in) {
+		return def
+	}
+	return in
+}
+
+// Required returns in, or an error with the given message when in is missing
+// (nil) or an empty string.
+func Required(msg string, in interface{}) (interface{}, error) {
+	if msg == "" {
+		msg = "required value is missing"
+	}
+	if isMissing(in) {
+		return nil, errors.New(msg)
+	}
+	return in, nil
+}
+
+// isMissing - whether the value is nil (including nil pointers) or an empty
+// string
+func isMissing(in interface{}) bool {
+	v := reflect.ValueOf(in)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
@@ -58,6 +58,20 @@ func TestHas(t *testing.T) {
 	assert.True(t, Has(in, "foo"))
 	assert.False(t, Has(in, "bar"))
 	assert.True(t, Has(in["baz"], "qux"))
+
+	assert.True(t, Has(keySet{"foo"}, "foo"))
+	assert.False(t, Has(keySet{"foo"}, "bar"))
+}
+
+type keySet []string
+
+func (s keySet) Has(key string) bool {
+	for _, k := range s {
+		if k == key {
+			return true
+		}
+	}
+	return false
 }
 
 func TestMustParseInt(t *testing.T) {
@@ -167,3 +181,33 @@ func TestToFloat64s(t *testing.T) {
 	assert.Equal(t, []float64{}, ToFloat64s())
 	assert.Equal(t, []float64{0, 1.0, 2.0, math.Pi, 4.0}, ToFloat64s(nil, true, "2", math.Pi, uint8(4)))
 }
+
+func TestDefault(t *testing.T) {
+	missing := []interface{}{nil, "", (*int)(nil)}
+	for _, v := range missing {
+		assert.Equal(t, "foo", Default("foo", v))
+	}
+	// false and 0 are often set deliberately, so they aren't replaced
+	present := []interface{}{"bar", 42, true, 0, 0.0, false, []string{}, map[string]interface{}{},
+		[]string{"a"}, map[string]interface{}{"a": 1}, struct{}{}}
+	for _, v := range present {
+		assert.Equal(t, v, Default("foo", v))
+	}
+}
+
+func TestRequired(t *testing.T) {
+	_, err := Required("foo is required", nil)
+	assert.EqualError(t, err, "foo is required")
+	_, err = Required("foo is required", "")
+	assert.EqualError(t, err, "foo is required")
+	_, err = Required("", nil)
+	assert.EqualError(t, err, "required value is missing")
+	_, err = Required("foo is required", (*int)(nil))
+	assert.EqualError(t, err, "foo is required")
+
+	for _, v := range []interface{}{"bar", 0, false, []string{}} {
+		out, err := Required("foo is required", v)
+		assert.NoError(t, err)
+		assert.Equal(t, v, out)
+	}
+}
@@ -0,0 +1,211 @@
+package data
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// column types supported by CSVTyped
+const (
+	csvString = "string"
+	csvInt    = "int"
+	csvFloat  = "float"
+	csvBool   = "bool"
+	csvDate   = "date"
+)
+
+// date layouts accepted for the "date" column type, in order of preference
+var csvDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// CSVTyped - Unmarshal CSV into typed rows
+// parameters:
+//  delim - (optional) the (single-character!) field delimiter, defaults to ","
+// schema - (optional) comma-separated list of column types, in `name:type`
+//          form, where type is one of string, int, float, bool, or date.
+//          Columns not listed have their types inferred
+//     in - the CSV-format string to parse. The first line is the header,
+//          and lines beginning with `#` are ignored
+// returns:
+//  an array of rows, indexed by the header name. Empty cells in columns of
+//  types other than string are nil
+func CSVTyped(args ...string) []map[string]interface{} {
+	rows, err := parseTypedCSV(args...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return rows
+}
+
+func parseTypedCSV(args ...string) ([]map[string]interface{}, error) {
+	delim := ","
+	schemaSpec := ""
+	var in string
+	switch len(args) {
+	case 1:
+		in = args[0]
+	case 2:
+		in = args[1]
+		if len(args[0]) == 1 {
+			delim = args[0]
+		} else {
+			schemaSpec = args[0]
+		}
+	case 3:
+		delim = args[0]
+		schemaSpec = args[1]
+		in = args[2]
+	default:
+		return nil, fmt.Errorf("CSVTyped requires 1 to 3 arguments, got %d", len(args))
+	}
+	if len(delim) != 1 {
+		return nil, fmt.Errorf("CSVTyped delimiter must be a single character, got %q", delim)
+	}
+
+	schema, err := parseCSVSchema(schemaSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	c := csv.NewReader(strings.NewReader(strings.TrimPrefix(in, "\ufeff")))
+	c.Comma = rune(delim[0])
+	c.Comment = '#'
+	records, err := c.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return []map[string]interface{}{}, nil
+	}
+	hdr := records[0]
+	records = records[1:]
+	for i := range hdr {
+		hdr[i] = strings.TrimSpace(hdr[i])
+	}
+	for name := range schema {
+		if !inStrings(hdr, name) {
+			return nil, fmt.Errorf("CSVTyped schema refers to unknown column %q", name)
+		}
+	}
+
+	types := make([]string, len(hdr))
+	for i, name := range hdr {
+		if t, ok := schema[name]; ok {
+			types[i] = t
+		} else {
+			types[i] = inferCSVType(records, i)
+		}
+	}
+
+	rows := make([]map[string]interface{}, len(records))
+	for r, record := range records {
+		row := make(map[string]interface{}, len(hdr))
+		for i, v := range record {
+			typed, err := convertCSVValue(v, types[i])
+			if err != nil {
+				return nil, fmt.Errorf("line %d, column %q: %v", r+2, hdr[i], err)
+			}
+			row[hdr[i]] = typed
+		}
+		rows[r] = row
+	}
+	return rows, nil
+}
+
+func parseCSVSchema(spec string) (map[string]string, error) {
+	schema := make(map[string]string)
+	if strings.TrimSpace(spec) == "" {
+		return schema, nil
+	}
+	for _, col := range strings.Split(spec, ",") {
+		parts := strings.SplitN(col, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid CSVTyped schema entry %q - must be in name:type form", col)
+		}
+		name := strings.TrimSpace(parts[0])
+		t := strings.ToLower(strings.TrimSpace(parts[1]))
+		switch t {
+		case csvString, csvInt, csvFloat, csvBool, csvDate:
+		default:
+			return nil, fmt.Errorf("invalid CSVTyped column type %q for %q", t, name)
+		}
+		schema[name] = t
+	}
+	return schema, nil
+}
+
+// inferCSVType - the narrowest type that all non-empty values in the given
+// column can be converted to
+func inferCSVType(records [][]string, col int) string {
+	candidates := []string{csvInt, csvFloat, csvBool, csvDate}
+	seen := false
+	for _, record := range records {
+		if col >= len(record) || strings.TrimSpace(record[col]) == "" {
+			continue
+		}
+		seen = true
+		remaining := []string{}
+		for _, t := range candidates {
+			if _, err := convertCSVValue(record[col], t); err == nil {
+				remaining = append(remaining, t)
+			}
+		}
+		candidates = remaining
+		if len(candidates) == 0 {
+			return csvString
+		}
+	}
+	if !seen {
+		return csvString
+	}
+	return candidates[0]
+}
+
+func convertCSVValue(v, t string) (interface{}, error) {
+	if t == csvString {
+		return v, nil
+	}
+	s := strings.TrimSpace(v)
+	if s == "" {
+		return nil, nil
+	}
+	switch t {
+	case csvInt:
+		return strconv.ParseInt(s, 10, 64)
+	case csvFloat:
+		return strconv.ParseFloat(s, 64)
+	case csvBool:
+		switch strings.ToLower(s) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid bool value %q", s)
+	case csvDate:
+		for _, layout := range csvDateLayouts {
+			if d, err := time.Parse(layout, s); err == nil {
+				return d, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid date value %q", s)
+	}
+	return nil, fmt.Errorf("unknown type %q", t)
+}
+
+func inStrings(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
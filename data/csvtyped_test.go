@@ -0,0 +1,51 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVTyped(t *testing.T) {
+	in := `# services
+"name","port","weight","enabled","since","notes"
+web,80,0.5,true,2018-01-02,
+db,5432,1,FALSE,2017-12-31T10:00:00Z,primary
+cache,,2.5,true,,
+`
+	expected := []map[string]interface{}{
+		{"name": "web", "port": int64(80), "weight": 0.5, "enabled": true, "since": time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC), "notes": ""},
+		{"name": "db", "port": int64(5432), "weight": 1.0, "enabled": false, "since": time.Date(2017, 12, 31, 10, 0, 0, 0, time.UTC), "notes": "primary"},
+		{"name": "cache", "port": nil, "weight": 2.5, "enabled": true, "since": nil, "notes": ""},
+	}
+	assert.Equal(t, expected, CSVTyped(in))
+
+	// explicit schema overrides inference
+	rows := CSVTyped("port:string,weight:float", "port,weight\n80,1\n")
+	assert.Equal(t, []map[string]interface{}{{"port": "80", "weight": 1.0}}, rows)
+
+	// TSV
+	rows = CSVTyped("\t", "a\tb\n1\tx y\n")
+	assert.Equal(t, []map[string]interface{}{{"a": int64(1), "b": "x y"}}, rows)
+
+	rows = CSVTyped(";", "a:int", "a;b\n1;2\n")
+	assert.Equal(t, []map[string]interface{}{{"a": int64(1), "b": int64(2)}}, rows)
+
+	assert.Equal(t, []map[string]interface{}{}, CSVTyped(""))
+
+	// rows are plain maps, so columns are sorted unless they're listed
+	assert.Equal(t, "a,z\r\n2,1\r\n", ToCSV(CSVTyped("z,a\n1,2\n")))
+	assert.Equal(t, "z,a\r\n1,2\r\n", ToCSV("z,a", CSVTyped("z,a\n1,2\n")))
+
+	_, err := parseTypedCSV("a:int", "a\nfoo\n")
+	assert.Error(t, err)
+	_, err = parseTypedCSV("a:blah", "a\n1\n")
+	assert.Error(t, err)
+	_, err = parseTypedCSV("b:int", "a\n1\n")
+	assert.Error(t, err)
+	_, err = parseTypedCSV("a", "a\n1\n")
+	assert.Error(t, err)
+	_, err = parseTypedCSV("ab", "a:int", "a\n1\n")
+	assert.Error(t, err)
+}
@@ -4,8 +4,13 @@ import (
 	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"log"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	// XXX: replace once https://github.com/BurntSushi/toml/pull/179 is merged
 	"github.com/hairyhenderson/toml"
@@ -13,16 +18,32 @@ import (
 	yaml "gopkg.in/yaml.v2"
 )
 
-func unmarshalObj(obj map[string]interface{}, in string, f func([]byte, interface{}) error) map[string]interface{} {
+func unmarshalObj(obj map[string]interface{}, in string, f func([]byte, interface{}) error) (map[string]interface{}, error) {
 	err := f([]byte(in), &obj)
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func unmarshalArray(obj []interface{}, in string, f func([]byte, interface{}) error) ([]interface{}, error) {
+	err := f([]byte(in), &obj)
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func mustUnmarshalObj(in string, f func([]byte, interface{}) error) map[string]interface{} {
+	obj, err := unmarshalObj(make(map[string]interface{}), in, f)
 	if err != nil {
 		log.Fatalf("Unable to unmarshal object %s: %v", in, err)
 	}
 	return obj
 }
 
-func unmarshalArray(obj []interface{}, in string, f func([]byte, interface{}) error) []interface{} {
-	err := f([]byte(in), &obj)
+func mustUnmarshalArray(in string, f func([]byte, interface{}) error) []interface{} {
+	obj, err := unmarshalArray(make([]interface{}, 1), in, f)
 	if err != nil {
 		log.Fatalf("Unable to unmarshal array %s: %v", in, err)
 	}
@@ -31,35 +52,133 @@ func unmarshalArray(obj []interface{}, in string, f func([]byte, interface{}) er
 
 // JSON - Unmarshal a JSON Object
 func JSON(in string) map[string]interface{} {
-	obj := make(map[string]interface{})
-	return unmarshalObj(obj, in, yaml.Unmarshal)
+	return mustUnmarshalObj(in, yaml.Unmarshal)
 }
 
 // JSONArray - Unmarshal a JSON Array
 func JSONArray(in string) []interface{} {
-	obj := make([]interface{}, 1)
-	return unmarshalArray(obj, in, yaml.Unmarshal)
+	return mustUnmarshalArray(in, yaml.Unmarshal)
 }
 
 // YAML - Unmarshal a YAML Object
 func YAML(in string) map[string]interface{} {
-	obj := make(map[string]interface{})
-	return unmarshalObj(obj, in, yaml.Unmarshal)
+	return mustUnmarshalObj(in, yaml.Unmarshal)
 }
 
 // YAMLArray - Unmarshal a YAML Array
 func YAMLArray(in string) []interface{} {
-	obj := make([]interface{}, 1)
-	return unmarshalArray(obj, in, yaml.Unmarshal)
+	return mustUnmarshalArray(in, yaml.Unmarshal)
+}
+
+// yamlDocSeparator - a line starting with `---` (followed by whitespace or
+// the end of the line) starts a new YAML document, even inside block scalars
+var yamlDocSeparator = regexp.MustCompile(`^---(\s|$)`)
+
+// splitYAMLDocuments - split a YAML stream into its documents, dropping empty
+// documents and `...` document-end markers
+func splitYAMLDocuments(in string) []string {
+	docs := []string{}
+	cur := []string{}
+	flush := func() {
+		if !isEmptyYAML(cur) {
+			docs = append(docs, strings.Join(cur, "\n")+"\n")
+		}
+		cur = []string{}
+	}
+	for _, line := range strings.Split(in, "\n") {
+		switch {
+		case yamlDocSeparator.MatchString(line):
+			flush()
+			cur = append(cur, strings.TrimPrefix(line, "---"))
+		case strings.TrimRight(line, " \t\r") == "...":
+			flush()
+		default:
+			cur = append(cur, line)
+		}
+	}
+	flush()
+	return docs
+}
+
+// isEmptyYAML - whether the lines contain only whitespace and comments
+func isEmptyYAML(lines []string) bool {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
+
+// unmarshalDoc - unmarshal a single document of any type. Objects are returned
+// as map[string]interface{}, the same as JSON and YAML do.
+func unmarshalDoc(in string, f func([]byte, interface{}) error) (interface{}, error) {
+	var obj interface{}
+	err := f([]byte(in), &obj)
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := obj.(map[interface{}]interface{}); ok {
+		return stringMap(m)
+	}
+	return obj, nil
+}
+
+// YAMLDocuments - Unmarshal a stream of YAML documents (separated by `---`)
+// into an array, with one element per document
+func YAMLDocuments(in string) []interface{} {
+	out, err := yamlDocuments(in, yaml.Unmarshal)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return out
+}
+
+func yamlDocuments(in string, f func([]byte, interface{}) error) ([]interface{}, error) {
+	docs := splitYAMLDocuments(in)
+	out := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		obj, err := unmarshalDoc(doc, f)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to unmarshal YAML document %d %s: %v", i, doc, err)
+		}
+		out[i] = obj
+	}
+	return out, nil
+}
+
+// JSONLines - Unmarshal a JSON Lines (newline-delimited JSON) document into
+// an array, with one element per line. Blank lines are ignored.
+func JSONLines(in string) []interface{} {
+	out, err := jsonLines(in, yaml.Unmarshal)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return out
+}
+
+func jsonLines(in string, f func([]byte, interface{}) error) ([]interface{}, error) {
+	out := []interface{}{}
+	for i, line := range strings.Split(in, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		obj, err := unmarshalDoc(line, f)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to unmarshal JSON on line %d %s: %v", i+1, line, err)
+		}
+		out = append(out, obj)
+	}
+	return out, nil
 }
 
 // TOML - Unmarshal a TOML Object
 func TOML(in string) interface{} {
-	obj := make(map[string]interface{})
-	return unmarshalObj(obj, in, toml.Unmarshal)
+	return mustUnmarshalObj(in, toml.Unmarshal)
 }
 
-func parseCSV(args ...string) (records [][]string, hdr []string) {
+func parseCSV(args ...string) (records [][]string, hdr []string, err error) {
 	delim := ","
 	var in string
 	if len(args) == 1 {
@@ -82,9 +201,12 @@ func parseCSV(args ...string) (records [][]string, hdr []string) {
 	}
 	c := csv.NewReader(strings.NewReader(in))
 	c.Comma = rune(delim[0])
-	records, err := c.ReadAll()
+	records, err = c.ReadAll()
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, err
+	}
+	if len(records) == 0 && len(hdr) == 0 {
+		return nil, nil, fmt.Errorf("no CSV header found")
 	}
 	if hdr == nil {
 		hdr = records[0]
@@ -95,14 +217,14 @@ func parseCSV(args ...string) (records [][]string, hdr []string) {
 			hdr[i] = autoIndex(i)
 		}
 	}
-	return records, hdr
+	return records, hdr, nil
 }
 
 // autoIndex - calculates a default string column name given a numeric value
 func autoIndex(i int) string {
 	s := ""
 	for n := 0; n <= i/26; n++ {
-		s += string('A' + i%26)
+		s += string(rune('A' + i%26))
 	}
 	return s
 }
@@ -114,11 +236,23 @@ func autoIndex(i int) string {
 // returns:
 //  an array of rows, which are arrays of cells (strings)
 func CSV(args ...string) [][]string {
-	records, hdr := parseCSV(args...)
+	records, err := csvWithHeader(args...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return records
+}
+
+// csvWithHeader - the CSV records, with the header as the first record
+func csvWithHeader(args ...string) ([][]string, error) {
+	records, hdr, err := parseCSV(args...)
+	if err != nil {
+		return nil, err
+	}
 	records = append(records, nil)
 	copy(records[1:], records)
 	records[0] = hdr
-	return records
+	return records, nil
 }
 
 // CSVByRow - Unmarshal CSV in a row-oriented form
@@ -131,7 +265,10 @@ func CSV(args ...string) [][]string {
 // returns:
 //  an array of rows, indexed by the header name
 func CSVByRow(args ...string) (rows []map[string]string) {
-	records, hdr := parseCSV(args...)
+	records, hdr, err := parseCSV(args...)
+	if err != nil {
+		log.Fatal(err)
+	}
 	for _, record := range records {
 		m := make(map[string]string)
 		for i, v := range record {
@@ -152,7 +289,10 @@ func CSVByRow(args ...string) (rows []map[string]string) {
 // returns:
 //  a map of columns, indexed by the header name. values are arrays of strings
 func CSVByColumn(args ...string) (cols map[string][]string) {
-	records, hdr := parseCSV(args...)
+	records, hdr, err := parseCSV(args...)
+	if err != nil {
+		log.Fatal(err)
+	}
 	cols = make(map[string][]string)
 	for _, record := range records {
 		for i, v := range record {
@@ -162,41 +302,175 @@ func CSVByColumn(args ...string) (cols map[string][]string) {
 	return cols
 }
 
-// ToCSV -
+// ToCSV - Marshal CSV
+// parameters:
+//   delim - (optional) the (single-character!) field delimiter, defaults to ","
+// columns - (optional) the columns to output, in order, when the input is an
+//           array of maps - either an array or a comma-separated string.
+//           Defaults to all keys found in the maps (as does "")
+//      in - the rows to output - either an array of arrays (such as a
+//           [][]string), or an array of maps (output with a header row)
+//
+// With two arguments, the first is the delimiter when the input is an array
+// of arrays, and the columns when it's an array of maps.
+// returns:
+//  the RFC4180-format CSV string
 func ToCSV(args ...interface{}) string {
 	delim := ","
-	var in [][]string
-	if len(args) == 2 {
-		d, ok := args[0].(string)
-		if ok {
-			delim = d
+	var columns []string
+	var in interface{}
+	switch len(args) {
+	case 1:
+		in = args[0]
+	case 2:
+		in = args[1]
+		if _, isRecords := csvRecords(in); isRecords {
+			delim = toCSVDelim(args[0])
 		} else {
-			log.Fatalf("Can't parse ToCSV delimiter (%v) - must be string (is a %T)", args[0], args[0])
-		}
-		in, ok = args[1].([][]string)
-		if !ok {
-			log.Fatal("Can't parse ToCSV input - must be of type [][]string")
+			columns = toCSVColumns(args[0])
 		}
+	case 3:
+		delim = toCSVDelim(args[0])
+		columns = toCSVColumns(args[1])
+		in = args[2]
+	default:
+		log.Fatalf("ToCSV requires 1 to 3 arguments, got %d", len(args))
 	}
-	if len(args) == 1 {
-		var ok bool
-		in, ok = args[0].([][]string)
-		if !ok {
-			log.Fatal("Can't parse ToCSV input - must be of type [][]string")
+
+	records, ok := csvRecords(in)
+	if !ok {
+		rows, err := csvRows(in)
+		if err != nil {
+			log.Fatalf("Can't parse ToCSV input - must be an array of arrays or an array of maps: %v", err)
 		}
+		records = mapsToRecords(rows, columns)
 	}
+
 	b := &bytes.Buffer{}
 	c := csv.NewWriter(b)
 	c.Comma = rune(delim[0])
 	// We output RFC4180 CSV, so force this to CRLF
 	c.UseCRLF = true
-	err := c.WriteAll(in)
+	err := c.WriteAll(records)
 	if err != nil {
 		log.Fatal(err)
 	}
 	return string(b.Bytes())
 }
 
+func toCSVDelim(in interface{}) string {
+	d, ok := in.(string)
+	if !ok || d == "" {
+		log.Fatalf("Can't parse ToCSV delimiter (%v) - must be a string (is a %T)", in, in)
+	}
+	return d
+}
+
+func toCSVColumns(in interface{}) []string {
+	if c, ok := in.(string); ok {
+		if c == "" {
+			return nil
+		}
+		return strings.Split(c, ",")
+	}
+	cols, err := interfaceSlice(in)
+	if err != nil {
+		log.Fatalf("Can't parse ToCSV columns (%v) - must be a string or an array", in)
+	}
+	out := make([]string, len(cols))
+	for i, c := range cols {
+		out[i] = fmt.Sprint(c)
+	}
+	return out
+}
+
+// csvRecords - the input as CSV records, and whether it's an array of arrays
+// (rather than, say, an array of maps)
+func csvRecords(in interface{}) ([][]string, bool) {
+	if records, ok := in.([][]string); ok {
+		return records, true
+	}
+	items, err := interfaceSlice(in)
+	if err != nil || len(items) == 0 {
+		return nil, false
+	}
+	records := make([][]string, len(items))
+	for i, item := range items {
+		if _, ok := item.(string); ok {
+			return nil, false
+		}
+		fields, err := interfaceSlice(item)
+		if err != nil {
+			return nil, false
+		}
+		records[i] = make([]string, len(fields))
+		for j, f := range fields {
+			records[i][j] = csvValue(f)
+		}
+	}
+	return records, true
+}
+
+// csvRows - convert an array of maps of any type to OrderedMaps, keeping the
+// order of keys in OrderedMaps and sorting the keys of other maps
+func csvRows(in interface{}) ([]OrderedMap, error) {
+	items, err := interfaceSlice(in)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]OrderedMap, len(items))
+	for i, item := range items {
+		rows[i], err = mapEntries(item)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+// mapsToRecords - convert rows to CSV records, with a header row. When no
+// columns are given, the keys of the first row are used, followed by any
+// other keys found in later rows.
+func mapsToRecords(rows []OrderedMap, columns []string) [][]string {
+	if columns == nil {
+		columns = []string{}
+		for _, row := range rows {
+			for _, item := range row {
+				if !inStrings(columns, item.Key) {
+					columns = append(columns, item.Key)
+				}
+			}
+		}
+	}
+
+	records := make([][]string, len(rows)+1)
+	records[0] = columns
+	for i, row := range rows {
+		record := make([]string, len(columns))
+		for j, col := range columns {
+			record[j] = csvValue(row.Get(col))
+		}
+		records[i+1] = record
+	}
+	return records
+}
+
+func csvValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32)
+	case time.Time:
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
+
 func marshalObj(obj interface{}, f func(interface{}) ([]byte, error)) string {
 	b, err := f(obj)
 	if err != nil {
@@ -210,7 +484,7 @@ func toJSONBytes(in interface{}) []byte {
 	h := &codec.JsonHandle{}
 	h.Canonical = true
 	buf := new(bytes.Buffer)
-	err := codec.NewEncoder(buf, h).Encode(in)
+	err := codec.NewEncoder(buf, h).Encode(toOrderedJSON(in))
 	if err != nil {
 		log.Fatalf("Unable to marshal %s: %v", in, err)
 	}
@@ -236,15 +510,89 @@ func ToJSONPretty(indent string, in interface{}) string {
 
 // ToYAML - Stringify a struct as YAML
 func ToYAML(in interface{}) string {
-	return marshalObj(in, yaml.Marshal)
+	return marshalObj(toOrderedYAML(in), yaml.Marshal)
+}
+
+// ToYAMLDocuments - Stringify an array as a stream of YAML documents, one
+// per element, separated by `---`
+func ToYAMLDocuments(in interface{}) string {
+	docs, err := interfaceSlice(in)
+	if err != nil {
+		log.Fatalf("Unable to marshal %v as YAML documents: %v", in, err)
+	}
+	out := make([]string, len(docs))
+	for i, doc := range docs {
+		out[i] = "---\n" + ToYAML(doc)
+	}
+	return strings.Join(out, "")
+}
+
+// ToJSONLines - Stringify an array as JSON Lines (newline-delimited JSON),
+// with one element per line
+func ToJSONLines(in interface{}) string {
+	items, err := interfaceSlice(in)
+	if err != nil {
+		log.Fatalf("Unable to marshal %v as JSON Lines: %v", in, err)
+	}
+	buf := new(bytes.Buffer)
+	for _, item := range items {
+		buf.Write(toJSONBytes(item))
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// interfaceSlice - convert any slice (other than an OrderedMap, which is an
+// object) to a []interface{}
+func interfaceSlice(in interface{}) ([]interface{}, error) {
+	switch s := in.(type) {
+	case []interface{}:
+		return s, nil
+	case OrderedMap:
+		return nil, fmt.Errorf("expected an array, got an object")
+	}
+	v := reflect.ValueOf(in)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected an array, got %T", in)
+	}
+	out := make([]interface{}, v.Len())
+	for i := range out {
+		out[i] = v.Index(i).Interface()
+	}
+	return out, nil
 }
 
 // ToTOML - Stringify a struct as TOML
 func ToTOML(in interface{}) string {
 	buf := new(bytes.Buffer)
-	err := toml.NewEncoder(buf).Encode(in)
+	err := toml.NewEncoder(buf).Encode(toOrderedTOML(in))
 	if err != nil {
 		log.Fatalf("Unable to marshal %s: %v", in, err)
 	}
 	return string(buf.Bytes())
 }
+
+// stringMap - convert the maps produced by the various parsers (YAML produces
+// map[interface{}]interface{}, and OrderedMaps keep their keys in order) to a
+// map with string keys
+func stringMap(in interface{}) (map[string]interface{}, error) {
+	switch m := in.(type) {
+	case map[string]interface{}:
+		return m, nil
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			out[fmt.Sprint(k)] = v
+		}
+		return out, nil
+	case map[string]string:
+		out := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			out[k] = v
+		}
+		return out, nil
+	case OrderedMap:
+		return m.Map(), nil
+	}
+	return nil, fmt.Errorf("expected a map, got %T", in)
+}
@@ -218,6 +218,37 @@ func TestToCSV(t *testing.T) {
 	expected = "first;second;third\r\n1;2;3\r\n4;5;6\r\n"
 
 	assert.Equal(t, expected, ToCSV(";", in))
+
+	// arrays of arrays of other types work too
+	rows := JSONArray(`[["a","b"],[1,true]]`)
+	assert.Equal(t, "a;b\r\n1;true\r\n", ToCSV(";", rows))
+}
+
+func TestToCSVMaps(t *testing.T) {
+	in := []map[string]interface{}{
+		{"name": "web", "port": 80, "weight": 0.5},
+		{"name": "db", "port": 5432, "extra": nil},
+	}
+	expected := "name,port,weight,extra\r\nweb,80,0.5,\r\ndb,5432,,\r\n"
+	assert.Equal(t, expected, ToCSV(in))
+
+	expected = "port,name\r\n80,web\r\n5432,db\r\n"
+	assert.Equal(t, expected, ToCSV("port,name", in))
+	assert.Equal(t, expected, ToCSV([]interface{}{"port", "name"}, in))
+
+	// a single column isn't mistaken for a delimiter
+	assert.Equal(t, "name\r\nweb\r\ndb\r\n", ToCSV("name", in))
+	assert.Equal(t, "a\r\n\r\n\r\n", ToCSV("a", in))
+
+	expected = "port;name\r\n80;web\r\n5432;db\r\n"
+	assert.Equal(t, expected, ToCSV(";", "port,name", in))
+	assert.Equal(t, expected, ToCSV(";", []string{"port", "name"}, in))
+	assert.Equal(t, "name;port;weight;extra\r\nweb;80;0.5;\r\ndb;5432;;\r\n", ToCSV(";", "", in))
+
+	// the key order of OrderedMaps is used
+	rows := []interface{}{}
+	assert.NoError(t, yamlUnmarshalOrdered([]byte(`[{"z":1,"a":"x"},{"a":"y","z":2}]`), &rows))
+	assert.Equal(t, "z,a\r\n1,x\r\n2,y\r\n", ToCSV(rows))
 }
 
 func TestTOML(t *testing.T) {
@@ -317,3 +348,65 @@ true = true
 	}
 	assert.Equal(t, expected, ToTOML(in))
 }
+
+func TestYAMLDocuments(t *testing.T) {
+	in := `---
+apiVersion: v1
+kind: Service
+---
+# comment only documents are skipped
+---
+- one
+- two
+...
+--- |
+  block
+  text
+---
+kind: Deployment
+`
+	expected := []interface{}{
+		map[string]interface{}{"apiVersion": "v1", "kind": "Service"},
+		[]interface{}{"one", "two"},
+		"block\ntext\n",
+		map[string]interface{}{"kind": "Deployment"},
+	}
+	assert.Equal(t, expected, YAMLDocuments(in))
+	assert.Equal(t, []interface{}{map[string]interface{}{"foo": "bar"}}, YAMLDocuments("foo: bar"))
+	assert.Equal(t, []interface{}{}, YAMLDocuments(""))
+}
+
+func TestToYAMLDocuments(t *testing.T) {
+	in := []interface{}{
+		map[string]interface{}{"kind": "Service"},
+		map[string]interface{}{"kind": "Deployment"},
+	}
+	expected := "---\nkind: Service\n---\nkind: Deployment\n"
+	assert.Equal(t, expected, ToYAMLDocuments(in))
+	assert.Equal(t, in, YAMLDocuments(ToYAMLDocuments(in)))
+}
+
+func TestJSONLines(t *testing.T) {
+	in := `{"level":"info","msg":"hello"}
+
+{"level":"warn","count":2}
+["a","b"]
+`
+	expected := []interface{}{
+		map[string]interface{}{"level": "info", "msg": "hello"},
+		map[string]interface{}{"level": "warn", "count": 2},
+		[]interface{}{"a", "b"},
+	}
+	assert.Equal(t, expected, JSONLines(in))
+}
+
+func TestToJSONLines(t *testing.T) {
+	in := []map[string]interface{}{
+		{"msg": "hello", "level": "info"},
+		{"count": 2},
+	}
+	expected := `{"level":"info","msg":"hello"}
+{"count":2}
+`
+	assert.Equal(t, expected, ToJSONLines(in))
+}
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -12,6 +13,8 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,6 +23,8 @@ import (
 	"github.com/blang/vfs"
 	"github.com/hairyhenderson/gomplate/libkv"
 	"github.com/hairyhenderson/gomplate/vault"
+	"github.com/hairyhenderson/toml"
+	yaml "gopkg.in/yaml.v2"
 )
 
 // logFatal is defined so log.Fatal calls can be overridden for testing
@@ -27,6 +32,10 @@ var logFatalf = log.Fatalf
 
 var json_mimetype = "application/json"
 
+// jsonArrayMimetype - the type given to directory listings, which are
+// returned as JSON arrays of names
+const jsonArrayMimetype = "application/array+json"
+
 // stdin - for overriding in tests
 var stdin io.Reader
 
@@ -43,14 +52,22 @@ func init() {
 	regExtension(".yml", "application/yaml")
 	regExtension(".yaml", "application/yaml")
 	regExtension(".csv", "text/csv")
+	regExtension(".tsv", "text/tab-separated-values")
 	regExtension(".toml", "application/toml")
+	regExtension(".jsonl", "application/x-ndjson")
+	regExtension(".ndjson", "application/x-ndjson")
+	regExtension(".xml", "application/xml")
+	regExtension(".hcl", "application/hcl")
+	regExtension(".tfvars", "application/hcl")
+	regExtension(".env", "application/x-dotenv")
+	regExtension(".ini", "application/x-ini")
+	regExtension(".properties", "text/x-java-properties")
 
 	sourceReaders = make(map[string]func(*Source, ...string) ([]byte, error))
 
 	// Register our source-reader functions
 	addSourceReader("http", readHTTP)
 	addSourceReader("https", readHTTP)
-	addSourceReader("file", readFile)
 	addSourceReader("stdin", readStdin)
 	addSourceReader("vault", readVault)
 	addSourceReader("vault+http", readVault)
@@ -72,13 +89,35 @@ func addSourceReader(scheme string, readFunc func(*Source, ...string) ([]byte, e
 // Data -
 type Data struct {
 	Sources map[string]*Source
-	cache   map[string][]byte
+	cache   map[cacheKey]*cacheEntry
+	// the Data this was derived from with WithDatasources, if any
+	parent *Data
+}
+
+// cacheKey - data is cached by source (rather than alias, since Data derived
+// with WithDatasources can give the same alias to different sources) and the
+// arguments it was read with
+type cacheKey struct {
+	source *Source
+	args   string
+}
+
+// cacheEntry - data read from a source, along with the type it was read as,
+// since some sources (such as directories) can return different types
+// depending on the arguments
+type cacheEntry struct {
+	data      []byte
+	mediaType string
 }
 
 // Cleanup - clean up datasources before shutting the process down - things
 // like Logging out happen here
 func (d *Data) Cleanup() {
-	for _, s := range d.Sources {
+	for alias, s := range d.Sources {
+		if d.parent != nil && d.parent.Sources[alias] == s {
+			// the parent's sources are cleaned up with the parent
+			continue
+		}
 		s.cleanup()
 	}
 }
@@ -140,22 +179,25 @@ func NewSource(alias string, URL *url.URL) *Source {
 		Ext:   ext,
 	}
 
-	mediatype := s.URL.Query().Get("type")
+	s.Type, s.Params = mediaType(s.URL, ext)
+	return s
+}
+
+// mediaType - determine the MIME type (and parameters) of data read from the
+// given URL, preferring the `type` query parameter over the file extension
+func mediaType(u *url.URL, ext string) (string, map[string]string) {
+	mediatype := u.Query().Get("type")
 	if mediatype == "" {
 		mediatype = mime.TypeByExtension(ext)
 	}
-	if mediatype != "" {
-		t, params, err := mime.ParseMediaType(mediatype)
-		if err != nil {
-			log.Fatal(err)
-		}
-		s.Type = t
-		s.Params = params
+	if mediatype == "" {
+		return plaintext, nil
 	}
-	if s.Type == "" {
-		s.Type = plaintext
+	t, params, err := mime.ParseMediaType(mediatype)
+	if err != nil {
+		log.Fatal(err)
 	}
-	return s
+	return t, params
 }
 
 // String is the method to format the flag's value, part of the flag.Value interface.
@@ -215,6 +257,57 @@ func absURL(value string) *url.URL {
 	return baseURL.ResolveReference(relURL)
 }
 
+// DefineDatasource - define a datasource with the given alias and URL, as
+// with an alias=URL argument to NewData. Defining an alias again with the
+// same URL has no effect, but redefining it with a different URL is an error.
+func (d *Data) DefineDatasource(alias, value string) error {
+	s, err := ParseSource(alias + "=" + value)
+	if err != nil {
+		return err
+	}
+	if existing, ok := d.Sources[alias]; ok {
+		if existing.URL.String() != s.URL.String() {
+			return fmt.Errorf("datasource '%s' is already defined as %s", alias, existing.URL)
+		}
+		return nil
+	}
+	if d.Sources == nil {
+		d.Sources = make(map[string]*Source)
+	}
+	d.Sources[alias] = s
+	return nil
+}
+
+// WithDatasources - a copy of d with the given datasources (alias: URL
+// pairs) defined as well, for datasources which should only be available to
+// some templates. Aliases already defined in d can't be given different URLs.
+// The copy shares d's cache, and its Cleanup only cleans up the datasources
+// it added.
+func (d *Data) WithDatasources(sources map[string]string) (*Data, error) {
+	if d.cache == nil {
+		d.cache = make(map[cacheKey]*cacheEntry)
+	}
+	o := &Data{
+		Sources: make(map[string]*Source, len(d.Sources)+len(sources)),
+		cache:   d.cache,
+		parent:  d,
+	}
+	for alias, s := range d.Sources {
+		o.Sources[alias] = s
+	}
+	aliases := make([]string, 0, len(sources))
+	for alias := range sources {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		if err := o.DefineDatasource(alias, sources[alias]); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
 // DatasourceExists -
 func (d *Data) DatasourceExists(alias string) bool {
 	_, ok := d.Sources[alias]
@@ -223,101 +316,289 @@ func (d *Data) DatasourceExists(alias string) bool {
 
 const plaintext = "text/plain"
 
+// DatasourceError - an error reading a datasource, with the alias and
+// arguments it was read with
+type DatasourceError struct {
+	Alias string
+	Args  []string
+	Err   error
+}
+
+func (e *DatasourceError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap - the underlying error
+func (e *DatasourceError) Unwrap() error {
+	return e.Err
+}
+
+func datasourceErrorf(alias string, args []string, format string, a ...interface{}) error {
+	return &DatasourceError{Alias: alias, Args: args, Err: fmt.Errorf(format, a...)}
+}
+
 // Datasource -
-func (d *Data) Datasource(alias string, args ...string) interface{} {
+func (d *Data) Datasource(alias string, args ...string) (interface{}, error) {
 	source, ok := d.Sources[alias]
 	if !ok {
-		log.Fatalf("Undefined datasource '%s'", alias)
+		return nil, datasourceErrorf(alias, args, "Undefined datasource '%s'", alias)
 	}
-	b, err := d.ReadSource(source, args...)
+	dsArgs, jq, err := extractQuery(args)
 	if err != nil {
-		logFatalf("Couldn't read datasource '%s': %s", alias, err)
+		return nil, datasourceErrorf(alias, args, "Couldn't read datasource '%s': %s", alias, err)
 	}
-	if b == nil || len(b) == 0 {
-		logFatalf("No value found for %s from datasource '%s'", args, alias)
-	}
-	s := string(b)
-	if source.Type == json_mimetype {
-		return JSON(s)
+	b, mediaType, err := d.readSource(source, dsArgs...)
+	if err != nil {
+		return nil, datasourceErrorf(alias, args, "Couldn't read datasource '%s': %s", alias, err)
 	}
-	if source.Type == "application/yaml" {
-		return YAML(s)
+	if len(b) == 0 {
+		return nil, datasourceErrorf(alias, args, "No value found for %s from datasource '%s'", dsArgs, alias)
 	}
-	if source.Type == "text/csv" {
-		return CSV(s)
+	out, err := parseSource(mediaType, string(b), sourceFlag(source, "typed"), sourceFlag(source, "ordered"))
+	if err != nil {
+		return nil, datasourceErrorf(alias, args, "Couldn't parse datasource '%s': %s", alias, err)
 	}
-	if source.Type == "application/toml" {
-		return TOML(s)
+	if jq != "" {
+		out, err = Query(jq, out)
+		if err != nil {
+			return nil, datasourceErrorf(alias, args, "Couldn't query datasource '%s': %s", alias, err)
+		}
 	}
-	if source.Type == plaintext {
-		return s
+	return out, nil
+}
+
+// extractQuery - remove the `jq` query parameter (if any) from the datasource
+// arguments, so that it isn't passed on to the underlying source. The query
+// string is split by hand, since url.Values would decode "+" as a space, and
+// "+" can appear in quoted keys and strings (as in `."a+b"`).
+func extractQuery(args []string) ([]string, string, error) {
+	jq := ""
+	out := []string{}
+	for _, arg := range args {
+		if !strings.Contains(arg, "jq=") {
+			out = append(out, arg)
+			continue
+		}
+		u, err := url.Parse(arg)
+		if err != nil {
+			return nil, "", err
+		}
+		found := false
+		rest := []string{}
+		for _, param := range strings.Split(u.RawQuery, "&") {
+			if !strings.HasPrefix(param, "jq=") {
+				rest = append(rest, param)
+				continue
+			}
+			jq, err = url.PathUnescape(strings.TrimPrefix(param, "jq="))
+			if err != nil {
+				return nil, "", err
+			}
+			found = true
+		}
+		if !found {
+			out = append(out, arg)
+			continue
+		}
+		u.RawQuery = strings.Join(rest, "&")
+		if arg = u.String(); arg != "" {
+			out = append(out, arg)
+		}
 	}
-	log.Fatalf("Datasources of type %s not yet supported", source.Type)
-	return nil
+	return out, jq, nil
+}
+
+// sourceFlag - whether a boolean query parameter (like `typed`) is set on the
+// datasource URL
+func sourceFlag(source *Source, name string) bool {
+	set, _ := strconv.ParseBool(source.URL.Query().Get(name))
+	return set
+}
+
+// parseSource - parse the datasource's content according to its MIME type,
+// with CSV and TSV parsed into typed rows when typed is true, and objects in
+// JSON, YAML, TOML and XML parsed into OrderedMaps when ordered is true
+func parseSource(mimeType, s string, typed, ordered bool) (interface{}, error) {
+	unmarshal := yaml.Unmarshal
+	if ordered {
+		unmarshal = yamlUnmarshalOrdered
+	}
+	switch mimeType {
+	case json_mimetype, "application/yaml":
+		// multi-document YAML streams are returned as an array of documents
+		if mimeType == "application/yaml" && len(splitYAMLDocuments(s)) > 1 {
+			return yamlDocuments(s, unmarshal)
+		}
+		if ordered {
+			return unmarshalOrdered(s)
+		}
+		return unmarshalObj(make(map[string]interface{}), s, unmarshal)
+	case "application/x-ndjson":
+		return jsonLines(s, unmarshal)
+	case "text/csv", "text/tab-separated-values":
+		args := []string{s}
+		if mimeType == "text/tab-separated-values" {
+			args = []string{"\t", s}
+		}
+		if typed {
+			return parseTypedCSV(args...)
+		}
+		return csvWithHeader(args...)
+	case "application/toml":
+		if ordered {
+			return tomlOrdered(s)
+		}
+		return unmarshalObj(make(map[string]interface{}), s, toml.Unmarshal)
+	case "application/xml", "text/xml":
+		return parseXML(s, ordered)
+	case "application/hcl":
+		return parseHCL(s)
+	case "application/x-dotenv":
+		return parseDotenv(s)
+	case "application/x-ini":
+		return parseINI(s)
+	case "text/x-java-properties":
+		return parseProperties(s)
+	case jsonArrayMimetype:
+		return unmarshalArray(nil, s, unmarshal)
+	case plaintext:
+		return s, nil
+	}
+	return nil, fmt.Errorf("Datasources of type %s not yet supported", mimeType)
 }
 
 // Include -
-func (d *Data) Include(alias string, args ...string) string {
+func (d *Data) Include(alias string, args ...string) (string, error) {
 	source, ok := d.Sources[alias]
 	if !ok {
-		log.Fatalf("Undefined datasource '%s'", alias)
+		return "", datasourceErrorf(alias, args, "Undefined datasource '%s'", alias)
 	}
 	b, err := d.ReadSource(source, args...)
 	if err != nil {
-		log.Fatalf("Couldn't read datasource '%s': %s", alias, err)
+		return "", datasourceErrorf(alias, args, "Couldn't read datasource '%s': %s", alias, err)
 	}
-	return string(b)
+	return string(b), nil
 }
 
 // ReadSource -
 func (d *Data) ReadSource(source *Source, args ...string) ([]byte, error) {
+	b, _, err := d.readSource(source, args...)
+	return b, err
+}
+
+// readSource - read the source, along with the media type of the data read,
+// which for file datasources depends on the arguments
+func (d *Data) readSource(source *Source, args ...string) ([]byte, string, error) {
 	if d.cache == nil {
-		d.cache = make(map[string][]byte)
-	}
-	cacheKey := source.Alias
-	for _, v := range args {
-		cacheKey += v
+		d.cache = make(map[cacheKey]*cacheEntry)
 	}
-	cached, ok := d.cache[cacheKey]
+	key := cacheKey{source, strings.Join(args, "")}
+	cached, ok := d.cache[key]
 	if ok {
-		return cached, nil
+		return cached.data, cached.mediaType, nil
+	}
+	if source.URL.Scheme == "file" {
+		data, mediaType, err := readFile(source, args...)
+		if err != nil {
+			return nil, "", err
+		}
+		d.cache[key] = &cacheEntry{data, mediaType}
+		return data, mediaType, nil
 	}
 	if r, ok := sourceReaders[source.URL.Scheme]; ok {
 		data, err := r(source, args...)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
-		d.cache[cacheKey] = data
-		return data, nil
+		d.cache[key] = &cacheEntry{data, source.Type}
+		return data, source.Type, nil
 	}
 
-	log.Fatalf("Datasources with scheme %s not yet supported", source.URL.Scheme)
-	return nil, nil
+	return nil, "", fmt.Errorf("Datasources with scheme %s not yet supported", source.URL.Scheme)
 }
 
-func readFile(source *Source, args ...string) ([]byte, error) {
+// readFile - read the file, or list the directory, returning the media type
+// of the data read. Files in a directory datasource are parsed according to
+// their own extension, so this can differ from the source's type.
+func readFile(source *Source, args ...string) ([]byte, string, error) {
 	if source.FS == nil {
 		source.FS = vfs.OS()
 	}
 
 	p := filepath.FromSlash(source.URL.Path)
+	glob := source.URL.Query().Get("glob")
+	typ := source.Type
+
+	if len(args) == 1 {
+		parsed, err := url.Parse(args[0])
+		if err != nil {
+			return nil, "", err
+		}
+
+		if parsed.Path != "" {
+			sub := filepath.Join(p, filepath.FromSlash(parsed.Path))
+			if rel, err := filepath.Rel(p, sub); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return nil, "", fmt.Errorf("Can't read %s: outside of %s", parsed.Path, p)
+			}
+			p = sub
+			typ, _ = mediaType(source.URL, filepath.Ext(p))
+		}
+
+		if g := parsed.Query().Get("glob"); g != "" {
+			glob = g
+		}
+	}
 
 	// make sure we can access the file
-	_, err := source.FS.Stat(p)
+	fi, err := source.FS.Stat(p)
 	if err != nil {
-		log.Fatalf("Can't stat %s: %#v", p, err)
-		return nil, err
+		return nil, "", fmt.Errorf("Can't stat %s: %v", p, err)
+	}
+
+	if fi.IsDir() {
+		b, err := readDir(source, p, glob)
+		return b, jsonArrayMimetype, err
 	}
 
 	f, err := source.FS.OpenFile(p, os.O_RDONLY, 0)
 	if err != nil {
-		log.Fatalf("Can't open %s: %#v", p, err)
-		return nil, err
+		return nil, "", fmt.Errorf("Can't open %s: %v", p, err)
 	}
+	// nolint: errcheck
+	defer f.Close()
 
 	b, err := ioutil.ReadAll(f)
 	if err != nil {
-		log.Fatalf("Can't read %s: %#v", p, err)
+		return nil, "", fmt.Errorf("Can't read %s: %v", p, err)
+	}
+	return b, typ, nil
+}
+
+// readDir - list the entries of the directory at path p, optionally filtered
+// by a glob, as a JSON array of names
+func readDir(source *Source, p, glob string) ([]byte, error) {
+	entries, err := source.FS.ReadDir(p)
+	if err != nil {
+		return nil, fmt.Errorf("Can't read directory %s: %v", p, err)
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		if glob != "" {
+			ok, err := path.Match(glob, entry.Name())
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	b, err := json.Marshal(names)
+	if err != nil {
 		return nil, err
 	}
 	return b, nil
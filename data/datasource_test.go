@@ -33,6 +33,12 @@ func setupMockLogFatalf() {
 	spyLogFatalfMsg = ""
 }
 
+func mustDatasource(t *testing.T, d *Data, alias string, args ...string) interface{} {
+	out, err := d.Datasource(alias, args...)
+	assert.NoError(t, err)
+	return out
+}
+
 func TestNewSource(t *testing.T) {
 	s := NewSource("foo", &url.URL{
 		Scheme: "file",
@@ -164,7 +170,8 @@ func TestDatasource(t *testing.T) {
 	test := func(ext, mime string, contents []byte) {
 		data := setup(ext, mime, contents)
 		expected := map[string]interface{}{"hello": map[interface{}]interface{}{"cruel": "world"}}
-		actual := data.Datasource("foo")
+		actual, err := data.Datasource("foo")
+		assert.NoError(t, err)
 		assert.Equal(t, expected, actual)
 	}
 
@@ -172,12 +179,66 @@ func TestDatasource(t *testing.T) {
 	test("yml", "application/yaml", []byte("hello:\n  cruel: world\n"))
 
 	d := setup("", "text/plain", nil)
-	defer restoreLogFatalf()
-	setupMockLogFatalf()
-	assert.Panics(t, func() {
-		d.Datasource("foo")
-	})
-	assert.Contains(t, spyLogFatalfMsg, "No value found for")
+	_, err := d.Datasource("foo")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "No value found for")
+
+	_, err = d.Datasource("bogus", "a", "b")
+	assert.EqualError(t, err, "Undefined datasource 'bogus'")
+	dsErr, ok := err.(*DatasourceError)
+	assert.True(t, ok)
+	assert.Equal(t, "bogus", dsErr.Alias)
+	assert.Equal(t, []string{"a", "b"}, dsErr.Args)
+}
+
+func TestDatasourceTypedCSV(t *testing.T) {
+	fs := memfs.Create()
+	_ = fs.Mkdir("/tmp", 0777)
+	f, _ := vfs.Create(fs, "/tmp/rows.csv")
+	_, _ = f.Write([]byte("name,port\nweb,80\n"))
+	f, _ = vfs.Create(fs, "/tmp/rows.tsv")
+	_, _ = f.Write([]byte("name\tport\nweb\t80\n"))
+
+	d := &Data{}
+	for alias, u := range map[string]string{
+		"csv":      "file:///tmp/rows.csv",
+		"typedcsv": "file:///tmp/rows.csv?typed=true",
+		"typedtsv": "file:///tmp/rows.tsv?typed=true",
+	} {
+		assert.NoError(t, d.DefineDatasource(alias, u))
+		d.Sources[alias].FS = fs
+	}
+	assert.Equal(t, [][]string{{"name", "port"}, {"web", "80"}}, mustDatasource(t, d, "csv"))
+	expected := []map[string]interface{}{{"name": "web", "port": int64(80)}}
+	assert.Equal(t, expected, mustDatasource(t, d, "typedcsv"))
+	assert.Equal(t, expected, mustDatasource(t, d, "typedtsv"))
+}
+
+func TestDatasourceOrdered(t *testing.T) {
+	fs := memfs.Create()
+	_ = fs.Mkdir("/tmp", 0777)
+	f, _ := vfs.Create(fs, "/tmp/config.json")
+	_, _ = f.Write([]byte(`{"z":1,"a":{"y":2,"b":3}}`))
+	f, _ = vfs.Create(fs, "/tmp/config.toml")
+	_, _ = f.Write([]byte("z = 1\n[a]\ny = 2\nb = 3\n"))
+	f, _ = vfs.Create(fs, "/tmp/docs.yaml")
+	_, _ = f.Write([]byte("z: 1\na: 2\n---\nx: 1\nb: 2\n"))
+
+	d := &Data{}
+	for alias, u := range map[string]string{
+		"json":        "file:///tmp/config.json",
+		"orderedjson": "file:///tmp/config.json?ordered=true",
+		"orderedtoml": "file:///tmp/config.toml?ordered=true",
+		"orderedyaml": "file:///tmp/docs.yaml?ordered=true",
+	} {
+		assert.NoError(t, d.DefineDatasource(alias, u))
+		d.Sources[alias].FS = fs
+	}
+	assert.Equal(t, map[string]interface{}{"z": 1, "a": map[interface{}]interface{}{"y": 2, "b": 3}}, mustDatasource(t, d, "json"))
+	expected := OrderedMap{{"z", 1}, {"a", OrderedMap{{"y", 2}, {"b", 3}}}}
+	assert.Equal(t, expected, mustDatasource(t, d, "orderedjson"))
+	assert.Equal(t, `{"z":1,"a":{"y":2,"b":3}}`, ToJSON(mustDatasource(t, d, "orderedtoml")))
+	assert.Equal(t, []interface{}{OrderedMap{{"z", 1}, {"a", 2}}, OrderedMap{{"x", 1}, {"b", 2}}}, mustDatasource(t, d, "orderedyaml"))
 }
 
 func TestDatasourceExists(t *testing.T) {
@@ -189,6 +250,38 @@ func TestDatasourceExists(t *testing.T) {
 	assert.False(t, data.DatasourceExists("bar"))
 }
 
+func TestDefineDatasource(t *testing.T) {
+	d := &Data{}
+	assert.NoError(t, d.DefineDatasource("foo", "file:///tmp/foo.json"))
+	assert.True(t, d.DatasourceExists("foo"))
+	assert.Equal(t, "/tmp/foo.json", d.Sources["foo"].URL.Path)
+	assert.Equal(t, "application/json", d.Sources["foo"].Type)
+
+	assert.NoError(t, d.DefineDatasource("foo", "file:///tmp/foo.json"))
+	assert.Error(t, d.DefineDatasource("foo", "file:///tmp/bar.json"))
+	assert.Equal(t, "/tmp/foo.json", d.Sources["foo"].URL.Path)
+}
+
+func TestWithDatasources(t *testing.T) {
+	d := &Data{}
+	assert.NoError(t, d.DefineDatasource("foo", "file:///tmp/foo.json"))
+
+	o, err := d.WithDatasources(map[string]string{"foo": "file:///tmp/foo.json", "bar": "file:///tmp/bar.json"})
+	assert.NoError(t, err)
+	assert.True(t, o.DatasourceExists("foo"))
+	assert.True(t, o.DatasourceExists("bar"))
+	assert.False(t, d.DatasourceExists("bar"))
+	assert.Equal(t, d.Sources["foo"], o.Sources["foo"])
+
+	o2, err := d.WithDatasources(map[string]string{"bar": "file:///tmp/baz.json"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/baz.json", o2.Sources["bar"].URL.Path)
+	assert.Equal(t, "/tmp/bar.json", o.Sources["bar"].URL.Path)
+
+	_, err = d.WithDatasources(map[string]string{"foo": "file:///tmp/other.json"})
+	assert.Error(t, err)
+}
+
 func setupHTTP(code int, mimetype string, body string) (*httptest.Server, *http.Client) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -232,8 +325,9 @@ func TestHTTPFile(t *testing.T) {
 	}
 	expected := make(map[string]interface{})
 	expected["hello"] = "world"
-	actual := data.Datasource("foo").(map[string]interface{})
-	assert.Equal(t, expected["hello"], actual["hello"])
+	actual, err := data.Datasource("foo")
+	assert.NoError(t, err)
+	assert.Equal(t, expected["hello"], actual.(map[string]interface{})["hello"])
 }
 
 func TestHTTPFileWithHeaders(t *testing.T) {
@@ -263,7 +357,8 @@ func TestHTTPFileWithHeaders(t *testing.T) {
 		"Accept-Encoding": {"test"},
 		"Foo":             {"bar", "baz"},
 	}
-	actual := data.Datasource("foo")
+	actual, err := data.Datasource("foo")
+	assert.NoError(t, err)
 	assert.Equal(t, marshalObj(expected, json.Marshal), marshalObj(actual, json.Marshal))
 }
 
@@ -334,8 +429,12 @@ func TestInclude(t *testing.T) {
 	data := &Data{
 		Sources: sources,
 	}
-	actual := data.Include("foo")
+	actual, err := data.Include("foo")
+	assert.NoError(t, err)
 	assert.Equal(t, contents, actual)
+
+	_, err = data.Include("bogus")
+	assert.EqualError(t, err, "Undefined datasource 'bogus'")
 }
 
 type errorReader struct{}
@@ -357,3 +456,137 @@ func TestReadStdin(t *testing.T) {
 	_, err = readStdin(nil)
 	assert.Error(t, err)
 }
+
+func TestReadFileDir(t *testing.T) {
+	fs := memfs.Create()
+	_ = fs.Mkdir("/tmp", 0777)
+	_ = fs.Mkdir("/tmp/conf.d", 0777)
+	_ = fs.Mkdir("/tmp/conf.d/sub", 0777)
+	f, _ := vfs.Create(fs, "/tmp/conf.d/foo.yaml")
+	_, _ = f.Write([]byte("foo: bar\n"))
+	f, _ = vfs.Create(fs, "/tmp/conf.d/bar.json")
+	_, _ = f.Write([]byte(`{"bar": "baz"}`))
+	f, _ = vfs.Create(fs, "/tmp/conf.d/sub/qux.txt")
+	_, _ = f.Write([]byte("hello"))
+
+	newData := func(u string) *Data {
+		parsed, _ := url.Parse(u)
+		s := NewSource("dir", parsed)
+		s.FS = fs
+		return &Data{Sources: map[string]*Source{"dir": s}}
+	}
+
+	d := newData("file:///tmp/conf.d/")
+	assert.Equal(t, []interface{}{"bar.json", "foo.yaml", "sub"}, mustDatasource(t, d, "dir"))
+	assert.Equal(t, map[string]interface{}{"foo": "bar"}, mustDatasource(t, d, "dir", "foo.yaml"))
+	assert.Equal(t, map[string]interface{}{"bar": "baz"}, mustDatasource(t, d, "dir", "bar.json"))
+	assert.Equal(t, []interface{}{"qux.txt"}, mustDatasource(t, d, "dir", "sub"))
+	assert.Equal(t, "hello", mustDatasource(t, d, "dir", "sub/qux.txt"))
+	// cached reads must still be parsed with the right type
+	assert.Equal(t, map[string]interface{}{"foo": "bar"}, mustDatasource(t, d, "dir", "foo.yaml"))
+	assert.Equal(t, []interface{}{"bar.json", "foo.yaml", "sub"}, mustDatasource(t, d, "dir"))
+
+	assert.Equal(t, []interface{}{"foo.yaml"}, mustDatasource(t, d, "dir", "?glob=*.yaml"))
+
+	d = newData("file:///tmp/conf.d/?glob=*.json")
+	assert.Equal(t, []interface{}{"bar.json"}, mustDatasource(t, d, "dir"))
+
+	d = newData("file:///tmp/conf.d/?glob=*.json")
+	_, err := d.ReadSource(d.Sources["dir"], "?glob=[")
+	assert.Error(t, err)
+
+	_, err = d.ReadSource(d.Sources["dir"], "nonexistent.yaml")
+	assert.Error(t, err)
+
+	// sub-paths can't escape the directory
+	f, _ = vfs.Create(fs, "/tmp/secret.txt")
+	_, _ = f.Write([]byte("secret"))
+	d = newData("file:///tmp/conf.d/")
+	for _, p := range []string{"../secret.txt", "sub/../../secret.txt", ".."} {
+		_, err = d.ReadSource(d.Sources["dir"], p)
+		assert.Error(t, err, p)
+	}
+	assert.Equal(t, "hello", mustDatasource(t, d, "dir", "sub/../sub/qux.txt"))
+
+	// reading a file or listing doesn't change the source's type
+	typ := d.Sources["dir"].Type
+	assert.Equal(t, map[string]interface{}{"foo": "bar"}, mustDatasource(t, d, "dir", "foo.yaml"))
+	assert.Equal(t, []interface{}{"bar.json", "foo.yaml", "sub"}, mustDatasource(t, d, "dir"))
+	assert.Equal(t, typ, d.Sources["dir"].Type)
+}
+
+func TestDatasourceXML(t *testing.T) {
+	fs := memfs.Create()
+	_ = fs.Mkdir("/tmp", 0777)
+	f, _ := vfs.Create(fs, "/tmp/foo.xml")
+	_, _ = f.Write([]byte(`<hello><cruel>world</cruel></hello>`))
+
+	s := NewSource("foo", &url.URL{Scheme: "file", Path: "/tmp/foo.xml"})
+	s.FS = fs
+	d := &Data{Sources: map[string]*Source{"foo": s}}
+	expected := map[string]interface{}{"hello": map[string]interface{}{"cruel": "world"}}
+	assert.Equal(t, expected, mustDatasource(t, d, "foo"))
+}
+
+func TestDatasourceMultiDocument(t *testing.T) {
+	fs := memfs.Create()
+	_ = fs.Mkdir("/tmp", 0777)
+	f, _ := vfs.Create(fs, "/tmp/manifests.yaml")
+	_, _ = f.Write([]byte("---\nkind: Service\n---\nkind: Deployment\n"))
+	f, _ = vfs.Create(fs, "/tmp/single.yaml")
+	_, _ = f.Write([]byte("---\nkind: Service\n"))
+	f, _ = vfs.Create(fs, "/tmp/log.jsonl")
+	_, _ = f.Write([]byte("{\"msg\":\"a\"}\n{\"msg\":\"b\"}\n"))
+
+	d := &Data{Sources: map[string]*Source{}}
+	for _, name := range []string{"manifests.yaml", "single.yaml", "log.jsonl"} {
+		s := NewSource(name, &url.URL{Scheme: "file", Path: "/tmp/" + name})
+		s.FS = fs
+		d.Sources[name] = s
+	}
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"kind": "Service"},
+		map[string]interface{}{"kind": "Deployment"},
+	}, mustDatasource(t, d, "manifests.yaml"))
+	assert.Equal(t, map[string]interface{}{"kind": "Service"}, mustDatasource(t, d, "single.yaml"))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"msg": "a"},
+		map[string]interface{}{"msg": "b"},
+	}, mustDatasource(t, d, "log.jsonl"))
+}
+
+func TestDatasourceParseErrors(t *testing.T) {
+	fs := memfs.Create()
+	_ = fs.Mkdir("/tmp", 0777)
+	d := &Data{Sources: map[string]*Source{}}
+	for name, typ := range map[string]string{
+		"bad.json":  "application/json",
+		"bad.yaml":  "application/yaml",
+		"bad.jsonl": "application/x-ndjson",
+		"bad.toml":  "application/toml",
+		"bad.xml":   "application/xml",
+		"bad.csv":   "text/csv",
+		"bad.foo":   "application/x-foo",
+	} {
+		f, _ := vfs.Create(fs, "/tmp/"+name)
+		_, _ = f.Write([]byte("{\"a\":\n---\nb: [\n\"c"))
+		d.Sources[name] = &Source{
+			Alias: name,
+			URL:   &url.URL{Scheme: "file", Path: "/tmp/" + name},
+			Type:  typ,
+			FS:    fs,
+		}
+	}
+	for name := range d.Sources {
+		_, err := d.Datasource(name)
+		if assert.Error(t, err, name) {
+			assert.Contains(t, err.Error(), "Couldn't parse datasource '"+name+"'")
+			dsErr, ok := err.(*DatasourceError)
+			assert.True(t, ok)
+			assert.Equal(t, name, dsErr.Alias)
+		}
+	}
+	_, err := d.Datasource("bad.foo")
+	assert.EqualError(t, err, "Couldn't parse datasource 'bad.foo': Datasources of type application/x-foo not yet supported")
+}
@@ -0,0 +1,143 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+var dotenvKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// Dotenv - Unmarshal a .env file. Lines may be prefixed with `export`, and
+// values may be unquoted, 'single-quoted' (taken literally), or
+// "double-quoted" (supporting escapes, and spanning multiple lines).
+func Dotenv(in string) map[string]interface{} {
+	obj, err := parseDotenv(in)
+	if err != nil {
+		log.Fatalf("Unable to unmarshal dotenv %s: %v", in, err)
+	}
+	return obj
+}
+
+func parseDotenv(in string) (map[string]interface{}, error) {
+	obj := make(map[string]interface{})
+	lines := strings.Split(strings.Replace(in, "\r\n", "\n", -1), "\n")
+	for n := 0; n < len(lines); n++ {
+		line := strings.TrimSpace(lines[n])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("line %d: expected KEY=value, got %q", n+1, line)
+		}
+		key := strings.TrimSpace(parts[0])
+		if !dotenvKey.MatchString(key) {
+			return nil, fmt.Errorf("line %d: invalid key %q", n+1, key)
+		}
+		value := strings.TrimSpace(parts[1])
+
+		switch {
+		case strings.HasPrefix(value, "'"):
+			end := strings.Index(value[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated single-quoted value", n+1)
+			}
+			value = value[1 : end+1]
+		case strings.HasPrefix(value, `"`):
+			// double-quoted values can span lines
+			raw := value[1:]
+			for !hasClosingQuote(raw) {
+				n++
+				if n >= len(lines) {
+					return nil, fmt.Errorf("unterminated double-quoted value for %s", key)
+				}
+				raw += "\n" + lines[n]
+			}
+			value = unescapeDotenv(raw[:closingQuote(raw)])
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		obj[key] = value
+	}
+	return obj, nil
+}
+
+// closingQuote - the index of the first unescaped double-quote in s, or -1
+func closingQuote(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func hasClosingQuote(s string) bool {
+	return closingQuote(s) >= 0
+}
+
+func unescapeDotenv(s string) string {
+	out := &bytes.Buffer{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i == len(s)-1 {
+			out.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			out.WriteByte('\n')
+		case 'r':
+			out.WriteByte('\r')
+		case 't':
+			out.WriteByte('\t')
+		default:
+			out.WriteByte(s[i])
+		}
+	}
+	return out.String()
+}
+
+var dotenvSafe = regexp.MustCompile(`^[A-Za-z0-9_./:@,+\-]*$`)
+
+// ToDotenv - Stringify a map as a .env file, one KEY=value line per key, in
+// order for an OrderedMap and otherwise in alphabetical order. Values are
+// double-quoted when necessary.
+func ToDotenv(in interface{}) string {
+	m, err := mapEntries(in)
+	if err != nil {
+		log.Fatalf("Unable to marshal %v as dotenv: %v", in, err)
+	}
+
+	buf := &bytes.Buffer{}
+	for _, item := range m {
+		if !dotenvKey.MatchString(item.Key) {
+			log.Fatalf("Unable to marshal %v as dotenv: invalid key %q", in, item.Key)
+		}
+		v := ""
+		if item.Value != nil {
+			v = fmt.Sprint(item.Value)
+		}
+		fmt.Fprintf(buf, "%s=%s\n", item.Key, dotenvValue(v))
+	}
+	return buf.String()
+}
+
+func dotenvValue(s string) string {
+	if dotenvSafe.MatchString(s) {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, `$`, `\$`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,65 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDotenv(t *testing.T) {
+	in := `# comment
+FOO=bar
+export BAR = baz # trailing comment
+EMPTY=
+SINGLE='literal \n $value'
+DOUBLE="escaped\tvalue with \"quotes\""
+MULTI="line one
+line two"
+`
+	expected := map[string]interface{}{
+		"FOO":    "bar",
+		"BAR":    "baz",
+		"EMPTY":  "",
+		"SINGLE": `literal \n $value`,
+		"DOUBLE": "escaped\tvalue with \"quotes\"",
+		"MULTI":  "line one\nline two",
+	}
+	assert.Equal(t, expected, Dotenv(in))
+
+	_, err := parseDotenv("FOO")
+	assert.Error(t, err)
+	_, err = parseDotenv("1FOO=bar")
+	assert.Error(t, err)
+	_, err = parseDotenv(`FOO="bar`)
+	assert.Error(t, err)
+	_, err = parseDotenv(`FOO='bar`)
+	assert.Error(t, err)
+}
+
+func TestToDotenv(t *testing.T) {
+	in := map[string]interface{}{
+		"FOO":   "bar",
+		"PORT":  8080,
+		"SPACE": "hello world",
+		"MULTI": "a\nb",
+		"PRICE": "$5",
+		"EMPTY": nil,
+	}
+	expected := `EMPTY=
+FOO=bar
+MULTI="a\nb"
+PORT=8080
+PRICE="\$5"
+SPACE="hello world"
+`
+	out := ToDotenv(in)
+	assert.Equal(t, expected, out)
+	assert.Equal(t, map[string]interface{}{
+		"EMPTY": "",
+		"FOO":   "bar",
+		"MULTI": "a\nb",
+		"PORT":  "8080",
+		"PRICE": "$5",
+		"SPACE": "hello world",
+	}, Dotenv(out))
+}
@@ -0,0 +1,249 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/hcl"
+)
+
+// HCL - Unmarshal an HCL (v1) document, such as a Terraform .tfvars file or a
+// Nomad job file. As with the HCL library itself, blocks and nested objects
+// are represented as arrays of maps.
+//
+// The `$${` and `%%{` escapes (for literal `${` and `%{`, as used by HCL 2 and
+// Terraform) are unescaped in strings, so documents written by ToHCL can be
+// read back.
+func HCL(in string) map[string]interface{} {
+	obj, err := parseHCL(in)
+	if err != nil {
+		log.Fatalf("Unable to unmarshal HCL %s: %v", in, err)
+	}
+	return obj
+}
+
+func parseHCL(in string) (map[string]interface{}, error) {
+	obj, err := unmarshalObj(make(map[string]interface{}), in, hcl.Unmarshal)
+	if err != nil {
+		return nil, err
+	}
+	return unescapeHCLTemplates(obj).(map[string]interface{}), nil
+}
+
+var hclTemplateUnescaper = strings.NewReplacer("$${", "${", "%%{", "%{")
+
+// unescapeHCLTemplates - unescape the `$${` and `%%{` sequences in the strings
+// (and keys) of the decoded value
+func unescapeHCLTemplates(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string:
+		return hclTemplateUnescaper.Replace(t)
+	case map[string]interface{}:
+		for _, k := range sortedKeys(t) {
+			item := t[k]
+			if u := hclTemplateUnescaper.Replace(k); u != k {
+				delete(t, k)
+				k = u
+			}
+			t[k] = unescapeHCLTemplates(item)
+		}
+	case []map[string]interface{}:
+		for _, item := range t {
+			unescapeHCLTemplates(item)
+		}
+	case []interface{}:
+		for i, item := range t {
+			t[i] = unescapeHCLTemplates(item)
+		}
+	}
+	return v
+}
+
+var hclIdent = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_\-]*$`)
+
+// ToHCL - Stringify an object as an HCL document. Maps become blocks, and a
+// map with a single key holding another block is written with that key as a
+// block label (`job "example" { ... }`), which is how the HCL library decodes
+// labelled blocks.
+func ToHCL(in interface{}) string {
+	m, err := mapEntries(in)
+	if err != nil {
+		log.Fatalf("Unable to marshal %v as HCL: %v", in, err)
+	}
+	buf := &bytes.Buffer{}
+	if err := writeHCLBody(buf, m, 0); err != nil {
+		log.Fatalf("Unable to marshal %v as HCL: %v", in, err)
+	}
+	return buf.String()
+}
+
+func writeHCLBody(buf *bytes.Buffer, m OrderedMap, depth int) error {
+	// attributes first, then blocks, to keep the output readable
+	blocks := OrderedMap{}
+	for _, item := range m {
+		if item.Value == nil {
+			continue
+		}
+		if isHCLBlock(item.Value) {
+			blocks = append(blocks, item)
+			continue
+		}
+		s, err := hclValue(item.Value)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "%s%s = %s\n", hclIndent(depth), hclKey(item.Key), s)
+	}
+
+	for _, item := range blocks {
+		if err := writeHCLBlock(buf, []string{hclKey(item.Key)}, item.Value, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeHCLBlock(buf *bytes.Buffer, labels []string, v interface{}, depth int) error {
+	if list, ok := v.([]interface{}); ok {
+		for _, item := range list {
+			if err := writeHCLBlock(buf, labels, item, depth); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if list, ok := v.([]map[string]interface{}); ok {
+		for _, item := range list {
+			if err := writeHCLBlock(buf, labels, item, depth); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	m, err := mapEntries(v)
+	if err != nil {
+		return err
+	}
+
+	// collapse single nested blocks into labels
+	if len(m) == 1 && isHCLBlock(m[0].Value) {
+		return writeHCLBlock(buf, append(labels, hclString(m[0].Key)), m[0].Value, depth)
+	}
+
+	fmt.Fprintf(buf, "%s%s {\n", hclIndent(depth), strings.Join(labels, " "))
+	if err := writeHCLBody(buf, m, depth+1); err != nil {
+		return err
+	}
+	fmt.Fprintf(buf, "%s}\n", hclIndent(depth))
+	return nil
+}
+
+// isHCLBlock - maps, and non-empty lists made up only of maps, are blocks
+func isHCLBlock(v interface{}) bool {
+	if _, err := stringMap(v); err == nil {
+		return true
+	}
+	return isHCLBlockList(v)
+}
+
+func isHCLBlockList(v interface{}) bool {
+	switch list := v.(type) {
+	case []map[string]interface{}:
+		return len(list) > 0
+	case []interface{}:
+		if len(list) == 0 {
+			return false
+		}
+		for _, item := range list {
+			if _, err := stringMap(item); err != nil {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func hclValue(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return hclString(t), nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	}
+
+	val := reflect.ValueOf(v)
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(val.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(val.Float(), 'f', -1, 64), nil
+	case reflect.Slice, reflect.Array:
+		items := make([]string, val.Len())
+		for i := range items {
+			s, err := hclValue(val.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	}
+	return "", fmt.Errorf("can't represent %v (%T) in HCL", v, v)
+}
+
+func hclKey(k string) string {
+	if hclIdent.MatchString(k) {
+		return k
+	}
+	return hclString(k)
+}
+
+// hclString - quote the string as an HCL string literal. Only the escapes
+// that both HCL 1 and 2 understand are used (unlike Go's strconv.Quote, which
+// can write escapes like `\a` and `\x01`), and `${` and `%{` (which start
+// template sequences in HCL 2) are escaped as `$${` and `%%{`.
+func hclString(s string) string {
+	buf := &strings.Builder{}
+	buf.WriteByte('"')
+	for i, r := range s {
+		switch {
+		case r == '"':
+			buf.WriteString(`\"`)
+		case r == '\\':
+			buf.WriteString(`\\`)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case (r == '$' || r == '%') && strings.HasPrefix(s[i+1:], "{"):
+			buf.WriteRune(r)
+			buf.WriteRune(r)
+		case !unicode.IsPrint(r):
+			if r > 0xffff {
+				fmt.Fprintf(buf, `\U%08x`, r)
+			} else {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			}
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+func hclIndent(depth int) string {
+	return strings.Repeat("  ", depth)
+}
@@ -0,0 +1,114 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHCL(t *testing.T) {
+	in := `# the region
+region = "us-east-1"
+count = 3
+enabled = true
+zones = ["a", "b"]
+
+tags {
+  Name = "web"
+}
+`
+	expected := map[string]interface{}{
+		"region":  "us-east-1",
+		"count":   3,
+		"enabled": true,
+		"zones":   []interface{}{"a", "b"},
+		"tags": []map[string]interface{}{
+			{"Name": "web"},
+		},
+	}
+	assert.Equal(t, expected, HCL(in))
+}
+
+func TestToHCL(t *testing.T) {
+	in := map[string]interface{}{
+		"region":  "us-east-1",
+		"count":   3,
+		"ratio":   0.5,
+		"enabled": true,
+		"zones":   []interface{}{"a", "b"},
+		"my key":  "quoted",
+		"tags":    map[interface{}]interface{}{"Name": "web"},
+	}
+	expected := `count = 3
+enabled = true
+"my key" = "quoted"
+ratio = 0.5
+region = "us-east-1"
+zones = ["a", "b"]
+tags {
+  Name = "web"
+}
+`
+	assert.Equal(t, expected, ToHCL(in))
+
+	job := `job "example" {
+  datacenters = ["dc1"]
+  group "cache" {
+    count = 1
+  }
+}
+`
+	assert.Equal(t, job, ToHCL(HCL(job)))
+
+	expected = `job "a" {
+  count = 1
+}
+job "b" {
+  count = 2
+}
+`
+	assert.Equal(t, expected, ToHCL(HCL(expected)))
+}
+
+func TestHCLStrings(t *testing.T) {
+	testdata := []struct {
+		in, out string
+	}{
+		{"plain", `"plain"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`C:\path`, `"C:\\path"`},
+		{"a\nb\tc\rd", `"a\nb\tc\rd"`},
+		{"${var.foo}", `"$${var.foo}"`},
+		{"100%{x}", `"100%%{x}"`},
+		{"$$${x}", `"$$$${x}"`},
+		{"$ and % and {}", `"$ and % and {}"`},
+		{"bell\a nul\x00 del\x7f", `"bell\u0007 nul\u0000 del\u007f"`},
+		{"café 😀", `"café 😀"`},
+		{"\u200b", `"\u200b"`},
+	}
+	for _, d := range testdata {
+		assert.Equal(t, d.out, hclString(d.in), d.in)
+
+		// round-trip through ToHCL and HCL
+		in := map[string]interface{}{"v": d.in, "list": []interface{}{d.in}}
+		out := HCL(ToHCL(in))
+		assert.Equal(t, d.in, out["v"], d.in)
+		assert.Equal(t, []interface{}{d.in}, out["list"], d.in)
+	}
+
+	// quoted keys and labels round-trip too
+	in := map[string]interface{}{
+		"my ${key}": "x",
+		"job": map[string]interface{}{
+			"a \"b\"": map[string]interface{}{"count": 1},
+		},
+	}
+	assert.Equal(t, `"my $${key}" = "x"
+job "a \"b\"" {
+  count = 1
+}
+`, ToHCL(in))
+	out := HCL(ToHCL(in))
+	assert.Equal(t, "x", out["my ${key}"])
+	assert.Equal(t, []map[string]interface{}{{"a \"b\"": []map[string]interface{}{{"count": 1}}}}, out["job"])
+}
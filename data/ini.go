@@ -0,0 +1,112 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	ini "github.com/go-ini/ini"
+)
+
+// INI - Unmarshal an INI document. Keys outside of any section are at the top
+// level, and each section is a nested map of its keys. All values are strings.
+func INI(in string) map[string]interface{} {
+	obj, err := parseINI(in)
+	if err != nil {
+		log.Fatalf("Unable to unmarshal INI %s: %v", in, err)
+	}
+	return obj
+}
+
+func parseINI(in string) (map[string]interface{}, error) {
+	f, err := ini.LoadSources(ini.LoadOptions{
+		UnescapeValueDoubleQuotes: true,
+	}, []byte(in))
+	if err != nil {
+		return nil, err
+	}
+
+	obj := make(map[string]interface{})
+	for _, section := range f.Sections() {
+		m := obj
+		if section.Name() != ini.DEFAULT_SECTION {
+			m = make(map[string]interface{})
+			obj[section.Name()] = m
+		}
+		for _, key := range section.Keys() {
+			m[key.Name()] = key.Value()
+		}
+	}
+	return obj, nil
+}
+
+// ToINI - Stringify an object as an INI document. Top-level scalar values are
+// written first, followed by one section for each top-level map. Keys are
+// written in order for OrderedMaps, and otherwise in alphabetical order.
+// Deeper nesting can't be represented in INI.
+func ToINI(in interface{}) string {
+	m, err := mapEntries(in)
+	if err != nil {
+		log.Fatalf("Unable to marshal %v as INI: %v", in, err)
+	}
+
+	buf := &bytes.Buffer{}
+	sections := OrderedMap{}
+	for _, item := range m {
+		if _, err := stringMap(item.Value); err == nil {
+			sections = append(sections, item)
+			continue
+		}
+		if err := writeINIKey(buf, item.Key, item.Value); err != nil {
+			log.Fatalf("Unable to marshal %v as INI: %v", in, err)
+		}
+	}
+
+	for _, s := range sections {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(buf, "[%s]\n", s.Key)
+		section, _ := mapEntries(s.Value)
+		for _, item := range section {
+			if err := writeINIKey(buf, item.Key, item.Value); err != nil {
+				log.Fatalf("Unable to marshal %v as INI: %v", in, err)
+			}
+		}
+	}
+	return buf.String()
+}
+
+func writeINIKey(buf *bytes.Buffer, k string, v interface{}) error {
+	if _, err := stringMap(v); err == nil {
+		return fmt.Errorf("can't represent nested section %s in INI", k)
+	}
+	s := ""
+	if v != nil {
+		s = fmt.Sprint(v)
+	}
+	fmt.Fprintf(buf, "%s = %s\n", k, iniValue(s))
+	return nil
+}
+
+// iniValue - quote values which would otherwise be changed by parsing
+func iniValue(s string) string {
+	if strings.Contains(s, "\n") {
+		return `"""` + s + `"""`
+	}
+	if s != strings.TrimSpace(s) || strings.ContainsAny(s, `;#"`) {
+		return `"` + strings.Replace(s, `"`, `\"`, -1) + `"`
+	}
+	return s
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
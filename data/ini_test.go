@@ -0,0 +1,62 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestINI(t *testing.T) {
+	in := `; global settings
+name = example
+
+[database]
+host = db.example.com
+port = 5432
+
+[server "web"]
+listen = "0.0.0.0:80"
+`
+	expected := map[string]interface{}{
+		"name": "example",
+		"database": map[string]interface{}{
+			"host": "db.example.com",
+			"port": "5432",
+		},
+		`server "web"`: map[string]interface{}{
+			"listen": "0.0.0.0:80",
+		},
+	}
+	assert.Equal(t, expected, INI(in))
+}
+
+func TestToINI(t *testing.T) {
+	in := map[string]interface{}{
+		"name": "example",
+		"database": map[interface{}]interface{}{
+			"host": "db.example.com",
+			"port": 5432,
+		},
+		"server": map[string]interface{}{
+			"motd":    " hello; world ",
+			"comment": "multi\nline",
+		},
+	}
+	expected := `name = example
+
+[database]
+host = db.example.com
+port = 5432
+
+[server]
+comment = """multi
+line"""
+motd = " hello; world "
+`
+	out := ToINI(in)
+	assert.Equal(t, expected, out)
+
+	parsed := INI(out)
+	assert.Equal(t, " hello; world ", parsed["server"].(map[string]interface{})["motd"])
+	assert.Equal(t, "multi\nline", parsed["server"].(map[string]interface{})["comment"])
+}
@@ -0,0 +1,614 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Schema - a JSON Schema (draft 7), used to validate parsed data
+type Schema struct {
+	root     interface{}
+	patterns map[string]*regexp.Regexp
+}
+
+// ValidationError - a single violation of a schema, at the location in the
+// validated document given by the JSON Pointer Path
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("#%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors - all of the violations found when validating a document
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%d schema violation(s):", len(e))
+	for _, v := range e {
+		fmt.Fprintf(buf, "\n  %s", v)
+	}
+	return buf.String()
+}
+
+// NewSchema - create a Schema from a parsed JSON Schema document, such as the
+// output of JSON or YAML
+func NewSchema(in interface{}) (*Schema, error) {
+	switch in.(type) {
+	case bool:
+	default:
+		if _, err := stringMap(in); err != nil {
+			return nil, fmt.Errorf("a JSON Schema must be an object or a boolean, not %T", in)
+		}
+	}
+	return &Schema{root: in, patterns: make(map[string]*regexp.Regexp)}, nil
+}
+
+// ParseSchema - parse a JSON (or YAML) JSON Schema document
+func ParseSchema(in string) (*Schema, error) {
+	var obj interface{}
+	if err := yaml.Unmarshal([]byte(in), &obj); err != nil {
+		return nil, fmt.Errorf("unable to parse schema: %v", err)
+	}
+	return NewSchema(obj)
+}
+
+// Validate - validate the value against the schema, returning
+// ValidationErrors listing all violations, or nil if the value is valid
+func (s *Schema) Validate(in interface{}) error {
+	v := &validator{schema: s}
+	v.validate(s.root, in, "")
+	if len(v.errs) > 0 {
+		sort.SliceStable(v.errs, func(i, j int) bool {
+			return v.errs[i].Path < v.errs[j].Path
+		})
+		return v.errs
+	}
+	return nil
+}
+
+// ValidateDocument - parse the JSON or YAML document and validate it against
+// the schema
+func (s *Schema) ValidateDocument(in string) error {
+	var obj interface{}
+	if err := yaml.Unmarshal([]byte(in), &obj); err != nil {
+		return fmt.Errorf("unable to parse document for validation: %v", err)
+	}
+	return s.Validate(obj)
+}
+
+// Validate - validate the value against the JSON Schema read from the given
+// datasource, returning the value unchanged when it's valid
+func (d *Data) Validate(alias string, in interface{}) (interface{}, error) {
+	doc, err := d.Datasource(alias)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := NewSchema(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema in datasource '%s': %v", alias, err)
+	}
+	if err := schema.Validate(in); err != nil {
+		return nil, fmt.Errorf("validation against schema '%s' failed: %v", alias, err)
+	}
+	return in, nil
+}
+
+// maximum depth of nested $refs before giving up, to avoid looping forever on
+// recursive schemas
+const maxRefDepth = 100
+
+type validator struct {
+	schema   *Schema
+	errs     ValidationErrors
+	refDepth int
+}
+
+func (v *validator) fail(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// valid - whether the value matches the schema, without recording errors
+func (v *validator) valid(schema, in interface{}, path string) bool {
+	sub := &validator{schema: v.schema, refDepth: v.refDepth}
+	sub.validate(schema, in, path)
+	return len(sub.errs) == 0
+}
+
+func (v *validator) validate(schema, in interface{}, path string) {
+	if b, ok := schema.(bool); ok {
+		if !b {
+			v.fail(path, "no value is allowed here")
+		}
+		return
+	}
+	s, err := stringMap(schema)
+	if err != nil {
+		v.fail(path, "invalid schema: %v", err)
+		return
+	}
+
+	// as of draft 7, keywords alongside $ref are ignored
+	if ref, ok := s["$ref"]; ok {
+		v.validateRef(fmt.Sprint(ref), in, path)
+		return
+	}
+
+	v.validateType(s, in, path)
+	v.validateEnum(s, in, path)
+	if n, ok := toFloat(in); ok {
+		v.validateNumber(s, n, path)
+	}
+	if str, ok := in.(string); ok {
+		v.validateString(s, str, path)
+	}
+	if arr, ok := schemaList(in); ok {
+		v.validateArray(s, arr, path)
+	}
+	if obj, err := stringMap(in); err == nil {
+		v.validateObject(s, obj, path)
+	}
+	v.validateCombinators(s, in, path)
+}
+
+func (v *validator) validateRef(ref string, in interface{}, path string) {
+	if v.refDepth >= maxRefDepth {
+		v.fail(path, "$ref %q nested too deeply", ref)
+		return
+	}
+	target, err := v.schema.resolve(ref)
+	if err != nil {
+		v.fail(path, "%v", err)
+		return
+	}
+	v.refDepth++
+	v.validate(target, in, path)
+	v.refDepth--
+}
+
+// resolve - find the subschema referred to by a local `$ref` (`#` or
+// `#/json/pointer`)
+func (s *Schema) resolve(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref %q - only references within the schema (starting with #) are supported", ref)
+	}
+	ptr, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref %q: %v", ref, err)
+	}
+	cur := s.root
+	if ptr == "" {
+		return cur, nil
+	}
+	for _, tok := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+		tok = strings.Replace(strings.Replace(tok, "~1", "/", -1), "~0", "~", -1)
+		if m, err := stringMap(cur); err == nil {
+			next, ok := m[tok]
+			if !ok {
+				return nil, fmt.Errorf("$ref %q not found in schema", ref)
+			}
+			cur = next
+			continue
+		}
+		if arr, ok := schemaList(cur); ok {
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(arr) {
+				return nil, fmt.Errorf("$ref %q not found in schema", ref)
+			}
+			cur = arr[i]
+			continue
+		}
+		return nil, fmt.Errorf("$ref %q not found in schema", ref)
+	}
+	return cur, nil
+}
+
+// jsonType - the JSON Schema type name of the value
+func jsonType(in interface{}) string {
+	switch in.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case time.Time:
+		// YAML timestamps and typed CSV dates
+		return "string"
+	case OrderedMap:
+		return "object"
+	}
+	if n, ok := toFloat(in); ok {
+		if n == math.Trunc(n) {
+			return "integer"
+		}
+		return "number"
+	}
+	switch reflect.ValueOf(in).Kind() {
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map:
+		return "object"
+	}
+	return fmt.Sprintf("%T", in)
+}
+
+func (v *validator) validateType(s map[string]interface{}, in interface{}, path string) {
+	t, ok := s["type"]
+	if !ok {
+		return
+	}
+	types := []string{}
+	if list, ok := schemaList(t); ok {
+		for _, item := range list {
+			types = append(types, fmt.Sprint(item))
+		}
+	} else {
+		types = append(types, fmt.Sprint(t))
+	}
+
+	actual := jsonType(in)
+	for _, want := range types {
+		if want == actual || (want == "number" && actual == "integer") {
+			return
+		}
+	}
+	v.fail(path, "expected %s, but got %s", strings.Join(types, " or "), actual)
+}
+
+func (v *validator) validateEnum(s map[string]interface{}, in interface{}, path string) {
+	if c, ok := s["const"]; ok && !jsonEqual(c, in) {
+		v.fail(path, "must be %s", shortJSON(c))
+	}
+	enum, ok := s["enum"]
+	if !ok {
+		return
+	}
+	list, _ := schemaList(enum)
+	for _, e := range list {
+		if jsonEqual(e, in) {
+			return
+		}
+	}
+	vals := make([]string, len(list))
+	for i, e := range list {
+		vals[i] = shortJSON(e)
+	}
+	v.fail(path, "must be one of %s", strings.Join(vals, ", "))
+}
+
+func (v *validator) validateNumber(s map[string]interface{}, n float64, path string) {
+	if m, ok := schemaNumber(s, "multipleOf"); ok && m > 0 {
+		q := n / m
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(path, "must be a multiple of %v", m)
+		}
+	}
+	if max, ok := schemaNumber(s, "maximum"); ok {
+		// draft 4's boolean exclusiveMaximum is also supported
+		if b, _ := s["exclusiveMaximum"].(bool); b && n >= max {
+			v.fail(path, "must be less than %v", max)
+		} else if n > max {
+			v.fail(path, "must be less than or equal to %v", max)
+		}
+	}
+	if max, ok := schemaNumber(s, "exclusiveMaximum"); ok && n >= max {
+		v.fail(path, "must be less than %v", max)
+	}
+	if min, ok := schemaNumber(s, "minimum"); ok {
+		if b, _ := s["exclusiveMinimum"].(bool); b && n <= min {
+			v.fail(path, "must be greater than %v", min)
+		} else if n < min {
+			v.fail(path, "must be greater than or equal to %v", min)
+		}
+	}
+	if min, ok := schemaNumber(s, "exclusiveMinimum"); ok && n <= min {
+		v.fail(path, "must be greater than %v", min)
+	}
+}
+
+func schemaNumber(s map[string]interface{}, key string) (float64, bool) {
+	n, ok := s[key]
+	if !ok {
+		return 0, false
+	}
+	return toFloat(n)
+}
+
+func (v *validator) validateString(s map[string]interface{}, str, path string) {
+	length := utf8.RuneCountInString(str)
+	if max, ok := schemaNumber(s, "maxLength"); ok && float64(length) > max {
+		v.fail(path, "must be at most %v characters long", max)
+	}
+	if min, ok := schemaNumber(s, "minLength"); ok && float64(length) < min {
+		v.fail(path, "must be at least %v characters long", min)
+	}
+	if p, ok := s["pattern"]; ok {
+		re, err := v.schema.pattern(fmt.Sprint(p))
+		if err != nil {
+			v.fail(path, "invalid pattern in schema: %v", err)
+		} else if !re.MatchString(str) {
+			v.fail(path, "must match the pattern %q", p)
+		}
+	}
+	if f, ok := s["format"]; ok {
+		if err := checkFormat(fmt.Sprint(f), str); err != nil {
+			v.fail(path, "%v", err)
+		}
+	}
+}
+
+// pattern - compile the regular expression, caching the result
+func (s *Schema) pattern(p string) (*regexp.Regexp, error) {
+	if re, ok := s.patterns[p]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(p)
+	if err != nil {
+		return nil, err
+	}
+	s.patterns[p] = re
+	return re, nil
+}
+
+// checkFormat - validate some of the most common formats. As allowed by the
+// spec, other formats are not checked.
+func checkFormat(format, s string) error {
+	var err error
+	switch format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339Nano, s)
+	case "date":
+		_, err = time.Parse("2006-01-02", s)
+	case "time":
+		_, err = time.Parse("15:04:05Z07:00", s)
+	case "email":
+		if i := strings.LastIndex(s, "@"); i < 1 || i == len(s)-1 {
+			err = fmt.Errorf("missing @")
+		}
+	case "ipv4":
+		if ip := net.ParseIP(s); ip == nil || ip.To4() == nil || strings.Contains(s, ":") {
+			err = fmt.Errorf("invalid address")
+		}
+	case "ipv6":
+		if ip := net.ParseIP(s); ip == nil || !strings.Contains(s, ":") {
+			err = fmt.Errorf("invalid address")
+		}
+	case "uri":
+		var u *url.URL
+		if u, err = url.Parse(s); err == nil && !u.IsAbs() {
+			err = fmt.Errorf("not absolute")
+		}
+	case "regex":
+		_, err = regexp.Compile(s)
+	}
+	if err != nil {
+		return fmt.Errorf("%q is not a valid %s", s, format)
+	}
+	return nil
+}
+
+func (v *validator) validateArray(s map[string]interface{}, arr []interface{}, path string) {
+	if max, ok := schemaNumber(s, "maxItems"); ok && float64(len(arr)) > max {
+		v.fail(path, "must have at most %v items", max)
+	}
+	if min, ok := schemaNumber(s, "minItems"); ok && float64(len(arr)) < min {
+		v.fail(path, "must have at least %v items", min)
+	}
+	if u, _ := s["uniqueItems"].(bool); u {
+		for i := range arr {
+			for j := 0; j < i; j++ {
+				if jsonEqual(arr[i], arr[j]) {
+					v.fail(path, "items %d and %d are equal, but items must be unique", j, i)
+				}
+			}
+		}
+	}
+
+	if items, ok := s["items"]; ok {
+		if tuple, ok := schemaList(items); ok {
+			for i, item := range arr {
+				p := fmt.Sprintf("%s/%d", path, i)
+				if i < len(tuple) {
+					v.validate(tuple[i], item, p)
+				} else if additional, ok := s["additionalItems"]; ok {
+					v.validate(additional, item, p)
+				}
+			}
+		} else {
+			for i, item := range arr {
+				v.validate(items, item, fmt.Sprintf("%s/%d", path, i))
+			}
+		}
+	}
+
+	if contains, ok := s["contains"]; ok {
+		for i, item := range arr {
+			if v.valid(contains, item, fmt.Sprintf("%s/%d", path, i)) {
+				return
+			}
+		}
+		v.fail(path, "must contain at least one item matching the 'contains' schema")
+	}
+}
+
+func (v *validator) validateObject(s map[string]interface{}, obj map[string]interface{}, path string) {
+	if max, ok := schemaNumber(s, "maxProperties"); ok && float64(len(obj)) > max {
+		v.fail(path, "must have at most %v properties", max)
+	}
+	if min, ok := schemaNumber(s, "minProperties"); ok && float64(len(obj)) < min {
+		v.fail(path, "must have at least %v properties", min)
+	}
+	if req, ok := schemaList(s["required"]); ok {
+		for _, r := range req {
+			if _, ok := obj[fmt.Sprint(r)]; !ok {
+				v.fail(path, "missing required property %q", r)
+			}
+		}
+	}
+
+	props, _ := stringMap(s["properties"])
+	patterns, _ := stringMap(s["patternProperties"])
+	additional, hasAdditional := s["additionalProperties"]
+	names, hasNames := s["propertyNames"]
+	deps, _ := stringMap(s["dependencies"])
+
+	for _, k := range sortedKeys(obj) {
+		p := path + "/" + escapePointer(k)
+		if hasNames && !v.valid(names, k, p) {
+			v.fail(p, "property name %q doesn't match the 'propertyNames' schema", k)
+		}
+
+		matched := false
+		if sub, ok := props[k]; ok {
+			matched = true
+			v.validate(sub, obj[k], p)
+		}
+		for _, pat := range sortedKeys(patterns) {
+			re, err := v.schema.pattern(pat)
+			if err != nil {
+				v.fail(path, "invalid pattern in schema: %v", err)
+				continue
+			}
+			if re.MatchString(k) {
+				matched = true
+				v.validate(patterns[pat], obj[k], p)
+			}
+		}
+		if !matched && hasAdditional {
+			if b, ok := additional.(bool); ok && !b {
+				v.fail(p, "additional property %q is not allowed", k)
+			} else {
+				v.validate(additional, obj[k], p)
+			}
+		}
+
+		if dep, ok := deps[k]; ok {
+			if list, ok := schemaList(dep); ok {
+				for _, d := range list {
+					if _, ok := obj[fmt.Sprint(d)]; !ok {
+						v.fail(path, "property %q is required when %q is present", d, k)
+					}
+				}
+			} else {
+				v.validate(dep, obj, path)
+			}
+		}
+	}
+}
+
+func (v *validator) validateCombinators(s map[string]interface{}, in interface{}, path string) {
+	if all, ok := schemaList(s["allOf"]); ok {
+		for _, sub := range all {
+			v.validate(sub, in, path)
+		}
+	}
+	if any, ok := schemaList(s["anyOf"]); ok {
+		matched := false
+		for _, sub := range any {
+			if v.valid(sub, in, path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(path, "must match at least one of the 'anyOf' schemas")
+		}
+	}
+	if one, ok := schemaList(s["oneOf"]); ok {
+		n := 0
+		for _, sub := range one {
+			if v.valid(sub, in, path) {
+				n++
+			}
+		}
+		if n != 1 {
+			v.fail(path, "must match exactly one of the 'oneOf' schemas, but matched %d", n)
+		}
+	}
+	if not, ok := s["not"]; ok && v.valid(not, in, path) {
+		v.fail(path, "must not match the 'not' schema")
+	}
+	if cond, ok := s["if"]; ok {
+		if v.valid(cond, in, path) {
+			if then, ok := s["then"]; ok {
+				v.validate(then, in, path)
+			}
+		} else if els, ok := s["else"]; ok {
+			v.validate(els, in, path)
+		}
+	}
+}
+
+// jsonEqual - deep equality, treating all numbers of the same value as equal
+func jsonEqual(a, b interface{}) bool {
+	if an, ok := toFloat(a); ok {
+		bn, ok := toFloat(b)
+		return ok && an == bn
+	}
+	if am, err := stringMap(a); err == nil {
+		bm, err := stringMap(b)
+		if err != nil || len(am) != len(bm) {
+			return false
+		}
+		for k, av := range am {
+			bv, ok := bm[k]
+			if !ok || !jsonEqual(av, bv) {
+				return false
+			}
+		}
+		return true
+	}
+	if as, ok := schemaList(a); ok {
+		bs, ok := schemaList(b)
+		if !ok || len(as) != len(bs) {
+			return false
+		}
+		for i := range as {
+			if !jsonEqual(as[i], bs[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// schemaList - the value as an array, if it is one
+func schemaList(in interface{}) ([]interface{}, bool) {
+	if in == nil {
+		return nil, false
+	}
+	l, err := interfaceSlice(in)
+	return l, err == nil
+}
+
+// escapePointer - escape a key for use in a JSON Pointer
+func escapePointer(k string) string {
+	return strings.Replace(strings.Replace(k, "~", "~0", -1), "/", "~1", -1)
+}
+
+func shortJSON(in interface{}) string {
+	if s, ok := in.(string); ok {
+		return strconv.Quote(s)
+	}
+	if in == nil {
+		return "null"
+	}
+	return fmt.Sprint(in)
+}
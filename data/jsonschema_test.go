@@ -0,0 +1,171 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustSchema(t *testing.T, in string) *Schema {
+	s, err := ParseSchema(in)
+	assert.NoError(t, err)
+	return s
+}
+
+func violations(err error) []string {
+	if err == nil {
+		return nil
+	}
+	out := []string{}
+	for _, v := range err.(ValidationErrors) {
+		out = append(out, v.Error())
+	}
+	return out
+}
+
+func TestSchemaValidate(t *testing.T) {
+	s := mustSchema(t, `{
+		"type": "object",
+		"required": ["name", "port"],
+		"properties": {
+			"name": {"type": "string", "minLength": 2, "pattern": "^[a-z]+$"},
+			"port": {"type": "integer", "minimum": 1, "maximum": 65535},
+			"mode": {"enum": ["dev", "prod"]},
+			"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true},
+			"a/b": {"type": "boolean"}
+		},
+		"additionalProperties": false
+	}`)
+
+	assert.NoError(t, s.Validate(JSON(`{"name": "web", "port": 80, "mode": "prod", "tags": ["a", "b"]}`)))
+	assert.NoError(t, s.Validate(YAML("name: web\nport: 8080\n")))
+
+	err := s.Validate(YAML(`
+name: W
+port: 70000.5
+mode: test
+tags: [a, 1, a]
+a/b: yes please
+extra: true
+`))
+	assert.Equal(t, []string{
+		`#/a~1b: expected boolean, but got string`,
+		`#/extra: additional property "extra" is not allowed`,
+		`#/mode: must be one of "dev", "prod"`,
+		`#/name: must be at least 2 characters long`,
+		`#/name: must match the pattern "^[a-z]+$"`,
+		`#/port: expected integer, but got number`,
+		`#/port: must be less than or equal to 65535`,
+		`#/tags: items 0 and 2 are equal, but items must be unique`,
+		`#/tags/1: expected string, but got integer`,
+	}, violations(err))
+
+	err = s.Validate(JSON(`{}`))
+	assert.Equal(t, []string{
+		`#: missing required property "name"`,
+		`#: missing required property "port"`,
+	}, violations(err))
+
+	err = s.Validate(JSONArray(`[]`))
+	assert.Equal(t, []string{`#: expected object, but got array`}, violations(err))
+}
+
+func TestSchemaRefsAndCombinators(t *testing.T) {
+	s := mustSchema(t, `{
+		"definitions": {
+			"node": {
+				"type": "object",
+				"properties": {
+					"value": {"type": "number"},
+					"children": {"type": "array", "items": {"$ref": "#/definitions/node"}}
+				}
+			}
+		},
+		"type": "object",
+		"properties": {
+			"tree": {"$ref": "#/definitions/node"},
+			"id": {"oneOf": [{"type": "string"}, {"type": "integer"}]},
+			"size": {"anyOf": [{"const": "auto"}, {"type": "integer", "exclusiveMinimum": 0}]},
+			"name": {"not": {"const": "root"}},
+			"kind": {"type": "string"},
+			"list": {"contains": {"const": 42}}
+		},
+		"if": {"required": ["kind"], "properties": {"kind": {"const": "web"}}},
+		"then": {"required": ["port"]},
+		"dependencies": {"id": ["name"]}
+	}`)
+
+	assert.NoError(t, s.Validate(JSON(`{"tree": {"value": 1, "children": [{"value": 2}]}, "id": 1, "name": "x", "size": "auto", "list": [1, 42]}`)))
+
+	err := s.Validate(JSON(`{
+		"tree": {"value": 1, "children": [{"value": "two"}]},
+		"id": 1.5,
+		"size": 0,
+		"name": "root",
+		"kind": "web",
+		"list": [1, 2]
+	}`))
+	assert.Equal(t, []string{
+		`#: missing required property "port"`,
+		`#/id: must match exactly one of the 'oneOf' schemas, but matched 0`,
+		`#/list: must contain at least one item matching the 'contains' schema`,
+		`#/name: must not match the 'not' schema`,
+		`#/size: must match at least one of the 'anyOf' schemas`,
+		`#/tree/children/0/value: expected number, but got string`,
+	}, violations(err))
+
+	err = s.Validate(JSON(`{"id": "abc"}`))
+	assert.Equal(t, []string{`#: property "name" is required when "id" is present`}, violations(err))
+}
+
+func TestSchemaFormats(t *testing.T) {
+	s := mustSchema(t, `{"type": "array", "items": [
+		{"format": "date-time"},
+		{"format": "date"},
+		{"format": "email"},
+		{"format": "ipv4"},
+		{"format": "ipv6"},
+		{"format": "uri"}
+	], "additionalItems": false}`)
+
+	assert.NoError(t, s.Validate(JSONArray(`["2018-01-02T03:04:05Z", "2018-01-02", "a@example.com", "10.0.0.1", "::1", "https://example.com"]`)))
+
+	err := s.Validate(JSONArray(`["yesterday", "2018-13-01", "nobody", "::1", "10.0.0.1", "/relative", "extra"]`))
+	assert.Equal(t, []string{
+		`#/0: "yesterday" is not a valid date-time`,
+		`#/1: "2018-13-01" is not a valid date`,
+		`#/2: "nobody" is not a valid email`,
+		`#/3: "::1" is not a valid ipv4`,
+		`#/4: "10.0.0.1" is not a valid ipv6`,
+		`#/5: "/relative" is not a valid uri`,
+		`#/6: no value is allowed here`,
+	}, violations(err))
+}
+
+func TestSchemaErrors(t *testing.T) {
+	_, err := ParseSchema(`[1, 2]`)
+	assert.Error(t, err)
+
+	_, err = ParseSchema(`{`)
+	assert.Error(t, err)
+
+	s := mustSchema(t, `{"$ref": "http://example.com/schema.json"}`)
+	assert.Error(t, s.Validate("foo"))
+
+	s = mustSchema(t, `{"$ref": "#/definitions/missing"}`)
+	assert.Error(t, s.Validate("foo"))
+
+	s = mustSchema(t, `{"$ref": "#"}`)
+	assert.Error(t, s.Validate("foo"))
+
+	s = mustSchema(t, `true`)
+	assert.NoError(t, s.Validate("anything"))
+}
+
+func TestSchemaValidateDocument(t *testing.T) {
+	s := mustSchema(t, `{"type": "object", "required": ["a"]}`)
+	assert.NoError(t, s.ValidateDocument(`{"a": 1}`))
+	assert.NoError(t, s.ValidateDocument("a: 1\n"))
+	assert.EqualError(t, s.ValidateDocument("b: 1\n"), "1 schema violation(s):\n  #: missing required property \"a\"")
+	assert.Error(t, s.ValidateDocument("a: [1\n"))
+}
@@ -0,0 +1,432 @@
+package data
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/hairyhenderson/toml"
+	"github.com/ugorji/go/codec"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// MapItem - a key and its value in an OrderedMap
+type MapItem struct {
+	Key   string
+	Value interface{}
+}
+
+// OrderedMap - an object which keeps the order of its keys, as produced by
+// JSONOrdered, YAMLOrdered, TOMLOrdered, XMLOrdered, and datasources with
+// the `ordered` query parameter set. The output functions (ToJSON, ToYAML,
+// ToTOML, etc.) write its keys in order.
+//
+// Templates can't look up keys in an OrderedMap with `.key` (that only works
+// with real maps and structs), so values are read with the Get method (or
+// converted with Map or SortKeys). Ranging over it produces MapItems in
+// order.
+type OrderedMap []MapItem
+
+// Get - the value at the given key, or nil if there's no such key
+func (m OrderedMap) Get(key string) interface{} {
+	if i := m.find(key); i >= 0 {
+		return m[i].Value
+	}
+	return nil
+}
+
+// Has - whether the map contains the given key
+func (m OrderedMap) Has(key string) bool {
+	return m.find(key) >= 0
+}
+
+// Keys - the keys of the map, in order
+func (m OrderedMap) Keys() []string {
+	keys := make([]string, len(m))
+	for i, item := range m {
+		keys[i] = item.Key
+	}
+	return keys
+}
+
+// Values - the values of the map, in the same order as Keys
+func (m OrderedMap) Values() []interface{} {
+	values := make([]interface{}, len(m))
+	for i, item := range m {
+		values[i] = item.Value
+	}
+	return values
+}
+
+// Map - the map's keys and values as a plain map, which can be used with
+// `.key` lookups in templates. Nested OrderedMaps are left as they are.
+func (m OrderedMap) Map() map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for _, item := range m {
+		out[item.Key] = item.Value
+	}
+	return out
+}
+
+func (m OrderedMap) find(key string) int {
+	for i, item := range m {
+		if item.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Set - a copy of the map with the key set to the value, added at the end if
+// it's new
+func (m OrderedMap) Set(key string, value interface{}) OrderedMap {
+	i := m.find(key)
+	if i < 0 {
+		out := make(OrderedMap, len(m), len(m)+1)
+		copy(out, m)
+		return append(out, MapItem{Key: key, Value: value})
+	}
+	out := make(OrderedMap, len(m))
+	copy(out, m)
+	out[i].Value = value
+	return out
+}
+
+// remove - a copy of the map without the key
+func (m OrderedMap) remove(key string) OrderedMap {
+	out := make(OrderedMap, 0, len(m))
+	for _, item := range m {
+		if item.Key != key {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// mapEntries - the entries of an OrderedMap, or of any other map (with its
+// keys converted to strings) in alphabetical order, as plain maps have no
+// order of their own
+func mapEntries(in interface{}) (OrderedMap, error) {
+	if m, ok := in.(OrderedMap); ok {
+		return m, nil
+	}
+	m, err := stringMap(in)
+	if err != nil {
+		return nil, err
+	}
+	out := make(OrderedMap, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		out = append(out, MapItem{Key: k, Value: m[k]})
+	}
+	return out, nil
+}
+
+// SortKeys - return a copy of the given value with all OrderedMaps replaced
+// by plain maps, so that it's output with keys in alphabetical order (and so
+// that keys can be looked up with `.key` in templates)
+func SortKeys(in interface{}) interface{} {
+	return toOrdered(in, func(m OrderedMap) interface{} {
+		out := make(map[string]interface{}, len(m))
+		for _, item := range m {
+			out[item.Key] = SortKeys(item.Value)
+		}
+		return out
+	})
+}
+
+// JSONOrdered - Unmarshal a JSON Object into an OrderedMap
+func JSONOrdered(in string) OrderedMap {
+	obj, err := unmarshalOrdered(in)
+	if err != nil {
+		log.Fatalf("Unable to unmarshal object %s: %v", in, err)
+	}
+	return obj
+}
+
+// unmarshalOrdered - unmarshal a JSON or YAML Object into an OrderedMap
+func unmarshalOrdered(in string) (OrderedMap, error) {
+	obj := OrderedMap{}
+	err := yamlUnmarshalOrdered([]byte(in), &obj)
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// YAMLOrdered - Unmarshal a YAML Object into an OrderedMap
+func YAMLOrdered(in string) OrderedMap {
+	return JSONOrdered(in)
+}
+
+// TOMLOrdered - Unmarshal a TOML Object into an OrderedMap
+func TOMLOrdered(in string) OrderedMap {
+	obj, err := tomlOrdered(in)
+	if err != nil {
+		log.Fatalf("Unable to unmarshal object %s: %v", in, err)
+	}
+	return obj
+}
+
+// orderedValue - decodes any YAML (or JSON) value, keeping the key order of
+// mappings by decoding them into yaml.MapSlice. Everything inside a mapping
+// is decoded along with it, with nested mappings as yaml.MapSlices too, so
+// each node is only decoded once.
+type orderedValue struct {
+	v interface{}
+}
+
+func (o *orderedValue) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	// a mapping (or null) can be decoded into an empty struct without
+	// decoding any of its values, which makes for a cheap way to find out
+	// what kind of node this is
+	var probe *struct{}
+	if err := unmarshal(&probe); err == nil {
+		if probe == nil {
+			o.v = nil
+			return nil
+		}
+		ms := yaml.MapSlice{}
+		if err := unmarshal(&ms); err != nil {
+			return err
+		}
+		o.v = ms
+		return nil
+	}
+	// decoding into []orderedValue means maps inside the sequence are
+	// ordered too - scalars fail quickly here
+	s := []orderedValue{}
+	if err := unmarshal(&s); err == nil {
+		o.v = s
+		return nil
+	}
+	var v interface{}
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	o.v = v
+	return nil
+}
+
+// fromMapSlice - convert the yaml.MapSlices in a decoded orderedValue to
+// OrderedMaps
+func fromMapSlice(in interface{}) interface{} {
+	switch v := in.(type) {
+	case yaml.MapSlice:
+		m := make(OrderedMap, len(v))
+		for i, item := range v {
+			m[i] = MapItem{Key: fmt.Sprint(item.Key), Value: fromMapSlice(item.Value)}
+		}
+		return m
+	case []orderedValue:
+		s := make([]interface{}, len(v))
+		for i, item := range v {
+			s[i] = fromMapSlice(item.v)
+		}
+		return s
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, item := range v {
+			s[i] = fromMapSlice(item)
+		}
+		return s
+	}
+	return in
+}
+
+// yamlUnmarshalOrdered - like yaml.Unmarshal (as used for both YAML and
+// JSON), but decoding all mappings into OrderedMaps
+func yamlUnmarshalOrdered(in []byte, out interface{}) error {
+	o := orderedValue{}
+	if err := yaml.Unmarshal(in, &o); err != nil {
+		return err
+	}
+	v := fromMapSlice(o.v)
+	switch p := out.(type) {
+	case *interface{}:
+		*p = v
+	case *OrderedMap:
+		if v == nil {
+			return nil
+		}
+		m, ok := v.(OrderedMap)
+		if !ok {
+			return fmt.Errorf("expected an object, got %s", typeName(v))
+		}
+		*p = m
+	case *[]interface{}:
+		if v == nil {
+			return nil
+		}
+		a, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected an array, got %s", typeName(v))
+		}
+		*p = a
+	default:
+		return fmt.Errorf("can't decode into %T", out)
+	}
+	return nil
+}
+
+// tomlOrdered - unmarshal a TOML document into an OrderedMap, ordering the
+// keys of each table by the keys reported in the decoder's metadata
+func tomlOrdered(in string) (OrderedMap, error) {
+	obj := make(map[string]interface{})
+	md, err := toml.Decode(in, &obj)
+	if err != nil {
+		return nil, err
+	}
+
+	// the keys of each table, by path (joined with NULs, which can't be in
+	// keys) - the tables in an array of tables share a path
+	orders := make(map[string][]string)
+	for _, key := range md.Keys() {
+		parent := strings.Join(key[:len(key)-1], "\x00")
+		leaf := key[len(key)-1]
+		if !inStrings(orders[parent], leaf) {
+			orders[parent] = append(orders[parent], leaf)
+		}
+	}
+	return tomlTable(obj, "", orders), nil
+}
+
+func tomlTable(m map[string]interface{}, path string, orders map[string][]string) OrderedMap {
+	keys := orders[path]
+	for _, k := range sortedKeys(m) {
+		if !inStrings(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	out := make(OrderedMap, 0, len(m))
+	for _, k := range keys {
+		v, ok := m[k]
+		if !ok {
+			continue
+		}
+		p := k
+		if path != "" {
+			p = path + "\x00" + k
+		}
+		out = append(out, MapItem{Key: k, Value: tomlValue(v, p, orders)})
+	}
+	return out
+}
+
+func tomlValue(v interface{}, path string, orders map[string][]string) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return tomlTable(t, path, orders)
+	case []map[string]interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			out[i] = tomlTable(item, path, orders)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			out[i] = tomlValue(item, path, orders)
+		}
+		return out
+	}
+	return v
+}
+
+// jsonMap - a map encoded by the JSON codec as an ordered sequence of
+// alternating keys and values
+type jsonMap []interface{}
+
+// MapBySlice - tells the codec to encode this slice as a map
+func (jsonMap) MapBySlice() {}
+
+var _ codec.MapBySlice = jsonMap{}
+
+// toOrderedJSON - replace OrderedMaps with jsonMaps
+func toOrderedJSON(in interface{}) interface{} {
+	return toOrdered(in, func(m OrderedMap) interface{} {
+		out := make(jsonMap, 0, len(m)*2)
+		for _, item := range m {
+			out = append(out, item.Key, toOrderedJSON(item.Value))
+		}
+		return out
+	})
+}
+
+// toOrderedYAML - replace OrderedMaps with yaml.MapSlices
+func toOrderedYAML(in interface{}) interface{} {
+	return toOrdered(in, func(m OrderedMap) interface{} {
+		out := make(yaml.MapSlice, len(m))
+		for i, item := range m {
+			out[i] = yaml.MapItem{Key: item.Key, Value: toOrderedYAML(item.Value)}
+		}
+		return out
+	})
+}
+
+// toOrderedTOML - replace OrderedMaps with structs, since the TOML encoder
+// always sorts map keys, but keeps struct fields in order
+func toOrderedTOML(in interface{}) interface{} {
+	return toOrdered(in, func(m OrderedMap) interface{} {
+		fields := []reflect.StructField{}
+		values := []reflect.Value{}
+		for _, item := range m {
+			if strings.ContainsAny(item.Key, `,"`) {
+				// can't be represented as a struct tag - fall back to
+				// sorted output
+				return toOrderedTOML(m.Map())
+			}
+			value := toOrderedTOML(item.Value)
+			if value == nil {
+				continue
+			}
+			fields = append(fields, reflect.StructField{
+				Name: fmt.Sprintf("F%d", len(fields)),
+				Type: reflect.TypeOf((*interface{})(nil)).Elem(),
+				Tag:  reflect.StructTag(fmt.Sprintf(`toml:"%s"`, item.Key)),
+			})
+			values = append(values, reflect.ValueOf(value))
+		}
+		s := reflect.New(reflect.StructOf(fields)).Elem()
+		for i, value := range values {
+			s.Field(i).Set(value)
+		}
+		return s.Interface()
+	})
+}
+
+// toOrdered - walk the given value, replacing OrderedMaps with the result of
+// calling ordered. Other maps and arrays are copied (when possible) so that
+// OrderedMaps nested inside them are found too.
+func toOrdered(in interface{}, ordered func(OrderedMap) interface{}) interface{} {
+	if m, ok := in.(OrderedMap); ok {
+		return ordered(m)
+	}
+	v := reflect.ValueOf(in)
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() || v.Type().Elem().Kind() != reflect.Interface {
+			return in
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, k := range v.MapKeys() {
+			value := reflect.Zero(v.Type().Elem())
+			if o := toOrdered(v.MapIndex(k).Interface(), ordered); o != nil {
+				value = reflect.ValueOf(o)
+			}
+			out.SetMapIndex(k, value)
+		}
+		return out.Interface()
+	case reflect.Slice:
+		elemKind := v.Type().Elem().Kind()
+		if v.IsNil() || (elemKind != reflect.Interface && elemKind != reflect.Map) {
+			return in
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = toOrdered(v.Index(i).Interface(), ordered)
+		}
+		return out
+	}
+	return in
+}
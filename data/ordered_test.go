@@ -0,0 +1,133 @@
+package data
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedMap(t *testing.T) {
+	m := OrderedMap{{"z", 1}, {"a", 2}}
+	assert.Equal(t, 1, m.Get("z"))
+	assert.Nil(t, m.Get("b"))
+	assert.True(t, m.Has("a"))
+	assert.False(t, m.Has("b"))
+	assert.Equal(t, []string{"z", "a"}, m.Keys())
+	assert.Equal(t, []interface{}{1, 2}, m.Values())
+	assert.Equal(t, map[string]interface{}{"z": 1, "a": 2}, m.Map())
+
+	// Set and remove return copies
+	assert.Equal(t, OrderedMap{{"z", 1}, {"a", 2}, {"b", 3}}, m.Set("b", 3))
+	assert.Equal(t, OrderedMap{{"z", 3}, {"a", 2}}, m.Set("z", 3))
+	assert.Equal(t, OrderedMap{{"a", 2}}, m.remove("z"))
+	assert.Equal(t, OrderedMap{{"z", 1}, {"a", 2}}, m)
+}
+
+func TestOrderedJSON(t *testing.T) {
+	in := `{"zebra":1,"apple":{"z":true,"a":false},"mango":[{"y":1,"b":2}]}`
+	assert.Equal(t, in, ToJSON(JSONOrdered(in)))
+
+	expected := `{
+  "zebra": 1,
+  "apple": {
+    "z": true,
+    "a": false
+  },
+  "mango": [
+    {
+      "y": 1,
+      "b": 2
+    }
+  ]
+}`
+	assert.Equal(t, expected, ToJSONPretty("  ", JSONOrdered(in)))
+
+	// plain maps are sorted, but OrderedMaps inside them keep their order
+	nested := map[string]interface{}{"b": JSONOrdered(`{"z":1,"a":2}`), "a": 1}
+	assert.Equal(t, `{"a":1,"b":{"z":1,"a":2}}`, ToJSON(nested))
+
+	// copies keep the order
+	m := JSONOrdered(`{"z":1,"a":2}`).Set("c", 3)
+	assert.Equal(t, `{"z":1,"a":2,"c":3}`, ToJSON(m))
+
+	assert.Equal(t, `{"apple":{"a":false,"z":true},"mango":[{"b":2,"y":1}],"zebra":1}`, ToJSON(JSON(in)))
+}
+
+func TestOrderedYAML(t *testing.T) {
+	in := `name: example
+version: 2
+dependencies:
+  zlib: 1.2
+  abseil: 3
+list:
+- z: 1
+  a: 2
+`
+	assert.Equal(t, in, ToYAML(YAMLOrdered(in)))
+	assert.Equal(t, `{"name":"example","version":2,"dependencies":{"zlib":1.2,"abseil":3},"list":[{"z":1,"a":2}]}`, ToJSON(YAMLOrdered(in)))
+
+	docs, err := yamlDocuments("---\nz: 1\na: 2\n---\nx: 1\nb: 2\n", yamlUnmarshalOrdered)
+	assert.NoError(t, err)
+	assert.Equal(t, "---\nz: 1\na: 2\n---\nx: 1\nb: 2\n", ToYAMLDocuments(docs))
+}
+
+func TestOrderedTOML(t *testing.T) {
+	in := `title = "example"
+owner = "me"
+
+[servers]
+  [servers.web]
+    port = 80
+    host = "example.com"
+
+[[products]]
+  name = "Hammer"
+  sku = 738594937
+
+[[products]]
+  name = "Nail"
+  sku = 284758393
+`
+	assert.Equal(t, in, ToTOML(TOMLOrdered(in)))
+	assert.Equal(t, `{"title":"example","owner":"me","servers":{"web":{"port":80,"host":"example.com"}},"products":[{"name":"Hammer","sku":738594937},{"name":"Nail","sku":284758393}]}`, ToJSON(TOMLOrdered(in)))
+}
+
+func TestOrderedOtherFormats(t *testing.T) {
+	in := JSONOrdered(`{"z":"1","a":{"y":"2","b":"3"}}`)
+	assert.Equal(t, "z = \"1\"\na {\n  y = \"2\"\n  b = \"3\"\n}\n", ToHCL(in))
+	assert.Equal(t, "z=1\na.y=2\na.b=3\n", ToProperties(in))
+	assert.Equal(t, "z = 1\n\n[a]\ny = 2\nb = 3\n", ToINI(in))
+	assert.Equal(t, "Z=1\nA=2\n", ToDotenv(JSONOrdered(`{"Z":1,"A":2}`)))
+	assert.Equal(t, "z,a\r\n1,2\r\n", ToCSV([]interface{}{JSONOrdered(`{"z":1,"a":2}`)}))
+}
+
+func TestSortKeys(t *testing.T) {
+	in := JSONOrdered(`{"zebra":1,"apple":{"z":true,"a":false},"mango":[{"x":1,"b":2}]}`)
+	sorted := SortKeys(in)
+	assert.Equal(t, map[string]interface{}{
+		"zebra": 1,
+		"apple": map[string]interface{}{"z": true, "a": false},
+		"mango": []interface{}{map[string]interface{}{"x": 1, "b": 2}},
+	}, sorted)
+	assert.Equal(t, `{"apple":{"a":false,"z":true},"mango":[{"b":2,"x":1}],"zebra":1}`, ToJSON(sorted))
+	assert.Equal(t, "apple:\n  a: false\n  z: true\nmango:\n- b: 2\n  x: 1\nzebra: 1\n", ToYAML(sorted))
+	assert.Nil(t, SortKeys(nil))
+	assert.Equal(t, "foo", SortKeys("foo"))
+}
+
+func TestOrderedNestedSequences(t *testing.T) {
+	in := `[[[{"z":1,"a":[{"y":2,"b":3}]}]],{},null,"x",[]]`
+	var out []interface{}
+	assert.NoError(t, yamlUnmarshalOrdered([]byte(in), &out))
+	assert.Equal(t, in, ToJSON(out))
+
+	// each level of nesting is only decoded once
+	deep := strings.Repeat("[", 200) + `{"z":1,"a":2}` + strings.Repeat("]", 200)
+	assert.NoError(t, yamlUnmarshalOrdered([]byte(deep), &out))
+	assert.Equal(t, deep, ToJSON(out))
+
+	var m OrderedMap
+	assert.Error(t, yamlUnmarshalOrdered([]byte(`[1]`), &m))
+	assert.Equal(t, OrderedMap{{"a", OrderedMap{}}, {"b", nil}}, JSONOrdered(`{"a":{},"b":null}`))
+}
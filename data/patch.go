@@ -0,0 +1,425 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Patch - apply a JSON Patch (RFC 6902) to a copy of the given object or
+// array. The patch can be given as a JSON (or YAML) string, or as an
+// already-parsed array of operations.
+func Patch(patch, in interface{}) (interface{}, error) {
+	p, err := parsePatchArg(patch)
+	if err != nil {
+		return nil, err
+	}
+	ops, ok := schemaList(p)
+	if !ok {
+		return nil, fmt.Errorf("a JSON Patch must be an array of operations, not %T", p)
+	}
+
+	doc := deepCopy(in)
+	for i, o := range ops {
+		op, err := stringMap(o)
+		if err != nil {
+			return nil, fmt.Errorf("patch operation %d: must be an object, not %T", i, o)
+		}
+		doc, err = applyPatchOp(doc, op)
+		if err != nil {
+			return nil, fmt.Errorf("patch operation %d (%s %s): %v", i, op["op"], op["path"], err)
+		}
+	}
+	return doc, nil
+}
+
+// MergePatch - apply a JSON Merge Patch (RFC 7386) to a copy of the given
+// object. Keys in the patch replace those in the object (recursively for
+// objects), and keys set to null in the patch are removed.
+func MergePatch(patch, in interface{}) (interface{}, error) {
+	p, err := parsePatchArg(patch)
+	if err != nil {
+		return nil, err
+	}
+	return mergePatch(deepCopy(in), p), nil
+}
+
+// Diff - produce a JSON Patch (RFC 6902) which transforms from into to
+func Diff(from, to interface{}) []interface{} {
+	ops := []interface{}{}
+	return diff(ops, "", from, to)
+}
+
+func parsePatchArg(patch interface{}) (interface{}, error) {
+	s, ok := patch.(string)
+	if !ok {
+		return patch, nil
+	}
+	p, err := unmarshalDoc(s, yaml.Unmarshal)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse patch: %v", err)
+	}
+	return p, nil
+}
+
+// deepCopy - copy all maps and arrays in the value so that it can be modified
+// without affecting the original. OrderedMaps are kept, and other maps are
+// converted to map[string]interface{}.
+func deepCopy(in interface{}) interface{} {
+	if m, ok := in.(OrderedMap); ok {
+		out := make(OrderedMap, len(m))
+		for i, item := range m {
+			out[i] = MapItem{Key: item.Key, Value: deepCopy(item.Value)}
+		}
+		return out
+	}
+	if m, err := stringMap(in); err == nil {
+		out := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			out[k] = deepCopy(v)
+		}
+		return out
+	}
+	if l, ok := schemaList(in); ok {
+		out := make([]interface{}, len(l))
+		for i, v := range l {
+			out[i] = deepCopy(v)
+		}
+		return out
+	}
+	return in
+}
+
+// pointerTokens - split a JSON Pointer (RFC 6901) into unescaped tokens
+func pointerTokens(ptr string) ([]string, error) {
+	if ptr == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(ptr, "/") {
+		return nil, fmt.Errorf("invalid JSON Pointer %q - must be empty or start with /", ptr)
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(t, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// arrayIndex - parse a JSON Pointer token as an index into an array of length
+// n. When adding, n (or `-`) is allowed, referring to the end of the array.
+func arrayIndex(tok string, n int, adding bool) (int, error) {
+	if tok == "-" && adding {
+		return n, nil
+	}
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || (tok != "0" && strings.HasPrefix(tok, "0")) {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	if i > n || (i == n && !adding) {
+		return 0, fmt.Errorf("array index %d out of bounds", i)
+	}
+	return i, nil
+}
+
+// pointerGet - the value at the location given by the JSON Pointer tokens
+func pointerGet(doc interface{}, tokens []string) (interface{}, error) {
+	for _, tok := range tokens {
+		switch c := doc.(type) {
+		case map[string]interface{}:
+			v, ok := c[tok]
+			if !ok {
+				return nil, fmt.Errorf("no such key %q", tok)
+			}
+			doc = v
+		case OrderedMap:
+			if !c.Has(tok) {
+				return nil, fmt.Errorf("no such key %q", tok)
+			}
+			doc = c.Get(tok)
+		case []interface{}:
+			i, err := arrayIndex(tok, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = c[i]
+		default:
+			return nil, fmt.Errorf("can't look up %q in %s", tok, typeName(doc))
+		}
+	}
+	return doc, nil
+}
+
+// pointerUpdate - call fn with the parent of the location given by the JSON
+// Pointer tokens and the last token, replacing the parent with the result.
+// Returns the updated document.
+func pointerUpdate(doc interface{}, tokens []string, fn func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return fn(doc, tokens[0])
+	}
+	switch c := doc.(type) {
+	case map[string]interface{}:
+		child, ok := c[tokens[0]]
+		if !ok {
+			return nil, fmt.Errorf("no such key %q", tokens[0])
+		}
+		n, err := pointerUpdate(child, tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		c[tokens[0]] = n
+		return c, nil
+	case OrderedMap:
+		if !c.Has(tokens[0]) {
+			return nil, fmt.Errorf("no such key %q", tokens[0])
+		}
+		n, err := pointerUpdate(c.Get(tokens[0]), tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		return c.Set(tokens[0], n), nil
+	case []interface{}:
+		i, err := arrayIndex(tokens[0], len(c), false)
+		if err != nil {
+			return nil, err
+		}
+		n, err := pointerUpdate(c[i], tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		c[i] = n
+		return c, nil
+	}
+	return nil, fmt.Errorf("can't look up %q in %s", tokens[0], typeName(doc))
+}
+
+func patchAdd(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return pointerUpdate(doc, tokens, func(parent interface{}, key string) (interface{}, error) {
+		switch c := parent.(type) {
+		case map[string]interface{}:
+			c[key] = value
+			return c, nil
+		case OrderedMap:
+			return c.Set(key, value), nil
+		case []interface{}:
+			i, err := arrayIndex(key, len(c), true)
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = value
+			return c, nil
+		}
+		return nil, fmt.Errorf("can't add %q to %s", key, typeName(parent))
+	})
+}
+
+func patchRemove(doc interface{}, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	return pointerUpdate(doc, tokens, func(parent interface{}, key string) (interface{}, error) {
+		switch c := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := c[key]; !ok {
+				return nil, fmt.Errorf("no such key %q", key)
+			}
+			delete(c, key)
+			return c, nil
+		case OrderedMap:
+			if !c.Has(key) {
+				return nil, fmt.Errorf("no such key %q", key)
+			}
+			return c.remove(key), nil
+		case []interface{}:
+			i, err := arrayIndex(key, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(c[:i], c[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("can't remove %q from %s", key, typeName(parent))
+	})
+}
+
+func patchReplace(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if _, err := pointerGet(doc, tokens); err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return pointerUpdate(doc, tokens, func(parent interface{}, key string) (interface{}, error) {
+		switch c := parent.(type) {
+		case map[string]interface{}:
+			c[key] = value
+		case OrderedMap:
+			return c.Set(key, value), nil
+		case []interface{}:
+			i, _ := arrayIndex(key, len(c), false)
+			c[i] = value
+		}
+		return parent, nil
+	})
+}
+
+func applyPatchOp(doc interface{}, op map[string]interface{}) (interface{}, error) {
+	path, ok := op["path"].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing 'path'")
+	}
+	tokens, err := pointerTokens(path)
+	if err != nil {
+		return nil, err
+	}
+
+	value, hasValue := op["value"]
+	value = deepCopy(value)
+
+	var fromTokens []string
+	name := fmt.Sprint(op["op"])
+	switch name {
+	case "add", "replace", "test":
+		if !hasValue {
+			return nil, fmt.Errorf("missing 'value'")
+		}
+	case "move", "copy":
+		from, ok := op["from"].(string)
+		if !ok {
+			return nil, fmt.Errorf("missing 'from'")
+		}
+		if fromTokens, err = pointerTokens(from); err != nil {
+			return nil, err
+		}
+	}
+
+	switch name {
+	case "add":
+		return patchAdd(doc, tokens, value)
+	case "remove":
+		return patchRemove(doc, tokens)
+	case "replace":
+		return patchReplace(doc, tokens, value)
+	case "move":
+		if strings.HasPrefix(path+"/", op["from"].(string)+"/") && path != op["from"] {
+			return nil, fmt.Errorf("can't move a value into one of its own children")
+		}
+		v, err := pointerGet(doc, fromTokens)
+		if err != nil {
+			return nil, err
+		}
+		if doc, err = patchRemove(doc, fromTokens); err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, tokens, v)
+	case "copy":
+		v, err := pointerGet(doc, fromTokens)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, tokens, deepCopy(v))
+	case "test":
+		v, err := pointerGet(doc, tokens)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(v, value) {
+			return nil, fmt.Errorf("test failed - value is %s, not %s", shortJSON(v), shortJSON(value))
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	p, err := mapEntries(patch)
+	if err != nil {
+		return deepCopy(patch)
+	}
+	switch t := target.(type) {
+	case OrderedMap:
+		for _, item := range p {
+			if item.Value == nil {
+				t = t.remove(item.Key)
+				continue
+			}
+			t = t.Set(item.Key, mergePatch(t.Get(item.Key), item.Value))
+		}
+		return t
+	case map[string]interface{}:
+		for _, item := range p {
+			if item.Value == nil {
+				delete(t, item.Key)
+				continue
+			}
+			t[item.Key] = mergePatch(t[item.Key], item.Value)
+		}
+		return t
+	}
+	// anything other than an object is replaced by the patch (without its
+	// nulls)
+	if _, ok := patch.(OrderedMap); ok {
+		return mergePatch(OrderedMap{}, patch)
+	}
+	return mergePatch(make(map[string]interface{}), patch)
+}
+
+// patchOp - a JSON Patch operation
+func patchOp(op, path string, value interface{}, withValue bool) map[string]interface{} {
+	m := map[string]interface{}{"op": op, "path": path}
+	if withValue {
+		m["value"] = value
+	}
+	return m
+}
+
+func diff(ops []interface{}, path string, from, to interface{}) []interface{} {
+	fm, ferr := mapEntries(from)
+	tm, terr := mapEntries(to)
+	if ferr == nil && terr == nil {
+		for _, item := range fm {
+			p := path + "/" + escapePointer(item.Key)
+			if tm.Has(item.Key) {
+				ops = diff(ops, p, item.Value, tm.Get(item.Key))
+			} else {
+				ops = append(ops, patchOp("remove", p, nil, false))
+			}
+		}
+		for _, item := range tm {
+			if !fm.Has(item.Key) {
+				ops = append(ops, patchOp("add", path+"/"+escapePointer(item.Key), item.Value, true))
+			}
+		}
+		return ops
+	}
+
+	fl, fok := schemaList(from)
+	tl, tok := schemaList(to)
+	if fok && tok {
+		n := len(fl)
+		if len(tl) < n {
+			n = len(tl)
+		}
+		for i := 0; i < n; i++ {
+			ops = diff(ops, fmt.Sprintf("%s/%d", path, i), fl[i], tl[i])
+		}
+		// remove from the end, so the indexes stay valid
+		for i := len(fl) - 1; i >= n; i-- {
+			ops = append(ops, patchOp("remove", fmt.Sprintf("%s/%d", path, i), nil, false))
+		}
+		for i := n; i < len(tl); i++ {
+			ops = append(ops, patchOp("add", fmt.Sprintf("%s/%d", path, i), tl[i], true))
+		}
+		return ops
+	}
+
+	if !jsonEqual(from, to) {
+		ops = append(ops, patchOp("replace", path, to, true))
+	}
+	return ops
+}
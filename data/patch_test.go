@@ -0,0 +1,113 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatch(t *testing.T) {
+	in := JSONOrdered(`{"name": "web", "spec": {"replicas": 1, "ports": [80, 443]}, "debug": true}`)
+
+	out, err := Patch(`[
+		{"op": "replace", "path": "/spec/replicas", "value": 3},
+		{"op": "add", "path": "/spec/ports/-", "value": 8080},
+		{"op": "add", "path": "/spec/ports/0", "value": 22},
+		{"op": "remove", "path": "/debug"},
+		{"op": "add", "path": "/labels", "value": {"env": "prod"}},
+		{"op": "copy", "from": "/labels/env", "path": "/env"},
+		{"op": "move", "from": "/name", "path": "/labels/name"},
+		{"op": "test", "path": "/spec/ports/1", "value": 80}
+	]`, in)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"spec":{"replicas":3,"ports":[22,80,443,8080]},"labels":{"env":"prod","name":"web"},"env":"prod"}`, ToJSON(out))
+
+	// the original is unchanged
+	assert.Equal(t, `{"name":"web","spec":{"replicas":1,"ports":[80,443]},"debug":true}`, ToJSON(in))
+
+	// already-parsed patches, and escaped pointers
+	out, err = Patch(JSONArray(`[{"op": "add", "path": "/a~1b", "value": 1}, {"op": "replace", "path": "", "value": {"x": 2}}]`), in)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"x":2}`, ToJSON(out))
+}
+
+func TestPatchErrors(t *testing.T) {
+	in := JSON(`{"a": [1, 2], "b": {"c": 1}}`)
+	testdata := []struct {
+		patch string
+		err   string
+	}{
+		{`{"op": "add"}`, "a JSON Patch must be an array of operations, not map[string]interface {}"},
+		{`[{"op": "add", "path": "/x"}]`, "patch operation 0 (add /x): missing 'value'"},
+		{`[{"op": "remove", "path": "/x"}]`, `patch operation 0 (remove /x): no such key "x"`},
+		{`[{"op": "replace", "path": "/a/2", "value": 1}]`, "patch operation 0 (replace /a/2): array index 2 out of bounds"},
+		{`[{"op": "add", "path": "/a/01", "value": 1}]`, `patch operation 0 (add /a/01): invalid array index "01"`},
+		{`[{"op": "add", "path": "a", "value": 1}]`, `patch operation 0 (add a): invalid JSON Pointer "a" - must be empty or start with /`},
+		{`[{"op": "test", "path": "/b/c", "value": 2}]`, "patch operation 0 (test /b/c): test failed - value is 1, not 2"},
+		{`[{"op": "move", "from": "/b", "path": "/b/d"}]`, "patch operation 0 (move /b/d): can't move a value into one of its own children"},
+		{`[{"op": "copy", "path": "/x"}]`, "patch operation 0 (copy /x): missing 'from'"},
+		{`[{"op": "frob", "path": "/x"}]`, `patch operation 0 (frob /x): unknown operation "frob"`},
+		{`[{"op": "add", "path": "/b/c/d", "value": 1}]`, `patch operation 0 (add /b/c/d): can't add "d" to number`},
+	}
+	for _, d := range testdata {
+		_, err := Patch(d.patch, in)
+		assert.EqualError(t, err, d.err, d.patch)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	in := YAMLOrdered(`
+name: web
+spec:
+  replicas: 1
+  image: nginx
+tags: [a, b]
+`)
+	out, err := MergePatch(`{"spec": {"replicas": 3, "image": null}, "tags": ["c"], "labels": {"env": "prod"}}`, in)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"web","spec":{"replicas":3},"tags":["c"],"labels":{"env":"prod"}}`, ToJSON(out))
+	assert.Equal(t, `{"name":"web","spec":{"replicas":1,"image":"nginx"},"tags":["a","b"]}`, ToJSON(in))
+
+	// examples from RFC 7386 appendix A
+	testdata := []struct{ target, patch, expected string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, d := range testdata {
+		var target interface{}
+		assert.NoError(t, yamlUnmarshalOrdered([]byte(d.target), &target))
+		out, err := MergePatch(d.patch, target)
+		assert.NoError(t, err)
+		assert.Equal(t, d.expected, ToJSON(out), d.target+" + "+d.patch)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	from := JSONOrdered(`{"name": "web", "spec": {"replicas": 1, "ports": [80, 443, 22]}, "debug": true}`)
+	to := JSONOrdered(`{"name": "web", "spec": {"replicas": 3, "ports": [80]}, "labels": {"env": "prod"}}`)
+
+	patch := Diff(from, to)
+	assert.Equal(t, `[{"op":"replace","path":"/spec/replicas","value":3},{"op":"remove","path":"/spec/ports/2"},{"op":"remove","path":"/spec/ports/1"},{"op":"remove","path":"/debug"},{"op":"add","path":"/labels","value":{"env":"prod"}}]`, ToJSON(patch))
+
+	out, err := Patch(patch, from)
+	assert.NoError(t, err)
+	assert.Equal(t, ToJSON(to), ToJSON(out))
+
+	assert.Empty(t, Diff(from, from))
+	assert.Equal(t, `[{"op":"replace","path":"","value":"foo"}]`, ToJSON(Diff(from, "foo")))
+
+	// keys of plain maps are compared in alphabetical order
+	patch = Diff(JSON(`{"z": 1, "a": 1}`), JSON(`{"y": 1}`))
+	assert.Equal(t, `[{"op":"remove","path":"/a"},{"op":"remove","path":"/z"},{"op":"add","path":"/y","value":1}]`, ToJSON(patch))
+}
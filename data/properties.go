@@ -0,0 +1,235 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Properties - Unmarshal a Java .properties file, following the format
+// described in the java.util.Properties documentation: `#` and `!` comments,
+// `=`, `:` or whitespace separators, line continuations and escapes
+// (including `\uXXXX`). Keys are not split on dots, and all values are strings.
+func Properties(in string) map[string]interface{} {
+	obj, err := parseProperties(in)
+	if err != nil {
+		log.Fatalf("Unable to unmarshal properties %s: %v", in, err)
+	}
+	return obj
+}
+
+func parseProperties(in string) (map[string]interface{}, error) {
+	obj := make(map[string]interface{})
+	lines := strings.Split(strings.Replace(in, "\r\n", "\n", -1), "\n")
+	for n := 0; n < len(lines); n++ {
+		line := strings.TrimLeftFunc(lines[n], isPropertiesSpace)
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		// join continued lines - a line is continued when it ends with an
+		// odd number of backslashes
+		for continued(line) && n+1 < len(lines) {
+			n++
+			line = line[:len(line)-1] + strings.TrimLeftFunc(lines[n], isPropertiesSpace)
+		}
+		if continued(line) {
+			line = line[:len(line)-1]
+		}
+
+		key, value, err := splitProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n+1, err)
+		}
+		obj[key] = value
+	}
+	return obj, nil
+}
+
+func isPropertiesSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\f'
+}
+
+func continued(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// splitProperty - split a logical line into its unescaped key and value
+func splitProperty(line string) (key, value string, err error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == '=' || c == ':' || isPropertiesSpace(rune(c)) {
+			end = i
+			break
+		}
+	}
+
+	rest := strings.TrimLeftFunc(line[end:], isPropertiesSpace)
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeftFunc(rest[1:], isPropertiesSpace)
+	}
+
+	key, err = unescapeProperty(line[:end])
+	if err != nil {
+		return "", "", err
+	}
+	value, err = unescapeProperty(rest)
+	return key, value, err
+}
+
+func unescapeProperty(s string) (string, error) {
+	out := &bytes.Buffer{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i == len(s)-1 {
+			out.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			out.WriteByte('\t')
+		case 'n':
+			out.WriteByte('\n')
+		case 'r':
+			out.WriteByte('\r')
+		case 'f':
+			out.WriteByte('\f')
+		case 'u':
+			r, ok := unicodeEscape(s, i)
+			if !ok {
+				return "", fmt.Errorf("malformed \\uXXXX escape in %q", s)
+			}
+			i += 4
+			// characters outside the BMP are escaped as UTF-16 surrogate pairs
+			if utf16.IsSurrogate(r) && i+2 < len(s) && s[i+1] == '\\' && s[i+2] == 'u' {
+				if r2, ok := unicodeEscape(s, i+2); ok {
+					if d := utf16.DecodeRune(r, r2); d != unicode.ReplacementChar {
+						r = d
+						i += 6
+					}
+				}
+			}
+			out.WriteRune(r)
+		default:
+			out.WriteByte(s[i])
+		}
+	}
+	return out.String(), nil
+}
+
+// unicodeEscape - the character given by the \uXXXX escape at s[i] (the u)
+func unicodeEscape(s string, i int) (rune, bool) {
+	if i+4 >= len(s) {
+		return 0, false
+	}
+	r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+	return rune(r), err == nil
+}
+
+// ToProperties - Stringify an object as a Java .properties file, one
+// key=value line per key, in order for an OrderedMap and otherwise in
+// alphabetical order. Nested maps are flattened, with their keys joined by
+// dots (so `{"db":{"port":5432}}` becomes `db.port=5432`). Non-ASCII
+// characters are written as `\uXXXX` escapes, using UTF-16 surrogate pairs
+// for characters outside the BMP.
+func ToProperties(in interface{}) string {
+	m, err := mapEntries(in)
+	if err != nil {
+		log.Fatalf("Unable to marshal %v as properties: %v", in, err)
+	}
+
+	flat := flattenProperties(OrderedMap{}, "", m)
+	if _, ok := in.(OrderedMap); !ok {
+		sort.SliceStable(flat, func(i, j int) bool {
+			return flat[i].Key < flat[j].Key
+		})
+	}
+
+	buf := &bytes.Buffer{}
+	for _, item := range flat {
+		v := ""
+		if item.Value != nil {
+			v = fmt.Sprint(item.Value)
+		}
+		fmt.Fprintf(buf, "%s=%s\n", escapeProperty(item.Key, true), escapeProperty(v, false))
+	}
+	return buf.String()
+}
+
+func flattenProperties(out OrderedMap, prefix string, m OrderedMap) OrderedMap {
+	for _, item := range m {
+		k := item.Key
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		if nested, err := mapEntries(item.Value); err == nil {
+			out = flattenProperties(out, k, nested)
+			continue
+		}
+		if i := out.find(k); i >= 0 {
+			out[i].Value = item.Value
+			continue
+		}
+		out = append(out, MapItem{Key: k, Value: item.Value})
+	}
+	return out
+}
+
+func escapeProperty(s string, isKey bool) string {
+	out := &bytes.Buffer{}
+	for i, r := range s {
+		switch r {
+		case '\\':
+			out.WriteString(`\\`)
+		case '\t':
+			out.WriteString(`\t`)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\r':
+			out.WriteString(`\r`)
+		case '\f':
+			out.WriteString(`\f`)
+		case '=', ':', '#', '!':
+			out.WriteByte('\\')
+			out.WriteRune(r)
+		case ' ':
+			// spaces are significant everywhere in keys, but only leading
+			// spaces need escaping in values
+			if isKey || i == 0 {
+				out.WriteByte('\\')
+			}
+			out.WriteRune(r)
+		default:
+			if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+				writeUnicodeEscape(out, r)
+			} else {
+				out.WriteRune(r)
+			}
+		}
+	}
+	return out.String()
+}
+
+// writeUnicodeEscape - write the character as a \uXXXX escape, or a pair of
+// them (a UTF-16 surrogate pair) for characters outside the BMP
+func writeUnicodeEscape(out *bytes.Buffer, r rune) {
+	if r1, r2 := utf16.EncodeRune(r); r1 != unicode.ReplacementChar {
+		fmt.Fprintf(out, `\u%04x\u%04x`, r1, r2)
+		return
+	}
+	fmt.Fprintf(out, `\u%04x`, r)
+}
@@ -0,0 +1,70 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProperties(t *testing.T) {
+	in := `# comment
+! another comment
+db.url = jdbc:postgresql://localhost/db
+db.user:admin
+greeting Hello, \
+         World!
+key\ with\ spaces=value
+unicode=café
+tab=a\tb
+empty
+trailing=backslash\\
+`
+	expected := map[string]interface{}{
+		"db.url":          "jdbc:postgresql://localhost/db",
+		"db.user":         "admin",
+		"greeting":        "Hello, World!",
+		"key with spaces": "value",
+		"unicode":         "café",
+		"tab":             "a\tb",
+		"empty":           "",
+		"trailing":        `backslash\`,
+	}
+	assert.Equal(t, expected, Properties(in))
+
+	_, err := parseProperties(`bad=\u00z1`)
+	assert.Error(t, err)
+
+	// surrogate pairs are combined, and lone surrogates replaced
+	assert.Equal(t, map[string]interface{}{
+		"emoji": "hi 😀",
+		"lone":  "\uFFFDx",
+		"raw":   "😀",
+	}, Properties("emoji=hi \\uD83D\\uDE00\nlone=\\uD83Dx\nraw=😀"))
+}
+
+func TestToProperties(t *testing.T) {
+	in := map[string]interface{}{
+		"db": map[interface{}]interface{}{
+			"url":  "jdbc:postgresql://localhost/db",
+			"port": 5432,
+		},
+		"key with spaces": " leading space",
+		"unicode":         "café",
+		"emoji":           "😀 ok",
+	}
+	expected := `db.port=5432
+db.url=jdbc\:postgresql\://localhost/db
+emoji=\ud83d\ude00 ok
+key\ with\ spaces=\ leading space
+unicode=caf\u00e9
+`
+	out := ToProperties(in)
+	assert.Equal(t, expected, out)
+	assert.Equal(t, map[string]interface{}{
+		"db.port":         "5432",
+		"db.url":          "jdbc:postgresql://localhost/db",
+		"key with spaces": " leading space",
+		"unicode":         "café",
+		"emoji":           "😀 ok",
+	}, Properties(out))
+}
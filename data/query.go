@@ -0,0 +1,953 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query - evaluate a jq-style (or simple JSONPath) expression against a value
+// produced by one of the parsing functions (or a datasource).
+//
+// Supported syntax is a subset of jq: `.`, `.foo`, `."foo"`, `.[0]`, `.[-1]`,
+// `.[1:3]`, `.[]`, `..`, `?`, pipes (`|`), commas, parentheses, comparisons
+// (`==`, `!=`, `<`, `<=`, `>`, `>=`), `and`, `or`, literals, and the
+// functions `keys`, `length`, `type`, `first`, `last`, `not`, `has(k)`,
+// `map(f)` and `select(f)`. JSONPath expressions starting with `$` are also
+// accepted, with `[*]`, `.*`, `['foo']` and `..foo`. Arithmetic, array and
+// object construction, variables, and other functions aren't supported.
+//
+// As in jq, identifiers can't contain `-`, so keys like `foo-bar` must be
+// quoted (`."foo-bar"`).
+//
+// Expressions which can produce more than one result (such as `.items[].name`)
+// always return an array, and others return a single value.
+func Query(expr string, in interface{}) (interface{}, error) {
+	q, err := parseQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	out, err := q.f(in)
+	if err != nil {
+		return nil, fmt.Errorf("query %q failed: %v", expr, err)
+	}
+	if q.multi {
+		return out, nil
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out[0], nil
+}
+
+// filter - takes an input value and produces a stream of output values
+type filter func(interface{}) ([]interface{}, error)
+
+// query - a compiled filter, and whether it can produce multiple outputs
+type query struct {
+	f     filter
+	multi bool
+}
+
+func parseQuery(expr string) (*query, error) {
+	toks, err := lexQuery(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %q: %v", expr, err)
+	}
+	p := &queryParser{toks: toks}
+	q, err := p.parsePipe()
+	if err == nil && p.peek().kind != tokEOF {
+		err = fmt.Errorf("unexpected %s", p.peek())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %q: %v", expr, err)
+	}
+	return q, nil
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokPunct
+	tokIdent
+	tokString
+	tokNumber
+)
+
+type token struct {
+	kind tokKind
+	val  string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q at position %d", t.val, t.pos+1)
+}
+
+func lexQuery(expr string) ([]token, error) {
+	toks := []token{}
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(expr[i:], ".."):
+			toks = append(toks, token{tokPunct, "..", i})
+			i += 2
+		case strings.ContainsAny(expr[i:i+1], "=!<>") && i+1 < len(expr) && expr[i+1] == '=':
+			toks = append(toks, token{tokPunct, expr[i : i+2], i})
+			i += 2
+		case strings.ContainsRune(".[]:|,()?$*<>@", rune(c)):
+			toks = append(toks, token{tokPunct, string(c), i})
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(expr) && expr[end] != c {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, fmt.Errorf("unterminated string at position %d", i+1)
+			}
+			raw := expr[i+1 : end]
+			if c == '\'' {
+				raw = strings.Replace(strings.Replace(raw, `\'`, `'`, -1), `"`, `\"`, -1)
+			}
+			s, err := strconv.Unquote(`"` + raw + `"`)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %v", i+1, err)
+			}
+			toks = append(toks, token{tokString, s, i})
+			i = end + 1
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(expr) && (expr[end] == '.' || (expr[end] >= '0' && expr[end] <= '9')) {
+				// don't swallow a following `..` or `.foo`
+				if expr[end] == '.' && (end+1 >= len(expr) || expr[end+1] < '0' || expr[end+1] > '9') {
+					break
+				}
+				end++
+			}
+			if expr[i:end] == "-" {
+				return nil, fmt.Errorf("unexpected '-' at position %d (quote keys containing '-', as in .\"foo-bar\")", i+1)
+			}
+			toks = append(toks, token{tokNumber, expr[i:end], i})
+			i = end
+		case c == '_' || unicode.IsLetter(rune(c)):
+			end := i + 1
+			for end < len(expr) && (expr[end] == '_' || unicode.IsLetter(rune(expr[end])) || unicode.IsDigit(rune(expr[end]))) {
+				end++
+			}
+			toks = append(toks, token{tokIdent, expr[i:end], i})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", c, i+1)
+		}
+	}
+	return append(toks, token{tokEOF, "", len(expr)}), nil
+}
+
+type queryParser struct {
+	toks []token
+	pos  int
+}
+
+func (p *queryParser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *queryParser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *queryParser) accept(val string) bool {
+	t := p.peek()
+	if (t.kind == tokPunct || t.kind == tokIdent) && t.val == val {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) expect(val string) error {
+	if !p.accept(val) {
+		return fmt.Errorf("expected %q, got %s", val, p.peek())
+	}
+	return nil
+}
+
+// parsePipe - a | b | ...
+func (p *queryParser) parsePipe() (*query, error) {
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("|") {
+		right, err := p.parseComma()
+		if err != nil {
+			return nil, err
+		}
+		left = pipe(left, right)
+	}
+	return left, nil
+}
+
+func pipe(left, right *query) *query {
+	l, r := left.f, right.f
+	return &query{
+		multi: left.multi || right.multi,
+		f: func(v interface{}) ([]interface{}, error) {
+			in, err := l(v)
+			if err != nil {
+				return nil, err
+			}
+			out := []interface{}{}
+			for _, item := range in {
+				o, err := r(item)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, o...)
+			}
+			return out, nil
+		},
+	}
+}
+
+// parseComma - a, b, ...
+func (p *queryParser) parseComma() (*query, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(",") {
+		right, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		l, r := left.f, right.f
+		left = &query{multi: true, f: func(v interface{}) ([]interface{}, error) {
+			a, err := l(v)
+			if err != nil {
+				return nil, err
+			}
+			b, err := r(v)
+			if err != nil {
+				return nil, err
+			}
+			return append(a, b...), nil
+		}}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseOr() (*query, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binary(left, right, func(a, b interface{}) (interface{}, error) {
+			return truthy(a) || truthy(b), nil
+		})
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (*query, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = binary(left, right, func(a, b interface{}) (interface{}, error) {
+			return truthy(a) && truthy(b), nil
+		})
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseComparison() (*query, error) {
+	left, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if t.kind != tokPunct {
+		return left, nil
+	}
+	switch t.val {
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.next()
+	default:
+		return left, nil
+	}
+	right, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	op := t.val
+	return binary(left, right, func(a, b interface{}) (interface{}, error) {
+		return compare(op, a, b)
+	}), nil
+}
+
+// binary - apply op to every combination of the outputs of left and right
+func binary(left, right *query, op func(a, b interface{}) (interface{}, error)) *query {
+	l, r := left.f, right.f
+	return &query{
+		multi: left.multi || right.multi,
+		f: func(v interface{}) ([]interface{}, error) {
+			a, err := l(v)
+			if err != nil {
+				return nil, err
+			}
+			b, err := r(v)
+			if err != nil {
+				return nil, err
+			}
+			out := []interface{}{}
+			for _, x := range a {
+				for _, y := range b {
+					o, err := op(x, y)
+					if err != nil {
+						return nil, err
+					}
+					out = append(out, o)
+				}
+			}
+			return out, nil
+		},
+	}
+}
+
+// parsePostfix - a primary term followed by any number of path suffixes
+func (p *queryParser) parsePostfix() (*query, error) {
+	// a `?` applies only to the most recent suffix, so prefix and last are
+	// kept separate until the next suffix is parsed
+	var prefix *query
+	last, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var next *query
+		t := p.peek()
+		switch {
+		case t.kind == tokPunct && t.val == ".":
+			p.next()
+			next, err = p.parseField()
+		case t.kind == tokPunct && t.val == "..":
+			p.next()
+			next = p.parseRecurse()
+		case t.kind == tokPunct && t.val == "[":
+			next, err = p.parseIndex()
+		case t.kind == tokPunct && t.val == "?":
+			p.next()
+			last = optional(last)
+			continue
+		default:
+			if prefix == nil {
+				return last, nil
+			}
+			return pipe(prefix, last), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if prefix == nil {
+			prefix = last
+		} else {
+			prefix = pipe(prefix, last)
+		}
+		last = next
+	}
+}
+
+// parseField - the part after a `.`: an identifier, a string, `*`, or
+// nothing (when followed by `[`)
+func (p *queryParser) parseField() (*query, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokIdent || t.kind == tokString:
+		p.next()
+		return field(t.val), nil
+	case t.kind == tokPunct && t.val == "*":
+		p.next()
+		return iterate(), nil
+	case t.kind == tokPunct && t.val == "[":
+		return p.parseIndex()
+	}
+	return nil, fmt.Errorf("unexpected %s after '.'", t)
+}
+
+// parseRecurse - `..`, optionally followed directly by a field name
+// (JSONPath-style `..foo`)
+func (p *queryParser) parseRecurse() *query {
+	q := &query{multi: true, f: func(v interface{}) ([]interface{}, error) {
+		return recurse(v), nil
+	}}
+	if t := p.peek(); t.kind == tokIdent || t.kind == tokString {
+		p.next()
+		q = pipe(q, presentField(t.val))
+	}
+	return q
+}
+
+// parseIndex - `[]`, `[*]`, `[n]`, `[n:m]`, or `["key"]`
+func (p *queryParser) parseIndex() (*query, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	if p.accept("]") {
+		return iterate(), nil
+	}
+	if p.accept("*") {
+		return iterate(), p.expect("]")
+	}
+
+	t := p.peek()
+	if t.kind == tokString {
+		p.next()
+		return field(t.val), p.expect("]")
+	}
+
+	var start, end *int
+	if t.kind == tokNumber {
+		p.next()
+		n, err := strconv.Atoi(t.val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %s", t)
+		}
+		start = &n
+	}
+	if !p.accept(":") {
+		if start == nil {
+			return nil, fmt.Errorf("unexpected %s in index", p.peek())
+		}
+		return index(*start), p.expect("]")
+	}
+	if t := p.peek(); t.kind == tokNumber {
+		p.next()
+		n, err := strconv.Atoi(t.val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %s", t)
+		}
+		end = &n
+	}
+	return slice(start, end), p.expect("]")
+}
+
+func (p *queryParser) parsePrimary() (*query, error) {
+	t := p.next()
+	switch t.kind {
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	case tokString:
+		return literal(t.val), nil
+	case tokNumber:
+		n, err := strconv.ParseFloat(t.val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", t)
+		}
+		return literal(n), nil
+	case tokIdent:
+		return p.parseFunction(t)
+	}
+
+	switch t.val {
+	case ".":
+		n := p.peek()
+		if n.kind == tokIdent || n.kind == tokString || (n.kind == tokPunct && n.val == "[") {
+			return p.parseField()
+		}
+		return identity(), nil
+	case "..":
+		return p.parseRecurse(), nil
+	case "$", "@":
+		// JSONPath root and current node
+		return identity(), nil
+	case "(":
+		q, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return q, p.expect(")")
+	}
+	return nil, fmt.Errorf("unexpected %s", t)
+}
+
+func (p *queryParser) parseFunction(t token) (*query, error) {
+	switch t.val {
+	case "true":
+		return literal(true), nil
+	case "false":
+		return literal(false), nil
+	case "null":
+		return literal(nil), nil
+	case "keys", "length", "type", "first", "last", "not":
+		return builtin(t.val), nil
+	case "map", "select", "has":
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		arg, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return builtinWithArg(t.val, arg), nil
+	}
+	return nil, fmt.Errorf("unknown function %s", t)
+}
+
+func identity() *query {
+	return &query{f: func(v interface{}) ([]interface{}, error) {
+		return []interface{}{v}, nil
+	}}
+}
+
+func literal(l interface{}) *query {
+	return &query{f: func(interface{}) ([]interface{}, error) {
+		return []interface{}{l}, nil
+	}}
+}
+
+// optional - suppress errors, producing no output instead
+func optional(q *query) *query {
+	f := q.f
+	return &query{multi: q.multi, f: func(v interface{}) ([]interface{}, error) {
+		out, err := f(v)
+		if err != nil {
+			return []interface{}{}, nil
+		}
+		return out, nil
+	}}
+}
+
+func field(name string) *query {
+	return &query{f: func(v interface{}) ([]interface{}, error) {
+		if v == nil {
+			return []interface{}{nil}, nil
+		}
+		if m, ok := v.(OrderedMap); ok {
+			return []interface{}{m.Get(name)}, nil
+		}
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Map {
+			return nil, fmt.Errorf("can't get key %q of %s", name, typeName(v))
+		}
+		return []interface{}{mapValue(rv, name)}, nil
+	}}
+}
+
+// presentField - like field, but producing no output for values which aren't
+// maps, or which don't contain the key (as in JSONPath)
+func presentField(name string) *query {
+	return &query{multi: true, f: func(v interface{}) ([]interface{}, error) {
+		if m, ok := v.(OrderedMap); ok {
+			if !m.Has(name) {
+				return []interface{}{}, nil
+			}
+			return []interface{}{m.Get(name)}, nil
+		}
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Map {
+			return []interface{}{}, nil
+		}
+		for _, k := range rv.MapKeys() {
+			if fmt.Sprint(k.Interface()) == name {
+				return []interface{}{rv.MapIndex(k).Interface()}, nil
+			}
+		}
+		return []interface{}{}, nil
+	}}
+}
+
+// mapValue - the value at the given key in the map, matching non-string keys
+// by their string representation (YAML can produce these)
+func mapValue(m reflect.Value, key string) interface{} {
+	kv := reflect.ValueOf(key)
+	if kv.Type().AssignableTo(m.Type().Key()) {
+		if v := m.MapIndex(kv); v.IsValid() {
+			return v.Interface()
+		}
+	}
+	for _, k := range m.MapKeys() {
+		if fmt.Sprint(k.Interface()) == key {
+			return m.MapIndex(k).Interface()
+		}
+	}
+	return nil
+}
+
+func index(i int) *query {
+	return &query{f: func(v interface{}) ([]interface{}, error) {
+		if v == nil {
+			return []interface{}{nil}, nil
+		}
+		rv := reflect.ValueOf(v)
+		if _, ok := v.(OrderedMap); ok || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+			return nil, fmt.Errorf("can't index %s with a number", typeName(v))
+		}
+		if i < 0 {
+			i += rv.Len()
+		}
+		if i < 0 || i >= rv.Len() {
+			return []interface{}{nil}, nil
+		}
+		return []interface{}{rv.Index(i).Interface()}, nil
+	}}
+}
+
+func slice(start, end *int) *query {
+	return &query{f: func(v interface{}) ([]interface{}, error) {
+		if v == nil {
+			return []interface{}{nil}, nil
+		}
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.String {
+			r := []rune(rv.String())
+			s, e := sliceBounds(start, end, len(r))
+			return []interface{}{string(r[s:e])}, nil
+		}
+		if _, ok := v.(OrderedMap); ok || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+			return nil, fmt.Errorf("can't slice %s", typeName(v))
+		}
+		s, e := sliceBounds(start, end, rv.Len())
+		out := make([]interface{}, e-s)
+		for i := range out {
+			out[i] = rv.Index(s + i).Interface()
+		}
+		return []interface{}{out}, nil
+	}}
+}
+
+func sliceBounds(start, end *int, n int) (int, int) {
+	clamp := func(i int) int {
+		if i < 0 {
+			i += n
+		}
+		if i < 0 {
+			return 0
+		}
+		if i > n {
+			return n
+		}
+		return i
+	}
+	s, e := 0, n
+	if start != nil {
+		s = clamp(*start)
+	}
+	if end != nil {
+		e = clamp(*end)
+	}
+	if e < s {
+		e = s
+	}
+	return s, e
+}
+
+// iterate - `.[]` - the elements of an array or the values of an object
+func iterate() *query {
+	return &query{multi: true, f: func(v interface{}) ([]interface{}, error) {
+		if m, ok := v.(OrderedMap); ok {
+			return m.Values(), nil
+		}
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			out := make([]interface{}, rv.Len())
+			for i := range out {
+				out[i] = rv.Index(i).Interface()
+			}
+			return out, nil
+		case reflect.Map:
+			out := []interface{}{}
+			for _, k := range sortedMapKeys(rv) {
+				out = append(out, rv.MapIndex(k).Interface())
+			}
+			return out, nil
+		}
+		return nil, fmt.Errorf("can't iterate over %s", typeName(v))
+	}}
+}
+
+// sortedMapKeys - the map's keys, sorted by their string representations
+func sortedMapKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
+
+// recurse - `..` - the value and all values nested within it
+func recurse(v interface{}) []interface{} {
+	out := []interface{}{v}
+	if m, ok := v.(OrderedMap); ok {
+		for _, item := range m {
+			out = append(out, recurse(item.Value)...)
+		}
+		return out
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			out = append(out, recurse(rv.Index(i).Interface())...)
+		}
+	case reflect.Map:
+		for _, k := range sortedMapKeys(rv) {
+			out = append(out, recurse(rv.MapIndex(k).Interface())...)
+		}
+	}
+	return out
+}
+
+func builtin(name string) *query {
+	return &query{f: func(v interface{}) ([]interface{}, error) {
+		if m, ok := v.(OrderedMap); ok {
+			// look up keys in a plain map, as jq sorts them anyway
+			v = m.Map()
+		}
+		rv := reflect.ValueOf(v)
+		switch name {
+		case "not":
+			return []interface{}{!truthy(v)}, nil
+		case "type":
+			return []interface{}{typeName(v)}, nil
+		case "length":
+			if v == nil {
+				return []interface{}{0}, nil
+			}
+			switch rv.Kind() {
+			case reflect.String:
+				return []interface{}{len([]rune(rv.String()))}, nil
+			case reflect.Slice, reflect.Array, reflect.Map:
+				return []interface{}{rv.Len()}, nil
+			}
+			if f, ok := toFloat(v); ok {
+				return []interface{}{math.Abs(f)}, nil
+			}
+		case "keys":
+			switch rv.Kind() {
+			case reflect.Map:
+				keys := []interface{}{}
+				for _, k := range rv.MapKeys() {
+					keys = append(keys, fmt.Sprint(k.Interface()))
+				}
+				sort.Slice(keys, func(i, j int) bool { return keys[i].(string) < keys[j].(string) })
+				return []interface{}{keys}, nil
+			case reflect.Slice, reflect.Array:
+				keys := make([]interface{}, rv.Len())
+				for i := range keys {
+					keys[i] = i
+				}
+				return []interface{}{keys}, nil
+			}
+		case "first", "last":
+			if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+				if rv.Len() == 0 {
+					return []interface{}{nil}, nil
+				}
+				if name == "first" {
+					return []interface{}{rv.Index(0).Interface()}, nil
+				}
+				return []interface{}{rv.Index(rv.Len() - 1).Interface()}, nil
+			}
+		}
+		return nil, fmt.Errorf("%s (%s) has no %s", typeName(v), shortValue(v), name)
+	}}
+}
+
+func builtinWithArg(name string, arg *query) *query {
+	f := arg.f
+	return &query{f: func(v interface{}) ([]interface{}, error) {
+		switch name {
+		case "select":
+			conds, err := f(v)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range conds {
+				if truthy(c) {
+					return []interface{}{v}, nil
+				}
+			}
+			return []interface{}{}, nil
+		case "map":
+			items, err := iterate().f(v)
+			if err != nil {
+				return nil, err
+			}
+			out := []interface{}{}
+			for _, item := range items {
+				o, err := f(item)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, o...)
+			}
+			return []interface{}{out}, nil
+		case "has":
+			keys, err := f(v)
+			if err != nil {
+				return nil, err
+			}
+			out := make([]interface{}, len(keys))
+			if m, ok := v.(OrderedMap); ok {
+				v = m.Map()
+			}
+			rv := reflect.ValueOf(v)
+			for i, k := range keys {
+				switch rv.Kind() {
+				case reflect.Map:
+					found := false
+					for _, mk := range rv.MapKeys() {
+						if fmt.Sprint(mk.Interface()) == fmt.Sprint(k) {
+							found = true
+						}
+					}
+					out[i] = found
+				case reflect.Slice, reflect.Array:
+					n, ok := toFloat(k)
+					out[i] = ok && n >= 0 && int(n) < rv.Len()
+				default:
+					return nil, fmt.Errorf("can't check whether %s has a key", typeName(v))
+				}
+			}
+			return out, nil
+		}
+		return nil, fmt.Errorf("unknown function %s", name)
+	}}
+}
+
+func truthy(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	return true
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func compare(op string, a, b interface{}) (interface{}, error) {
+	af, aNum := toFloat(a)
+	bf, bNum := toFloat(b)
+	as, aStr := a.(string)
+	bs, bStr := b.(string)
+
+	switch op {
+	case "==":
+		if aNum && bNum {
+			return af == bf, nil
+		}
+		return reflect.DeepEqual(a, b), nil
+	case "!=":
+		if aNum && bNum {
+			return af != bf, nil
+		}
+		return !reflect.DeepEqual(a, b), nil
+	}
+
+	var c int
+	switch {
+	case aNum && bNum:
+		c = cmpFloat(af, bf)
+	case aStr && bStr:
+		c = strings.Compare(as, bs)
+	default:
+		return nil, fmt.Errorf("can't compare %s and %s with %s", typeName(a), typeName(b), op)
+	}
+	switch op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	}
+	return c >= 0, nil
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// typeName - the jq name of the value's type
+func typeName(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	if _, ok := toFloat(v); ok {
+		return "number"
+	}
+	if _, ok := v.(OrderedMap); ok {
+		return "object"
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func shortValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if len(s) > 20 {
+		s = s[:17] + "..."
+	}
+	return s
+}
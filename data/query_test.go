@@ -0,0 +1,178 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	in := JSON(`{
+		"name": "test",
+		"items": [
+			{"name": "a", "on": true, "size": 3},
+			{"name": "b", "on": false, "size": 10},
+			{"name": "c", "on": true, "size": 7, "tags": ["x", "y"]}
+		],
+		"store": {"book": [{"author": "Lee"}, {"author": "Sam", "price": 8}]},
+		"with space": 1
+	}`)
+
+	testdata := []struct {
+		expr     string
+		expected interface{}
+	}{
+		{`.name`, "test"},
+		{`.missing`, nil},
+		{`.missing.deeper`, nil},
+		{`."with space"`, 1},
+		{`.["with space"]`, 1},
+		{`.items[0].name`, "a"},
+		{`.items[-1].name`, "c"},
+		{`.items[5]`, nil},
+		{`.items[].name`, []interface{}{"a", "b", "c"}},
+		{`.items | length`, 3},
+		{`.items[0] | keys`, []interface{}{"name", "on", "size"}},
+		{`.items[] | select(.on) | .name`, []interface{}{"a", "c"}},
+		{`.items[] | select(.size > 5 and .on) | .name`, []interface{}{"c"}},
+		{`.items[] | select(.name == "b" or .size < 4) | .name`, []interface{}{"a", "b"}},
+		{`.items | map(.size)`, []interface{}{3, 10, 7}},
+		{`.items[].tags[]?`, []interface{}{"x", "y"}},
+		{`.items | first | .name`, "a"},
+		{`.items | last | .name`, "c"},
+		{`.items[2] | has("tags")`, true},
+		{`.name, .items[0].name`, []interface{}{"test", "a"}},
+		{`.name | type`, "string"},
+		{`.store | type`, "object"},
+		{`.items[0].on | not`, false},
+		{`$.store.book[*].author`, []interface{}{"Lee", "Sam"}},
+		{`$['store']['book'][0].author`, "Lee"},
+		{`$..author`, []interface{}{"Lee", "Sam"}},
+		{`$..price`, []interface{}{8}},
+	}
+	for _, d := range testdata {
+		actual, err := Query(d.expr, in)
+		assert.NoError(t, err, d.expr)
+		assert.Equal(t, d.expected, actual, d.expr)
+	}
+
+	actual, err := Query(`.items[1:] | map(.name)`, in)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"b", "c"}, actual)
+
+	actual, err = Query(`.[1:3]`, "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "el", actual)
+
+	actual, err = Query(`.`, in)
+	assert.NoError(t, err)
+	assert.Equal(t, in, actual)
+}
+
+func TestQueryYAML(t *testing.T) {
+	in := YAML("a:\n  1: one\n  b: [p, q]\n")
+	actual, err := Query(`.a."1"`, in)
+	assert.NoError(t, err)
+	assert.Equal(t, "one", actual)
+
+	actual, err = Query(`.a.b[1]`, in)
+	assert.NoError(t, err)
+	assert.Equal(t, "q", actual)
+
+	// iteration follows the key order of OrderedMaps, and is sorted for
+	// plain maps
+	actual, err = Query(`.z[]`, YAMLOrdered("z:\n  q: 1\n  a: 2\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{1, 2}, actual)
+	actual, err = Query(`.z[]`, YAML("z:\n  q: 1\n  a: 2\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{2, 1}, actual)
+
+	ordered := YAMLOrdered("z:\n  q: [1]\n  a: 2\n")
+	actual, err = Query(`.z.q[0]`, ordered)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, actual)
+	actual, err = Query(`.z | keys`, ordered)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "q"}, actual)
+	actual, err = Query(`.z | type`, ordered)
+	assert.NoError(t, err)
+	assert.Equal(t, "object", actual)
+	_, err = Query(`.z[0]`, ordered)
+	assert.Error(t, err)
+}
+
+func TestQueryErrors(t *testing.T) {
+	in := JSON(`{"a": [1, 2], "s": "str"}`)
+	badExprs := []string{
+		``,
+		`.a[`,
+		`.a[x]`,
+		`.a | nope`,
+		`"unterminated`,
+		`.a )`,
+		`.a = 1`,
+		`select(.a`,
+		`.a + .b`,
+		`[.a]`,
+		`.foo-bar`,
+	}
+	for _, expr := range badExprs {
+		_, err := Query(expr, in)
+		assert.Error(t, err, expr)
+	}
+
+	_, err := Query(`.a.b`, in)
+	assert.EqualError(t, err, `query ".a.b" failed: can't get key "b" of array`)
+
+	_, err = Query(`.foo-bar`, in)
+	assert.EqualError(t, err, `invalid query ".foo-bar": unexpected '-' at position 5 (quote keys containing '-', as in ."foo-bar")`)
+
+	actual, err := Query(`."foo-bar"`, JSON(`{"foo-bar": 1}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, actual)
+
+	_, err = Query(`.s[0]`, in)
+	assert.Error(t, err)
+
+	_, err = Query(`.s[]`, in)
+	assert.Error(t, err)
+
+	_, err = Query(`.a < .s`, in)
+	assert.Error(t, err)
+
+	actual, err = Query(`.a.b?`, in)
+	assert.NoError(t, err)
+	assert.Nil(t, actual)
+}
+
+func TestExtractQuery(t *testing.T) {
+	args, jq, err := extractQuery([]string{"?jq=.items[].name"})
+	assert.NoError(t, err)
+	assert.Equal(t, ".items[].name", jq)
+	assert.Empty(t, args)
+
+	args, jq, err = extractQuery([]string{"sub/path.json?jq=.a&glob=x"})
+	assert.NoError(t, err)
+	assert.Equal(t, ".a", jq)
+	assert.Equal(t, []string{"sub/path.json?glob=x"}, args)
+
+	args, jq, err = extractQuery([]string{"foo?param=bar"})
+	assert.NoError(t, err)
+	assert.Equal(t, "", jq)
+	assert.Equal(t, []string{"foo?param=bar"}, args)
+
+	// "+" is kept, rather than decoded as a space
+	args, jq, err = extractQuery([]string{`?jq=."a+b"`})
+	assert.NoError(t, err)
+	assert.Equal(t, `."a+b"`, jq)
+	assert.Empty(t, args)
+
+	args, jq, err = extractQuery([]string{`x.json?glob=a+b&jq=."a%20+%20b"&other=c%26d`})
+	assert.NoError(t, err)
+	assert.Equal(t, `."a + b"`, jq)
+	assert.Equal(t, []string{"x.json?glob=a+b&other=c%26d"}, args)
+
+	_, _, err = extractQuery([]string{"?jq=%zz"})
+	assert.Error(t, err)
+}
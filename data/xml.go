@@ -0,0 +1,252 @@
+package data
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+// XML documents are mapped to and from nested maps with these conventions:
+//
+//  - the document is a map with a single key, the name of the root element
+//  - an element containing only text (and no attributes) is a string
+//  - other elements are maps, with child elements keyed by name
+//  - attributes (including xmlns declarations) are keyed by their name,
+//    prefixed with "-"
+//  - names keep their namespace prefix as written, as in "xsi:schemaLocation"
+//  - text content of an element with attributes or children is keyed "#text"
+//  - repeated child elements with the same name are collected into an array
+//  - an empty element with no attributes is an empty string
+//
+// XMLOrdered (and XML datasources with the `ordered` query parameter set)
+// produces OrderedMaps instead of maps, keeping the document order of
+// attributes and elements, so documents can be written back in the same
+// order.
+const (
+	xmlAttrPrefix = "-"
+	xmlTextKey    = "#text"
+)
+
+// XML - Unmarshal an XML document
+func XML(in string) map[string]interface{} {
+	obj, err := parseXML(in, false)
+	if err != nil {
+		log.Fatalf("Unable to unmarshal XML %s: %v", in, err)
+	}
+	return obj.(map[string]interface{})
+}
+
+// XMLOrdered - Unmarshal an XML document into an OrderedMap
+func XMLOrdered(in string) OrderedMap {
+	obj, err := parseXML(in, true)
+	if err != nil {
+		log.Fatalf("Unable to unmarshal XML %s: %v", in, err)
+	}
+	return obj.(OrderedMap)
+}
+
+// parseXML - parse the document into a map, or an OrderedMap when ordered is
+// true (as are all of the maps inside it)
+func parseXML(in string, ordered bool) (interface{}, error) {
+	dec := xml.NewDecoder(strings.NewReader(in))
+	for {
+		// raw tokens keep namespace prefixes (and xmlns attributes) as
+		// written, rather than resolving them to namespace URLs
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			return nil, fmt.Errorf("no root element found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			v, err := parseXMLElement(dec, start, ordered)
+			if err != nil {
+				return nil, err
+			}
+			return xmlMap(map[string]interface{}{xmlName(start.Name): v}, []string{xmlName(start.Name)}, ordered), nil
+		}
+	}
+}
+
+// parseXMLElement - decode the element started by start (up to and including
+// its end tag) into a string or a map
+func parseXMLElement(dec *xml.Decoder, start xml.StartElement, ordered bool) (interface{}, error) {
+	m := make(map[string]interface{})
+	keys := []string{}
+	for _, attr := range start.Attr {
+		k := xmlAttrPrefix + xmlName(attr.Name)
+		if _, ok := m[k]; !ok {
+			keys = append(keys, k)
+		}
+		m[k] = attr.Value
+	}
+
+	text := &bytes.Buffer{}
+	for {
+		tok, err := dec.RawToken()
+		if err != nil {
+			if err == io.EOF {
+				err = fmt.Errorf("element <%s> not closed", xmlName(start.Name))
+			}
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			child, err := parseXMLElement(dec, t, ordered)
+			if err != nil {
+				return nil, err
+			}
+			keys = addXMLChild(m, keys, xmlName(t.Name), child)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if t.Name != start.Name {
+				return nil, fmt.Errorf("element <%s> closed by </%s>", xmlName(start.Name), xmlName(t.Name))
+			}
+			s := strings.TrimSpace(text.String())
+			if len(m) == 0 {
+				return s, nil
+			}
+			if s != "" {
+				m[xmlTextKey] = s
+				keys = append(keys, xmlTextKey)
+			}
+			return xmlMap(m, keys, ordered), nil
+		}
+	}
+}
+
+func addXMLChild(m map[string]interface{}, keys []string, name string, child interface{}) []string {
+	existing, ok := m[name]
+	if !ok {
+		m[name] = child
+		return append(keys, name)
+	}
+	if list, ok := existing.([]interface{}); ok {
+		m[name] = append(list, child)
+		return keys
+	}
+	m[name] = []interface{}{existing, child}
+	return keys
+}
+
+// xmlMap - the map, or an OrderedMap with the given keys when ordered is true
+func xmlMap(m map[string]interface{}, keys []string, ordered bool) interface{} {
+	if !ordered {
+		return m
+	}
+	out := make(OrderedMap, len(keys))
+	for i, k := range keys {
+		out[i] = MapItem{Key: k, Value: m[k]}
+	}
+	return out
+}
+
+// xmlName - the name as written in the document, with its namespace prefix
+// (if any)
+func xmlName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+// ToXML - Stringify a map as an XML document (with an XML declaration), using
+// the same conventions as XML. An optional indent string can be given before
+// the input.
+func ToXML(args ...interface{}) string {
+	indent := ""
+	var in interface{}
+	switch len(args) {
+	case 1:
+		in = args[0]
+	case 2:
+		var ok bool
+		indent, ok = args[0].(string)
+		if !ok {
+			log.Fatalf("Can't parse ToXML indent (%v) - must be string (is a %T)", args[0], args[0])
+		}
+		in = args[1]
+	default:
+		log.Fatalf("ToXML requires 1 or 2 arguments, got %d", len(args))
+	}
+
+	b := bytes.NewBufferString(xml.Header)
+	enc := xml.NewEncoder(b)
+	enc.Indent("", indent)
+	if err := encodeXMLDoc(enc, in); err != nil {
+		log.Fatalf("Unable to marshal %v as XML: %v", in, err)
+	}
+	if err := enc.Flush(); err != nil {
+		log.Fatalf("Unable to marshal %v as XML: %v", in, err)
+	}
+	return b.String()
+}
+
+func encodeXMLDoc(enc *xml.Encoder, in interface{}) error {
+	m, err := mapEntries(in)
+	if err != nil {
+		return err
+	}
+	if len(m) != 1 {
+		return fmt.Errorf("XML documents must have exactly one root element, got %d", len(m))
+	}
+	return encodeXMLElement(enc, m[0].Key, m[0].Value)
+}
+
+func encodeXMLElement(enc *xml.Encoder, name string, v interface{}) error {
+	if list, ok := v.([]interface{}); ok {
+		for _, item := range list {
+			if err := encodeXMLElement(enc, name, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	m, err := mapEntries(v)
+	if err != nil {
+		// not a map - must be a scalar
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		if v != nil {
+			if err := enc.EncodeToken(xml.CharData(fmt.Sprint(v))); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	}
+
+	children := OrderedMap{}
+	for _, item := range m {
+		if strings.HasPrefix(item.Key, xmlAttrPrefix) {
+			start.Attr = append(start.Attr, xml.Attr{
+				Name:  xml.Name{Local: strings.TrimPrefix(item.Key, xmlAttrPrefix)},
+				Value: fmt.Sprint(item.Value),
+			})
+		} else if item.Key != xmlTextKey {
+			children = append(children, item)
+		}
+	}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if m.Has(xmlTextKey) {
+		if err := enc.EncodeToken(xml.CharData(fmt.Sprint(m.Get(xmlTextKey)))); err != nil {
+			return err
+		}
+	}
+	for _, item := range children {
+		if err := encodeXMLElement(enc, item.Key, item.Value); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
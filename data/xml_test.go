@@ -0,0 +1,101 @@
+package data
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXML(t *testing.T) {
+	in := `<?xml version="1.0" encoding="UTF-8"?>
+<settings xmlns="http://maven.apache.org/SETTINGS/1.0.0">
+  <localRepository>/opt/m2</localRepository>
+  <offline/>
+  <servers>
+    <server id="central">
+      <username>deploy</username>
+    </server>
+    <server id="snapshots">
+      <username>snap</username>
+    </server>
+  </servers>
+  <proxy active="true">proxy.example.com</proxy>
+</settings>`
+	expected := map[string]interface{}{
+		"settings": map[string]interface{}{
+			"-xmlns":          "http://maven.apache.org/SETTINGS/1.0.0",
+			"localRepository": "/opt/m2",
+			"offline":         "",
+			"servers": map[string]interface{}{
+				"server": []interface{}{
+					map[string]interface{}{"-id": "central", "username": "deploy"},
+					map[string]interface{}{"-id": "snapshots", "username": "snap"},
+				},
+			},
+			"proxy": map[string]interface{}{"-active": "true", "#text": "proxy.example.com"},
+		},
+	}
+	assert.Equal(t, expected, XML(in))
+
+	_, err := parseXML("", false)
+	assert.Error(t, err)
+	_, err = parseXML("<foo>", false)
+	assert.EqualError(t, err, "element <foo> not closed")
+	_, err = parseXML("<foo></bar>", false)
+	assert.EqualError(t, err, "element <foo> closed by </bar>")
+}
+
+func TestXMLNamespaces(t *testing.T) {
+	in := `<project xmlns="http://maven.apache.org/POM/4.0.0" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://maven.apache.org/POM/4.0.0 http://maven.apache.org/xsd/maven-4.0.0.xsd"><m:name xmlns:m="urn:m">foo</m:name></project>`
+	out := XML(in)
+	project := out["project"].(map[string]interface{})
+	assert.Equal(t, "http://maven.apache.org/POM/4.0.0", project["-xmlns"])
+	assert.Equal(t, "http://www.w3.org/2001/XMLSchema-instance", project["-xmlns:xsi"])
+	assert.Equal(t, "http://maven.apache.org/POM/4.0.0 http://maven.apache.org/xsd/maven-4.0.0.xsd", project["-xsi:schemaLocation"])
+	assert.Equal(t, map[string]interface{}{"-xmlns:m": "urn:m", "#text": "foo"}, project["m:name"])
+
+	assert.Equal(t, xml.Header+in, ToXML(out))
+}
+
+func TestXMLOrder(t *testing.T) {
+	doc := `<a z="1" b="2"><z>1</z><b>2</b><z>3</z><m>4</m></a>`
+	out := XMLOrdered(doc)
+	assert.Equal(t, []string{"-z", "-b", "z", "b", "m"}, out.Get("a").(OrderedMap).Keys())
+	assert.Equal(t, xml.Header+`<a z="1" b="2"><z>1</z><z>3</z><b>2</b><m>4</m></a>`, ToXML(out))
+
+	// without the order, attributes and elements are sorted
+	assert.Equal(t, xml.Header+`<a b="2" z="1"><b>2</b><m>4</m><z>1</z><z>3</z></a>`, ToXML(XML(doc)))
+}
+
+func TestToXML(t *testing.T) {
+	in := map[string]interface{}{
+		"Server": map[string]interface{}{
+			"-port": 8005,
+			"Service": map[interface{}]interface{}{
+				"-name": "Catalina",
+				"Connector": []interface{}{
+					map[string]interface{}{"-port": "8080"},
+					map[string]interface{}{"-port": "8443"},
+				},
+				"Engine": "Catalina & co",
+			},
+		},
+	}
+	assert.Equal(t, xml.Header+`<Server port="8005"><Service name="Catalina"><Connector port="8080"></Connector><Connector port="8443"></Connector><Engine>Catalina &amp; co</Engine></Service></Server>`, ToXML(in))
+
+	expected := xml.Header + `<a x="1">
+  <b>foo</b>
+</a>`
+	assert.Equal(t, expected, ToXML("  ", map[string]interface{}{
+		"a": map[string]interface{}{"-x": "1", "b": "foo"},
+	}))
+
+	assert.Equal(t, xml.Header+`<a x="1">text</a>`, ToXML(map[string]interface{}{
+		"a": map[string]interface{}{"-x": "1", "#text": "text"},
+	}))
+
+	// round-trip
+	doc := `<a x="1"><b>foo</b><b>bar</b><c>baz</c></a>`
+	assert.Equal(t, xml.Header+doc, ToXML(XML(doc)))
+}
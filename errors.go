@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/hairyhenderson/gomplate/data"
+)
+
+// TemplateError - an error parsing or executing a template, with enough
+// context to find the problem: the offending source line, the `template`
+// invocations (and tmpl.Exec calls) leading to it, and the datasource
+// involved, if any
+type TemplateError struct {
+	Template   string              `json:"template"`
+	Line       int                 `json:"line"`
+	Column     int                 `json:"column,omitempty"`
+	Message    string              `json:"message"`
+	Source     string              `json:"source,omitempty"`
+	Calls      []templateCall      `json:"calls,omitempty"`
+	Datasource *datasourceLocation `json:"datasource,omitempty"`
+
+	// the original error
+	err error
+	// whether it's an execution error, rather than a parse error
+	exec bool
+}
+
+// templateCall - an invocation of a named template
+type templateCall struct {
+	Template string `json:"template"`
+	Location string `json:"location"`
+}
+
+type datasourceLocation struct {
+	Alias string   `json:"alias"`
+	Args  []string `json:"args,omitempty"`
+}
+
+// execErrorRE - matches the location prefix of text/template execution
+// errors, as in `template: foo.tmpl:3:12: executing "foo" at <ds "bar">: ...`
+var execErrorRE = regexp.MustCompile(`(?s)^template: (.*?):(\d+):(\d+): executing "(.*?)"(?: at <.*?>)?: (.*)$`)
+
+// parseErrorRE - matches the location prefix of parse errors, which don't
+// include the column, as in `template: foo.tmpl:3: unexpected {{end}}`
+var parseErrorRE = regexp.MustCompile(`(?s)^template: (.*?):(\d+): (.*)$`)
+
+func (e *TemplateError) Error() string {
+	sb := &strings.Builder{}
+	if e.exec {
+		fmt.Fprintf(sb, "template: %s:%d:%d: %s", e.Template, e.Line, e.Column, e.Message)
+	} else {
+		sb.WriteString(e.err.Error())
+	}
+	if e.Source != "" {
+		num := strconv.Itoa(e.Line)
+		fmt.Fprintf(sb, "\n  %s | %s", num, e.Source)
+		if e.Column > 0 {
+			fmt.Fprintf(sb, "\n  %s | %s^", strings.Repeat(" ", len(num)), caretIndent(e.Source, e.Column))
+		}
+	}
+	for _, c := range e.Calls {
+		fmt.Fprintf(sb, "\n  in template %q, called from %s", c.Template, c.Location)
+	}
+	if ds := e.Datasource; ds != nil {
+		fmt.Fprintf(sb, "\n  reading datasource '%s'", ds.Alias)
+		if len(ds.Args) > 0 {
+			fmt.Fprintf(sb, " with args %q", ds.Args)
+		}
+	}
+	return sb.String()
+}
+
+// Unwrap - the original error
+func (e *TemplateError) Unwrap() error {
+	return e.err
+}
+
+// JSON - the error as a JSON object, for editors and other tools
+func (e *TemplateError) JSON() string {
+	return toJSON(e)
+}
+
+// toJSON - marshal the value, without escaping characters like < and >, which
+// are common in template names and messages
+func toJSON(v interface{}) string {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprintf(`{"message": %q}`, err.Error())
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// errorJSON - an error formatted as a JSON object, to be printed on its own
+// to stderr
+type errorJSON string
+
+func (e errorJSON) Error() string {
+	return string(e)
+}
+
+// jsonError - the error, as a JSON object. Errors which aren't template
+// errors only have a message.
+func jsonError(err error) error {
+	for e := err; e != nil; e = unwrap(e) {
+		if te, ok := e.(*TemplateError); ok {
+			return errorJSON(te.JSON())
+		}
+	}
+	return errorJSON(toJSON(map[string]string{"message": err.Error()}))
+}
+
+// unwrap - the error wrapped by err, or nil. This follows Unwrap methods and
+// the Err field of text/template's ExecError, which also works with versions
+// of Go without errors.Unwrap.
+func unwrap(err error) error {
+	switch e := err.(type) {
+	case template.ExecError:
+		return e.Err
+	case interface{ Unwrap() error }:
+		return e.Unwrap()
+	}
+	return nil
+}
+
+// caretIndent - whitespace to line a caret up under the given (1-based,
+// byte) column of the line, keeping tabs so it lines up however they're shown
+func caretIndent(line string, col int) string {
+	if col-1 > len(line) {
+		col = len(line) + 1
+	}
+	indent := []rune{}
+	for _, r := range line[:col-1] {
+		if r == '\t' {
+			indent = append(indent, '\t')
+		} else {
+			indent = append(indent, ' ')
+		}
+	}
+	return string(indent)
+}
+
+// newParseError - wrap an error from parsing the template, adding the column
+// and source line when they can be found
+func newParseError(t *tplate, leftDelim, rightDelim, text string, err error) error {
+	m := parseErrorRE.FindStringSubmatch(err.Error())
+	if m == nil || m[1] != t.name {
+		return err
+	}
+	line, col, msg := syntaxErrorPosition(t.name, text, leftDelim, rightDelim, err)
+	e := &TemplateError{
+		Template: t.name,
+		Line:     line,
+		Column:   t.column(line, col, rightDelim),
+		Message:  msg,
+		err:      err,
+	}
+	e.Source, _ = t.sourceLine(line)
+	return e
+}
+
+// newExecError - wrap an error from executing the template. Errors from
+// templates run with tmpl.Exec or tmpl.Inline are nested inside the error
+// from the calling template, so the innermost is the one reported, and the
+// others are part of the chain of calls.
+func newExecError(t *tplate, tmpl *template.Template, rightDelim string, err error) error {
+	type level struct {
+		// as given by text/template, with a 0-based column
+		loc  string
+		file string
+		line int
+		col  int
+		name string
+		msg  string
+	}
+	levels := []level{}
+	for e := err; e != nil; e = unwrap(e) {
+		ee, ok := e.(template.ExecError)
+		if !ok {
+			continue
+		}
+		m := execErrorRE.FindStringSubmatch(ee.Error())
+		if m == nil {
+			continue
+		}
+		line, _ := strconv.Atoi(m[2])
+		col, _ := strconv.Atoi(m[3])
+		levels = append(levels, level{
+			loc:  fmt.Sprintf("%s:%s:%s", m[1], m[2], m[3]),
+			file: m[1],
+			line: line,
+			col:  col + 1,
+			name: m[4],
+			msg:  m[5],
+		})
+	}
+	if len(levels) == 0 {
+		return err
+	}
+
+	inner := levels[len(levels)-1]
+	e := &TemplateError{
+		Template: inner.file,
+		Line:     inner.line,
+		Column:   inner.col,
+		Message:  inner.msg,
+		err:      err,
+		exec:     true,
+	}
+	if inner.file == t.name {
+		e.Column = t.column(inner.line, inner.col, rightDelim)
+		e.Source, _ = t.sourceLine(inner.line)
+	}
+
+	for i := len(levels) - 1; i >= 0; i-- {
+		calls, top := templateCallers(t, tmpl, rightDelim, levels[i].name)
+		e.Calls = append(e.Calls, calls...)
+		if i > 0 {
+			// the call to tmpl.Exec or tmpl.Inline in the enclosing template
+			e.Calls = append(e.Calls, templateCall{
+				Template: top,
+				Location: t.location(levels[i-1].loc, rightDelim),
+			})
+		}
+	}
+
+	for c := err; c != nil; c = unwrap(c) {
+		if dsErr, ok := c.(*data.DatasourceError); ok {
+			e.Datasource = &datasourceLocation{Alias: dsErr.Alias, Args: dsErr.Args}
+			break
+		}
+	}
+	return e
+}
+
+// templateCallers - the chain of `template` actions leading to the named
+// template, and the name of the template at the top of the chain. Which call
+// led to the error isn't known when a template is invoked from more than one
+// place, so the chain stops there.
+func templateCallers(t *tplate, tmpl *template.Template, rightDelim, name string) ([]templateCall, string) {
+	calls := []templateCall{}
+	seen := map[string]bool{}
+	for !seen[name] {
+		seen[name] = true
+		sites := []templateCall{}
+		caller := ""
+		for _, candidate := range tmpl.Templates() {
+			if candidate.Tree == nil {
+				continue
+			}
+			for _, n := range findTemplateNodes(candidate.Tree.Root, name) {
+				loc, _ := candidate.Tree.ErrorContext(n)
+				sites = append(sites, templateCall{Template: name, Location: t.location(loc, rightDelim)})
+				caller = candidate.Tree.Name
+			}
+		}
+		if len(sites) != 1 {
+			break
+		}
+		calls = append(calls, sites[0])
+		name = caller
+	}
+	return calls, name
+}
+
+// findTemplateNodes - the `template` actions invoking the named template
+func findTemplateNodes(node parse.Node, name string) []*parse.TemplateNode {
+	found := []*parse.TemplateNode{}
+	var walk func(n parse.Node)
+	walk = func(n parse.Node) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Nodes {
+				walk(c)
+			}
+		case *parse.IfNode:
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			if n.Name == name {
+				found = append(found, n)
+			}
+		}
+	}
+	walk(node)
+	return found
+}
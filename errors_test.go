@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"text/template"
+
+	"github.com/hairyhenderson/gomplate/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func runErrTemplate(t *testing.T, contents string) *TemplateError {
+	d := &data.Data{Sources: map[string]*data.Source{}}
+	g := &Gomplate{
+		funcMap:    template.FuncMap{"ds": d.Datasource},
+		leftDelim:  "{{",
+		rightDelim: "}}",
+	}
+	tp := &tplate{name: "foo.tmpl", contents: contents, target: &bytes.Buffer{}}
+	assert.NoError(t, tp.parseFrontMatter())
+	err := g.RunTemplate(tp)
+	te, ok := err.(*TemplateError)
+	assert.True(t, ok, "%v", err)
+	return te
+}
+
+func TestExecError(t *testing.T) {
+	te := runErrTemplate(t, "hello\n\t{{ ds \"cfg\" \"a.json\" }}\n")
+	assert.Equal(t, "foo.tmpl", te.Template)
+	assert.Equal(t, 2, te.Line)
+	assert.Equal(t, 5, te.Column)
+	assert.Equal(t, "error calling ds: Undefined datasource 'cfg'", te.Message)
+	assert.Equal(t, "\t{{ ds \"cfg\" \"a.json\" }}", te.Source)
+	assert.Equal(t, &datasourceLocation{Alias: "cfg", Args: []string{"a.json"}}, te.Datasource)
+	assert.Equal(t, `template: foo.tmpl:2:5: error calling ds: Undefined datasource 'cfg'
+  2 | 	{{ ds "cfg" "a.json" }}
+    | 	   ^
+  reading datasource 'cfg' with args ["a.json"]`, te.Error())
+}
+
+func TestExecErrorTemplateCalls(t *testing.T) {
+	te := runErrTemplate(t, `{{ define "inner" }}{{ .nope }}{{ end -}}
+{{ define "outer" }}{{ template "inner" . }}{{ end -}}
+{{ template "outer" . }}`)
+	assert.Equal(t, 1, te.Line)
+	assert.Equal(t, 24, te.Column)
+	assert.Equal(t, []templateCall{
+		{Template: "inner", Location: "foo.tmpl:2:33"},
+		{Template: "outer", Location: "foo.tmpl:3:13"},
+	}, te.Calls)
+	assert.Nil(t, te.Datasource)
+
+	// the chain stops when a template is called from more than one place
+	te = runErrTemplate(t, `{{ define "inner" }}{{ .nope }}{{ end -}}
+{{ template "inner" . }}{{ template "inner" . }}`)
+	assert.Empty(t, te.Calls)
+}
+
+func TestExecErrorTmplExec(t *testing.T) {
+	te := runErrTemplate(t, "{{ define \"sub\" }}{{ .nope }}{{ end -}}\n{{ tmpl.Exec \"sub\" . }}")
+	assert.Equal(t, 1, te.Line)
+	assert.Equal(t, 22, te.Column)
+	assert.Equal(t, "map has no entry for key \"nope\"", te.Message)
+	assert.Equal(t, []templateCall{{Template: "sub", Location: "foo.tmpl:2:8"}}, te.Calls)
+}
+
+func TestExecErrorFrontMatter(t *testing.T) {
+	te := runErrTemplate(t, "---\ncontext:\n  a: 1\n---\n{{ .a }} {{ .nope }}\n")
+	assert.Equal(t, 5, te.Line)
+	assert.Equal(t, 13, te.Column)
+	assert.Equal(t, "{{ .a }} {{ .nope }}", te.Source)
+}
+
+func TestParseError(t *testing.T) {
+	te := runErrTemplate(t, "ok\n{{ 1 }} {{ if }}\n")
+	assert.Equal(t, 2, te.Line)
+	assert.Equal(t, 9, te.Column)
+	assert.Equal(t, "missing value for if", te.Message)
+	assert.Equal(t, `template: foo.tmpl:2: missing value for if
+  2 | {{ 1 }} {{ if }}
+    |         ^`, te.Error())
+}
+
+func TestJSONError(t *testing.T) {
+	te := &TemplateError{
+		Template:   "<arg>",
+		Line:       1,
+		Column:     4,
+		Message:    "boom",
+		Source:     "{{ fail }}",
+		Datasource: &datasourceLocation{Alias: "cfg"},
+		err:        errors.New("boom"),
+	}
+	assert.Equal(t, `{"template":"<arg>","line":1,"column":4,"message":"boom","source":"{{ fail }}","datasource":{"alias":"cfg"}}`, jsonError(te).Error())
+	assert.Equal(t, `{"message":"oops"}`, jsonError(errors.New("oops")).Error())
+}
+
+func TestCaretIndent(t *testing.T) {
+	assert.Equal(t, "", caretIndent("abc", 1))
+	assert.Equal(t, "  ", caretIndent("abc", 3))
+	assert.Equal(t, "\t ", caretIndent("\tabc", 3))
+	assert.Equal(t, "   ", caretIndent("abc", 10))
+}
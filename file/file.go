@@ -0,0 +1,188 @@
+// Package file gives templates read access to the filesystem, optionally
+// confined to a root directory
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// FS - filesystem access for templates. When root is set, only paths inside
+// it (after resolving symbolic links) can be accessed.
+type FS struct {
+	fs   afero.Fs
+	root string
+}
+
+// New - create an FS. An empty root allows access to any path.
+func New(fs afero.Fs, root string) (*FS, error) {
+	f := &FS{fs: fs}
+	if root != "" {
+		r, err := f.abs(root)
+		if err != nil {
+			return nil, err
+		}
+		fi, err := fs.Stat(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid root %s: %v", root, err)
+		}
+		if !fi.IsDir() {
+			return nil, fmt.Errorf("invalid root %s: not a directory", root)
+		}
+		f.root = r
+	}
+	return f, nil
+}
+
+// Resolve - the path to access for the given name, or an error if it's
+// outside the root. Relative names are relative to the working directory.
+func (f *FS) Resolve(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("empty path")
+	}
+	if f.root == "" {
+		return name, nil
+	}
+	p, err := f.abs(name)
+	if err != nil {
+		return "", err
+	}
+	if !within(f.root, p) {
+		return "", fmt.Errorf("access to %s denied: outside of %s", name, f.root)
+	}
+	return name, nil
+}
+
+// abs - the absolute form of the path, with symlinks resolved as far as
+// possible (only on the OS filesystem)
+func (f *FS) abs(name string) (string, error) {
+	p, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := f.fs.(*afero.OsFs); !ok {
+		return p, nil
+	}
+	return evalSymlinks(p), nil
+}
+
+// evalSymlinks - resolve symlinks in the longest existing prefix of the path
+func evalSymlinks(p string) string {
+	if r, err := filepath.EvalSymlinks(p); err == nil {
+		return r
+	}
+	dir, base := filepath.Split(p)
+	dir = filepath.Clean(dir)
+	if dir == p {
+		return p
+	}
+	return filepath.Join(evalSymlinks(dir), base)
+}
+
+// within - whether the path p is root, or inside it
+func within(root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Read - the contents of the file
+func (f *FS) Read(name string) (string, error) {
+	p, err := f.Resolve(name)
+	if err != nil {
+		return "", err
+	}
+	b, err := afero.ReadFile(f.fs, p)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Exists - whether the path exists (and is accessible)
+func (f *FS) Exists(name string) bool {
+	p, err := f.Resolve(name)
+	if err != nil {
+		return false
+	}
+	_, err = f.fs.Stat(p)
+	return err == nil
+}
+
+// IsDir - whether the path exists and is a directory
+func (f *FS) IsDir(name string) bool {
+	p, err := f.Resolve(name)
+	if err != nil {
+		return false
+	}
+	fi, err := f.fs.Stat(p)
+	return err == nil && fi.IsDir()
+}
+
+// Stat -
+func (f *FS) Stat(name string) (os.FileInfo, error) {
+	p, err := f.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return f.fs.Stat(p)
+}
+
+// ReadDir - the sorted names of the directory's entries
+func (f *FS) ReadDir(name string) ([]string, error) {
+	p, err := f.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := afero.ReadDir(f.fs, p)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+	return names, nil
+}
+
+// Walk - the paths of the directory and everything in it, recursively, in
+// lexical order. Symbolic links aren't followed.
+func (f *FS) Walk(name string) ([]string, error) {
+	p, err := f.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	err = afero.Walk(f.fs, p, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	return paths, err
+}
+
+// Glob - the sorted paths matching the pattern (see filepath.Match for the
+// syntax). Matches outside the root are left out.
+func (f *FS) Glob(pattern string) ([]string, error) {
+	matches, err := afero.Glob(f.fs, pattern)
+	if err != nil {
+		return nil, err
+	}
+	out := []string{}
+	for _, m := range matches {
+		if _, err := f.Resolve(m); err == nil {
+			out = append(out, m)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
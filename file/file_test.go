@@ -0,0 +1,192 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupFS() afero.Fs {
+	fs := afero.NewMemMapFs()
+	_ = fs.MkdirAll("/base/sub/deeper", 0755)
+	_ = fs.MkdirAll("/other", 0755)
+	_ = afero.WriteFile(fs, "/base/foo.txt", []byte("foo"), 0644)
+	_ = afero.WriteFile(fs, "/base/bar.txt", []byte("bar"), 0644)
+	_ = afero.WriteFile(fs, "/base/sub/baz.yaml", []byte("baz: qux"), 0644)
+	_ = afero.WriteFile(fs, "/other/secret", []byte("hunter2"), 0600)
+	return fs
+}
+
+func TestNew(t *testing.T) {
+	fs := setupFS()
+	_, err := New(fs, "")
+	assert.NoError(t, err)
+	_, err = New(fs, "/base")
+	assert.NoError(t, err)
+	_, err = New(fs, "/nonexistent")
+	assert.Error(t, err)
+	_, err = New(fs, "/base/foo.txt")
+	assert.Error(t, err)
+}
+
+func TestRead(t *testing.T) {
+	f, _ := New(setupFS(), "")
+	out, err := f.Read("/base/foo.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", out)
+	out, err = f.Read("/other/secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", out)
+	_, err = f.Read("/base/missing")
+	assert.Error(t, err)
+	_, err = f.Read("")
+	assert.Error(t, err)
+
+	f, _ = New(setupFS(), "/base")
+	out, err = f.Read("/base/sub/baz.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "baz: qux", out)
+	_, err = f.Read("/other/secret")
+	assert.Error(t, err)
+	_, err = f.Read("/base/../other/secret")
+	assert.Error(t, err)
+}
+
+func TestExistsIsDir(t *testing.T) {
+	f, _ := New(setupFS(), "/base")
+	assert.True(t, f.Exists("/base/foo.txt"))
+	assert.True(t, f.Exists("/base/sub"))
+	assert.False(t, f.Exists("/base/missing"))
+	assert.False(t, f.Exists("/other/secret"))
+
+	assert.True(t, f.IsDir("/base/sub"))
+	assert.False(t, f.IsDir("/base/foo.txt"))
+	assert.False(t, f.IsDir("/base/missing"))
+	assert.False(t, f.IsDir("/other"))
+}
+
+func TestStat(t *testing.T) {
+	f, _ := New(setupFS(), "/base")
+	fi, err := f.Stat("/base/foo.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "foo.txt", fi.Name())
+	assert.Equal(t, int64(3), fi.Size())
+	assert.False(t, fi.IsDir())
+
+	_, err = f.Stat("/other/secret")
+	assert.Error(t, err)
+}
+
+func TestReadDir(t *testing.T) {
+	f, _ := New(setupFS(), "/base")
+	out, err := f.ReadDir("/base")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bar.txt", "foo.txt", "sub"}, out)
+
+	_, err = f.ReadDir("/other")
+	assert.Error(t, err)
+	_, err = f.ReadDir("/base/missing")
+	assert.Error(t, err)
+}
+
+func TestWalk(t *testing.T) {
+	f, _ := New(setupFS(), "/base")
+	out, err := f.Walk("/base")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/base",
+		"/base/bar.txt",
+		"/base/foo.txt",
+		"/base/sub",
+		"/base/sub/baz.yaml",
+		"/base/sub/deeper",
+	}, out)
+
+	_, err = f.Walk("/other")
+	assert.Error(t, err)
+	_, err = f.Walk("/base/missing")
+	assert.Error(t, err)
+}
+
+func TestGlob(t *testing.T) {
+	f, _ := New(setupFS(), "/base")
+	out, err := f.Glob("/base/*.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/base/bar.txt", "/base/foo.txt"}, out)
+
+	out, err = f.Glob("/*/*")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/base/bar.txt", "/base/foo.txt", "/base/sub"}, out)
+
+	_, err = f.Glob("/base/[")
+	assert.Error(t, err)
+}
+
+func TestSymlinkEscape(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomplate-file")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	root := filepath.Join(dir, "root")
+	assert.NoError(t, os.Mkdir(root, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "secret"), []byte("hunter2"), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, "ok"), []byte("ok"), 0600))
+	assert.NoError(t, os.Symlink(filepath.Join(dir, "secret"), filepath.Join(root, "link")))
+
+	f, err := New(afero.NewOsFs(), root)
+	assert.NoError(t, err)
+	out, err := f.Read(filepath.Join(root, "ok"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", out)
+	_, err = f.Read(filepath.Join(root, "link"))
+	assert.Error(t, err)
+	assert.False(t, f.Exists(filepath.Join(root, "link")))
+}
+
+func TestWriter(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_ = fs.MkdirAll("/out", 0755)
+	w, err := NewWriter(fs, "/out")
+	assert.NoError(t, err)
+
+	assert.NoError(t, w.Write("a.txt", []byte("hello")))
+	b, err := afero.ReadFile(fs, "/out/a.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+
+	assert.NoError(t, w.Write("deep/er/b.txt", []byte("world")))
+	b, err = afero.ReadFile(fs, "/out/deep/er/b.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "world", string(b))
+
+	assert.NoError(t, w.Write("/out/c.txt", []byte("abs")))
+	assert.NoError(t, w.Write("a.txt", []byte("hi")))
+	b, _ = afero.ReadFile(fs, "/out/a.txt")
+	assert.Equal(t, "hi", string(b))
+
+	for _, name := range []string{"", ".", "../escape.txt", "deep/../../escape.txt", "/etc/passwd", "/out"} {
+		assert.Error(t, w.Write(name, []byte("x")), name)
+	}
+	exists, _ := afero.Exists(fs, "/escape.txt")
+	assert.False(t, exists)
+}
+
+func TestWriterSymlinkEscape(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomplate-write")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "out")
+	assert.NoError(t, os.Mkdir(out, 0755))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "elsewhere"), 0755))
+	assert.NoError(t, os.Symlink(filepath.Join(dir, "elsewhere"), filepath.Join(out, "link")))
+
+	w, err := NewWriter(afero.NewOsFs(), out)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Write("ok.txt", []byte("ok")))
+	assert.Error(t, w.Write("link/bad.txt", []byte("bad")))
+	_, err = os.Stat(filepath.Join(dir, "elsewhere", "bad.txt"))
+	assert.True(t, os.IsNotExist(err))
+}
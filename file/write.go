@@ -0,0 +1,79 @@
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+// Writer - writes files from templates, confined to an output root directory
+type Writer struct {
+	fs   afero.Fs
+	root string
+}
+
+// NewWriter - create a Writer for the given output root
+func NewWriter(fs afero.Fs, root string) (*Writer, error) {
+	w := &Writer{fs: fs}
+	r, err := w.abs(root)
+	if err != nil {
+		return nil, err
+	}
+	w.root = r
+	return w, nil
+}
+
+// Resolve - the path to write for the given name. Relative names are relative
+// to the output root, and an error is returned for any path outside of it.
+func (w *Writer) Resolve(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("empty path")
+	}
+	p := name
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(w.root, p)
+	}
+	p, err := w.abs(p)
+	if err != nil {
+		return "", err
+	}
+	if p == w.root || !within(w.root, p) {
+		return "", fmt.Errorf("refusing to write %s: outside of output directory %s", name, w.root)
+	}
+	return p, nil
+}
+
+func (w *Writer) abs(name string) (string, error) {
+	p, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := w.fs.(*afero.OsFs); !ok {
+		return p, nil
+	}
+	return evalSymlinks(p), nil
+}
+
+// Write - write the content to the named file, creating any missing parent
+// directories. As with other outputs, an existing file is overwritten, and
+// keeps its mode.
+func (w *Writer) Write(name string, content []byte) error {
+	p, err := w.Resolve(name)
+	if err != nil {
+		return err
+	}
+	if err = w.fs.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	f, err := w.fs.OpenFile(p, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
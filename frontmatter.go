@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// frontMatter - settings a template can declare for itself, in a YAML block
+// at the very start of the file, between two lines containing only "---"
+type frontMatter struct {
+	// Datasources - alias: URL pairs, with relative file paths being relative
+	// to the template's directory
+	Datasources map[string]string `yaml:"datasources"`
+	// Context - extra values to add to the template's context
+	Context map[string]interface{} `yaml:"context"`
+	// Out - the output path, relative to the output directory
+	Out string `yaml:"out"`
+	// Mode - the output file's mode, in octal
+	Mode       string `yaml:"mode"`
+	LeftDelim  string `yaml:"leftDelim"`
+	RightDelim string `yaml:"rightDelim"`
+
+	mode  uint32
+	lines int
+}
+
+// frontMatterKeys - the settings front matter can contain
+var frontMatterKeys = map[string]bool{
+	"datasources": true,
+	"context":     true,
+	"out":         true,
+	"mode":        true,
+	"leftDelim":   true,
+	"rightDelim":  true,
+}
+
+// splitFrontMatter - split the front matter (if any) from the start of the
+// template. The block is only taken as front matter if it's empty or sets at
+// least one known setting, so that YAML templates which begin with a "---"
+// document separator aren't mistaken for front matter. Other keys in front
+// matter (such as misspelled settings) are errors.
+func splitFrontMatter(contents string) (*frontMatter, string, error) {
+	if !strings.HasPrefix(contents, "---\n") && !strings.HasPrefix(contents, "---\r\n") {
+		return nil, contents, nil
+	}
+	lines := strings.SplitAfter(contents, "\n")
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], "\r\n") != "---" {
+			continue
+		}
+		block := []byte(strings.Join(lines[1:i], ""))
+		keys := map[string]interface{}{}
+		if err := yaml.Unmarshal(block, &keys); err != nil {
+			return nil, contents, nil
+		}
+		unknown := []string{}
+		for k := range keys {
+			if !frontMatterKeys[k] {
+				unknown = append(unknown, k)
+			}
+		}
+		if len(keys) > 0 && len(unknown) == len(keys) {
+			return nil, contents, nil
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return nil, contents, fmt.Errorf("unknown setting(s) %s", strings.Join(unknown, ", "))
+		}
+		fm := &frontMatter{}
+		if err := yaml.UnmarshalStrict(block, fm); err != nil {
+			return nil, contents, err
+		}
+		fm.lines = i + 1
+		return fm, strings.Join(lines[i+1:], ""), nil
+	}
+	return nil, contents, nil
+}
+
+// validate - check the settings, resolving relative datasource paths against
+// the template's directory
+func (fm *frontMatter) validate(name string) error {
+	if fm.Mode != "" {
+		m, err := strconv.ParseUint(fm.Mode, 8, 32)
+		if err != nil {
+			return fmt.Errorf("invalid mode %q: must be an octal number like 0644", fm.Mode)
+		}
+		fm.mode = uint32(m)
+	}
+	if (fm.LeftDelim == "") != (fm.RightDelim == "") {
+		return fmt.Errorf("leftDelim and rightDelim must be set together")
+	}
+	for k := range fm.Context {
+		if isReservedContextKey(k) {
+			return fmt.Errorf("invalid context: %s is reserved", k)
+		}
+	}
+	dir := "."
+	if name != "<arg>" && name != "-" {
+		dir = filepath.Dir(name)
+	}
+	for alias, value := range fm.Datasources {
+		u, err := url.Parse(value)
+		if err != nil {
+			return fmt.Errorf("invalid datasource %s: %v", alias, err)
+		}
+		if u.Scheme == "" && value != "-" && !filepath.IsAbs(value) {
+			fm.Datasources[alias] = filepath.Join(dir, value)
+		}
+	}
+	return nil
+}
+
+// comment - a template comment spanning as many lines as the front matter
+// did, so that line numbers in error messages still match the template file
+func (fm *frontMatter) comment(leftDelim, rightDelim string) string {
+	return leftDelim + "/*" + strings.Repeat("\n", fm.lines) + "*/" + rightDelim
+}
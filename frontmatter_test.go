@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	"github.com/hairyhenderson/gomplate/data"
+	"github.com/hairyhenderson/gomplate/funcs"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	fm, rest, err := splitFrontMatter("---\nout: foo.yaml\nmode: 0600\n---\nhello\n")
+	assert.NoError(t, err)
+	assert.NotNil(t, fm)
+	assert.Equal(t, "foo.yaml", fm.Out)
+	assert.Equal(t, "0600", fm.Mode)
+	assert.Equal(t, 4, fm.lines)
+	assert.Equal(t, "hello\n", rest)
+
+	fm, rest, err = splitFrontMatter("---\r\ncontext:\r\n  foo: bar\r\n---\r\nhello")
+	assert.NoError(t, err)
+	assert.NotNil(t, fm)
+	assert.Equal(t, "bar", fm.Context["foo"])
+	assert.Equal(t, "hello", rest)
+
+	fm, rest, err = splitFrontMatter("---\n---\nhello")
+	assert.NoError(t, err)
+	assert.NotNil(t, fm)
+	assert.Equal(t, "hello", rest)
+
+	for _, in := range []string{
+		"hello",
+		"",
+		"---",
+		"--- \nout: foo\n---\n",
+		"---\nout: foo\n",
+		// YAML templates beginning with a document separator
+		"---\nfoo: bar\n---\nbaz: qux\n",
+		"---\n{{ .Env.FOO }}\n---\n",
+	} {
+		fm, rest, err = splitFrontMatter(in)
+		assert.NoError(t, err, in)
+		assert.Nil(t, fm, in)
+		assert.Equal(t, in, rest)
+	}
+
+	// misspelled or mistyped settings are errors, rather than text
+	_, _, err = splitFrontMatter("---\nout: foo.yaml\nmdoe: \"0600\"\n---\nhello\n")
+	assert.EqualError(t, err, "unknown setting(s) mdoe")
+	_, _, err = splitFrontMatter("---\nout: [foo.yaml]\n---\nhello\n")
+	assert.Error(t, err)
+}
+
+func TestFrontMatterValidate(t *testing.T) {
+	fm := &frontMatter{
+		Mode:        "0640",
+		Datasources: map[string]string{"rel": "config.json", "abs": "/tmp/x.json", "url": "https://example.com/x.json"},
+	}
+	assert.NoError(t, fm.validate("in/sub/a.tmpl"))
+	assert.Equal(t, uint32(0640), fm.mode)
+	assert.Equal(t, "in/sub/config.json", fm.Datasources["rel"])
+	assert.Equal(t, "/tmp/x.json", fm.Datasources["abs"])
+	assert.Equal(t, "https://example.com/x.json", fm.Datasources["url"])
+
+	fm = &frontMatter{Datasources: map[string]string{"rel": "config.json"}}
+	assert.NoError(t, fm.validate("<arg>"))
+	assert.Equal(t, "config.json", fm.Datasources["rel"])
+
+	assert.Error(t, (&frontMatter{Mode: "rw"}).validate("a"))
+	assert.Error(t, (&frontMatter{Mode: "0999"}).validate("a"))
+	assert.Error(t, (&frontMatter{LeftDelim: "[["}).validate("a"))
+	assert.Error(t, (&frontMatter{Context: map[string]interface{}{"Env": 1}}).validate("a"))
+}
+
+func TestRunTemplateFrontMatter(t *testing.T) {
+	g := &Gomplate{
+		funcMap:    template.FuncMap{},
+		leftDelim:  "{{",
+		rightDelim: "}}",
+		data:       &data.Data{},
+		context:    Context{"cli": "set"},
+	}
+	tp := &tplate{name: "a.tmpl", contents: "---\nleftDelim: '[['\nrightDelim: ']]'\ncontext:\n  greeting: hello\n  cli: default\n---\n{{ [[ .greeting ]] [[ .cli ]] }}"}
+	assert.NoError(t, tp.parseFrontMatter())
+	out := &bytes.Buffer{}
+	tp.target = out
+	assert.NoError(t, g.RunTemplate(tp))
+	assert.Equal(t, "{{ hello set }}", out.String())
+
+	// line numbers in errors still match the template file
+	tp = &tplate{name: "b.tmpl", contents: "---\nout: foo\n---\nline 4\n{{ .nope.nope }}", target: &bytes.Buffer{}}
+	assert.NoError(t, tp.parseFrontMatter())
+	err := g.RunTemplate(tp)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "b.tmpl:5:")
+
+	// front matter datasources are only available to the template defining
+	// them, so the same alias can be used for different files
+	dir, err := ioutil.TempDir("", "gomplate-frontmatter")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	funcs.AddDataFuncs(g.funcMap, g.data)
+	for _, sub := range []string{"c", "d"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, sub), 0755))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, sub, "config.json"), []byte(`{"name": "`+sub+`"}`), 0644))
+
+		tp = &tplate{name: filepath.Join(dir, sub, "a.tmpl"), contents: "---\ndatasources:\n  config: config.json\n---\n{{ (ds \"config\").name }}"}
+		assert.NoError(t, tp.parseFrontMatter())
+		out = &bytes.Buffer{}
+		tp.target = out
+		assert.NoError(t, g.RunTemplate(tp))
+		assert.Equal(t, sub, out.String())
+		assert.False(t, g.data.DatasourceExists("config"))
+	}
+
+	tp = &tplate{name: "e.tmpl", contents: `{{ datasourceExists "config" }}`}
+	out = &bytes.Buffer{}
+	tp.target = out
+	assert.NoError(t, g.RunTemplate(tp))
+	assert.Equal(t, "false", out.String())
+}
+
+func TestGatherTemplatesFrontMatter(t *testing.T) {
+	origfs := fs
+	defer func() { fs = origfs }()
+	fs = afero.NewMemMapFs()
+	_ = fs.MkdirAll("/in", 0755)
+	_ = afero.WriteFile(fs, "/in/a.tmpl", []byte("---\nout: gen/a.yaml\nmode: 0600\n---\nhello"), 0644)
+	_ = afero.WriteFile(fs, "/in/b.tmpl", []byte("---\nout: ../escape\n---\n"), 0644)
+
+	templates, _, err := gatherTemplates(&GomplateOpts{
+		inputFiles:  []string{"/in/a.tmpl"},
+		outputFiles: []string{"-"},
+		outputDir:   "/out",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, templates, 1)
+	assert.Equal(t, "/out/gen/a.yaml", templates[0].targetPath)
+	assert.Equal(t, "hello", templates[0].contents)
+	fi, err := fs.Stat("/out/gen/a.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	_, _, err = gatherTemplates(&GomplateOpts{
+		inputFiles:  []string{"/in/b.tmpl"},
+		outputFiles: []string{"-"},
+		outputDir:   "/out",
+	})
+	assert.Error(t, err)
+
+	// --out isn't silently overridden
+	_, _, err = gatherTemplates(&GomplateOpts{
+		inputFiles:  []string{"/in/a.tmpl"},
+		outputFiles: []string{"/out/a.tmpl"},
+		outputDir:   "/out",
+	})
+	assert.EqualError(t, err, "invalid front matter in /in/a.tmpl: out can't be set when --out is given")
+
+	_ = afero.WriteFile(fs, "/in/c.tmpl", []byte("---\nout: c.yaml\nmdoe: \"0600\"\n---\n"), 0644)
+	_, _, err = gatherTemplates(&GomplateOpts{
+		inputFiles:  []string{"/in/c.tmpl"},
+		outputFiles: []string{"-"},
+		outputDir:   "/out",
+	})
+	assert.EqualError(t, err, "invalid front matter in /in/c.tmpl: unknown setting(s) mdoe")
+}
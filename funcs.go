@@ -4,13 +4,15 @@ import (
 	"text/template"
 
 	"github.com/hairyhenderson/gomplate/data"
+	"github.com/hairyhenderson/gomplate/file"
 	"github.com/hairyhenderson/gomplate/funcs"
 )
 
 // initFuncs - The function mappings are defined here!
-func initFuncs(d *data.Data) template.FuncMap {
+func initFuncs(d *data.Data, fsys *file.FS, w *file.Writer) template.FuncMap {
 	f := template.FuncMap{}
 	funcs.AddDataFuncs(f, d)
+	funcs.AddCollFuncs(f)
 	funcs.AWSFuncs(f)
 	funcs.AddBase64Funcs(f)
 	funcs.AddNetFuncs(f)
@@ -21,5 +23,9 @@ func initFuncs(d *data.Data) template.FuncMap {
 	funcs.AddTimeFuncs(f)
 	funcs.AddMathFuncs(f)
 	funcs.AddCryptoFuncs(f)
+	funcs.AddRandomFuncs(f)
+	funcs.AddUUIDFuncs(f)
+	funcs.AddFileFuncs(f, fsys, w)
+	funcs.AddFilePathFuncs(f)
 	return f
 }
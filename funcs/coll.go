@@ -0,0 +1,123 @@
+package funcs
+
+import (
+	"sync"
+
+	"github.com/hairyhenderson/gomplate/coll"
+	"github.com/hairyhenderson/gomplate/data"
+)
+
+var (
+	collNS     *CollFuncs
+	collNSInit sync.Once
+)
+
+// CollNS -
+func CollNS() *CollFuncs {
+	collNSInit.Do(func() { collNS = &CollFuncs{} })
+	return collNS
+}
+
+// AddCollFuncs -
+func AddCollFuncs(f map[string]interface{}) {
+	f["coll"] = CollNS
+
+	f["dict"] = CollNS().Dict
+	f["list"] = CollNS().List
+	f["append"] = CollNS().Append
+	f["prepend"] = CollNS().Prepend
+	f["uniq"] = CollNS().Uniq
+	f["flatten"] = CollNS().Flatten
+	f["reverse"] = CollNS().Reverse
+	f["merge"] = CollNS().Merge
+	f["pick"] = CollNS().Pick
+	f["omit"] = CollNS().Omit
+}
+
+// CollFuncs -
+type CollFuncs struct{}
+
+// Dict -
+func (f *CollFuncs) Dict(v ...interface{}) map[string]interface{} {
+	return coll.Dict(v...)
+}
+
+// List -
+func (f *CollFuncs) List(v ...interface{}) []interface{} {
+	return coll.List(v...)
+}
+
+// Append -
+func (f *CollFuncs) Append(v interface{}, list interface{}) ([]interface{}, error) {
+	return coll.Append(v, list)
+}
+
+// Prepend -
+func (f *CollFuncs) Prepend(v interface{}, list interface{}) ([]interface{}, error) {
+	return coll.Prepend(v, list)
+}
+
+// Uniq -
+func (f *CollFuncs) Uniq(list interface{}) ([]interface{}, error) {
+	return coll.Uniq(list)
+}
+
+// Flatten -
+func (f *CollFuncs) Flatten(args ...interface{}) ([]interface{}, error) {
+	return coll.Flatten(args...)
+}
+
+// Reverse -
+func (f *CollFuncs) Reverse(list interface{}) ([]interface{}, error) {
+	return coll.Reverse(list)
+}
+
+// Sort -
+func (f *CollFuncs) Sort(args ...interface{}) ([]interface{}, error) {
+	return coll.Sort(args...)
+}
+
+// Keys -
+func (f *CollFuncs) Keys(in ...interface{}) ([]string, error) {
+	return coll.Keys(in...)
+}
+
+// Values -
+func (f *CollFuncs) Values(in ...interface{}) ([]interface{}, error) {
+	return coll.Values(in...)
+}
+
+// Merge -
+func (f *CollFuncs) Merge(dst interface{}, srcs ...interface{}) (interface{}, error) {
+	return coll.Merge(dst, srcs...)
+}
+
+// Pick -
+func (f *CollFuncs) Pick(args ...interface{}) (interface{}, error) {
+	return coll.Pick(args...)
+}
+
+// Omit -
+func (f *CollFuncs) Omit(args ...interface{}) (interface{}, error) {
+	return coll.Omit(args...)
+}
+
+// Has -
+func (f *CollFuncs) Has(in interface{}, key string) bool {
+	return coll.Has(in, key)
+}
+
+// Where -
+func (f *CollFuncs) Where(args ...interface{}) ([]interface{}, error) {
+	return coll.Where(args...)
+}
+
+// GroupBy -
+func (f *CollFuncs) GroupBy(key string, list interface{}) (map[string]interface{}, error) {
+	return coll.GroupBy(key, list)
+}
+
+// JQ -
+func (f *CollFuncs) JQ(expr string, in interface{}) (interface{}, error) {
+	return data.Query(expr, in)
+}
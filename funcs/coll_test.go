@@ -0,0 +1,21 @@
+package funcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollJQ(t *testing.T) {
+	c := CollNS()
+	out, err := c.JQ(".a[1]", map[string]interface{}{"a": []interface{}{1, 2}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, out)
+}
+
+func TestAddCollFuncs(t *testing.T) {
+	f := map[string]interface{}{"has": "conv.Has"}
+	AddCollFuncs(f)
+	assert.Equal(t, "conv.Has", f["has"])
+	assert.NotNil(t, f["dict"])
+}
@@ -27,6 +27,8 @@ func AddConvFuncs(f map[string]interface{}) {
 	f["has"] = ConvNS().Has
 	f["slice"] = ConvNS().Slice
 	f["join"] = ConvNS().Join
+	f["default"] = ConvNS().Default
+	f["required"] = ConvNS().Required
 }
 
 // ConvFuncs -
@@ -59,6 +61,16 @@ func (f *ConvFuncs) Atoi(s string) int {
 	return conv.MustAtoi(s)
 }
 
+// Default - def when in is missing (nil or ""), otherwise in
+func (f *ConvFuncs) Default(def, in interface{}) interface{} {
+	return conv.Default(def, in)
+}
+
+// Required - in, or an error with the given message when it's missing
+func (f *ConvFuncs) Required(msg string, in interface{}) (interface{}, error) {
+	return conv.Required(msg, in)
+}
+
 func (f *ConvFuncs) URL(s string) (*url.URL, error) {
 	return url.Parse(s)
 }
@@ -1,22 +1,11 @@
 package funcs
 
 import (
-	"sync"
+	"fmt"
 
 	"github.com/hairyhenderson/gomplate/data"
 )
 
-var (
-	dataNS     *DataFuncs
-	dataNSInit sync.Once
-)
-
-// DataNS -
-func DataNS() *DataFuncs {
-	dataNSInit.Do(func() { dataNS = &DataFuncs{} })
-	return dataNS
-}
-
 // AddDataFuncs -
 func AddDataFuncs(f map[string]interface{}, d *data.Data) {
 	f["datasource"] = d.Datasource
@@ -24,25 +13,50 @@ func AddDataFuncs(f map[string]interface{}, d *data.Data) {
 	f["datasourceExists"] = d.DatasourceExists
 	f["include"] = d.Include
 
-	f["data"] = DataNS
-
-	f["json"] = DataNS().JSON
-	f["jsonArray"] = DataNS().JSONArray
-	f["yaml"] = DataNS().YAML
-	f["yamlArray"] = DataNS().YAMLArray
-	f["toml"] = DataNS().TOML
-	f["csv"] = DataNS().CSV
-	f["csvByRow"] = DataNS().CSVByRow
-	f["csvByColumn"] = DataNS().CSVByColumn
-	f["toJSON"] = DataNS().ToJSON
-	f["toJSONPretty"] = DataNS().ToJSONPretty
-	f["toYAML"] = DataNS().ToYAML
-	f["toTOML"] = DataNS().ToTOML
-	f["toCSV"] = DataNS().ToCSV
+	// data.Validate needs the datasources, so each function map gets its own
+	// namespace rather than a singleton
+	ns := &DataFuncs{d: d}
+	f["data"] = func() *DataFuncs { return ns }
+
+	f["json"] = ns.JSON
+	f["jsonArray"] = ns.JSONArray
+	f["yaml"] = ns.YAML
+	f["yamlArray"] = ns.YAMLArray
+	f["yamlDocuments"] = ns.YAMLDocuments
+	f["jsonLines"] = ns.JSONLines
+	f["toml"] = ns.TOML
+	f["xml"] = ns.XML
+	f["jsonOrdered"] = ns.JSONOrdered
+	f["yamlOrdered"] = ns.YAMLOrdered
+	f["tomlOrdered"] = ns.TOMLOrdered
+	f["xmlOrdered"] = ns.XMLOrdered
+	f["hcl"] = ns.HCL
+	f["dotenv"] = ns.Dotenv
+	f["ini"] = ns.INI
+	f["properties"] = ns.Properties
+	f["csv"] = ns.CSV
+	f["csvByRow"] = ns.CSVByRow
+	f["csvByColumn"] = ns.CSVByColumn
+	f["csvTyped"] = ns.CSVTyped
+	f["query"] = ns.Query
+	f["toJSON"] = ns.ToJSON
+	f["toJSONPretty"] = ns.ToJSONPretty
+	f["toYAML"] = ns.ToYAML
+	f["toYAMLDocuments"] = ns.ToYAMLDocuments
+	f["toJSONLines"] = ns.ToJSONLines
+	f["toTOML"] = ns.ToTOML
+	f["toCSV"] = ns.ToCSV
+	f["toXML"] = ns.ToXML
+	f["toHCL"] = ns.ToHCL
+	f["toDotenv"] = ns.ToDotenv
+	f["toINI"] = ns.ToINI
+	f["toProperties"] = ns.ToProperties
 }
 
 // DataFuncs -
-type DataFuncs struct{}
+type DataFuncs struct {
+	d *data.Data
+}
 
 // JSON -
 func (f *DataFuncs) JSON(in string) map[string]interface{} {
@@ -64,11 +78,66 @@ func (f *DataFuncs) YAMLArray(in string) []interface{} {
 	return data.YAMLArray(in)
 }
 
+// YAMLDocuments -
+func (f *DataFuncs) YAMLDocuments(in string) []interface{} {
+	return data.YAMLDocuments(in)
+}
+
+// JSONLines -
+func (f *DataFuncs) JSONLines(in string) []interface{} {
+	return data.JSONLines(in)
+}
+
 // TOML -
 func (f *DataFuncs) TOML(in string) interface{} {
 	return data.TOML(in)
 }
 
+// XML -
+func (f *DataFuncs) XML(in string) map[string]interface{} {
+	return data.XML(in)
+}
+
+// JSONOrdered -
+func (f *DataFuncs) JSONOrdered(in string) data.OrderedMap {
+	return data.JSONOrdered(in)
+}
+
+// YAMLOrdered -
+func (f *DataFuncs) YAMLOrdered(in string) data.OrderedMap {
+	return data.YAMLOrdered(in)
+}
+
+// TOMLOrdered -
+func (f *DataFuncs) TOMLOrdered(in string) data.OrderedMap {
+	return data.TOMLOrdered(in)
+}
+
+// XMLOrdered -
+func (f *DataFuncs) XMLOrdered(in string) data.OrderedMap {
+	return data.XMLOrdered(in)
+}
+
+// HCL -
+func (f *DataFuncs) HCL(in string) map[string]interface{} {
+	return data.HCL(in)
+}
+
+// Dotenv -
+func (f *DataFuncs) Dotenv(in string) map[string]interface{} {
+	return data.Dotenv(in)
+}
+
+// INI -
+func (f *DataFuncs) INI(in string) map[string]interface{} {
+	return data.INI(in)
+}
+
+// Properties -
+func (f *DataFuncs) Properties(in string) map[string]interface{} {
+	return data.Properties(in)
+}
+
 // CSV -
 func (f *DataFuncs) CSV(args ...string) [][]string {
 	return data.CSV(args...)
@@ -84,11 +153,49 @@ func (f *DataFuncs) CSVByColumn(args ...string) (cols map[string][]string) {
 	return data.CSVByColumn(args...)
 }
 
+// CSVTyped -
+func (f *DataFuncs) CSVTyped(args ...string) []map[string]interface{} {
+	return data.CSVTyped(args...)
+}
+
 // ToCSV -
 func (f *DataFuncs) ToCSV(args ...interface{}) string {
 	return data.ToCSV(args...)
 }
 
+// SortKeys -
+func (f *DataFuncs) SortKeys(in interface{}) interface{} {
+	return data.SortKeys(in)
+}
+
+// Query -
+func (f *DataFuncs) Query(expr string, in interface{}) (interface{}, error) {
+	return data.Query(expr, in)
+}
+
+// Validate -
+func (f *DataFuncs) Validate(schemaAlias string, in interface{}) (interface{}, error) {
+	if f.d == nil {
+		return nil, fmt.Errorf("no datasources are available to validate against")
+	}
+	return f.d.Validate(schemaAlias, in)
+}
+
+// Patch -
+func (f *DataFuncs) Patch(patch, in interface{}) (interface{}, error) {
+	return data.Patch(patch, in)
+}
+
+// MergePatch -
+func (f *DataFuncs) MergePatch(patch, in interface{}) (interface{}, error) {
+	return data.MergePatch(patch, in)
+}
+
+// Diff -
+func (f *DataFuncs) Diff(from, to interface{}) []interface{} {
+	return data.Diff(from, to)
+}
+
 // ToJSON -
 func (f *DataFuncs) ToJSON(in interface{}) string {
 	return data.ToJSON(in)
@@ -104,7 +211,42 @@ func (f *DataFuncs) ToYAML(in interface{}) string {
 	return data.ToYAML(in)
 }
 
+// ToYAMLDocuments -
+func (f *DataFuncs) ToYAMLDocuments(in interface{}) string {
+	return data.ToYAMLDocuments(in)
+}
+
+// ToJSONLines -
+func (f *DataFuncs) ToJSONLines(in interface{}) string {
+	return data.ToJSONLines(in)
+}
+
 // ToTOML -
 func (f *DataFuncs) ToTOML(in interface{}) string {
 	return data.ToTOML(in)
 }
+
+// ToXML -
+func (f *DataFuncs) ToXML(args ...interface{}) string {
+	return data.ToXML(args...)
+}
+
+// ToHCL -
+func (f *DataFuncs) ToHCL(in interface{}) string {
+	return data.ToHCL(in)
+}
+
+// ToDotenv -
+func (f *DataFuncs) ToDotenv(in interface{}) string {
+	return data.ToDotenv(in)
+}
+
+// ToINI -
+func (f *DataFuncs) ToINI(in interface{}) string {
+	return data.ToINI(in)
+}
+
+// ToProperties -
+func (f *DataFuncs) ToProperties(in interface{}) string {
+	return data.ToProperties(in)
+}
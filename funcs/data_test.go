@@ -0,0 +1,34 @@
+package funcs
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/blang/vfs"
+	"github.com/blang/vfs/memfs"
+	"github.com/hairyhenderson/gomplate/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataValidate(t *testing.T) {
+	fs := memfs.Create()
+	f, _ := vfs.Create(fs, "/schema.json")
+	_, _ = f.Write([]byte(`{"type": "object", "required": ["name"]}`))
+	d := &data.Data{Sources: map[string]*data.Source{
+		"schema": {Alias: "schema", URL: &url.URL{Scheme: "file", Path: "/schema.json"}, Type: "application/json", FS: fs},
+	}}
+
+	funcMap := map[string]interface{}{}
+	AddDataFuncs(funcMap, d)
+	ns := funcMap["data"].(func() *DataFuncs)()
+
+	in := map[string]interface{}{"name": "web"}
+	out, err := ns.Validate("schema", in)
+	assert.NoError(t, err)
+	assert.Equal(t, in, out)
+	_, err = ns.Validate("schema", map[string]interface{}{})
+	assert.Error(t, err)
+
+	_, err = (&DataFuncs{}).Validate("schema", in)
+	assert.EqualError(t, err, "no datasources are available to validate against")
+}
@@ -0,0 +1,72 @@
+package funcs
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hairyhenderson/gomplate/file"
+)
+
+// AddFileFuncs -
+func AddFileFuncs(f map[string]interface{}, fs *file.FS, w *file.Writer) {
+	// the functions are confined to the given file root and output directory,
+	// so each function map gets its own namespace rather than a singleton
+	ns := &FileFuncs{fs: fs, w: w}
+	f["file"] = func() *FileFuncs { return ns }
+}
+
+// FileFuncs -
+type FileFuncs struct {
+	fs *file.FS
+	w  *file.Writer
+}
+
+// Read -
+func (f *FileFuncs) Read(path string) (string, error) {
+	return f.fs.Read(path)
+}
+
+// Exists -
+func (f *FileFuncs) Exists(path string) bool {
+	return f.fs.Exists(path)
+}
+
+// IsDir -
+func (f *FileFuncs) IsDir(path string) bool {
+	return f.fs.IsDir(path)
+}
+
+// Stat -
+func (f *FileFuncs) Stat(path string) (os.FileInfo, error) {
+	return f.fs.Stat(path)
+}
+
+// ReadDir -
+func (f *FileFuncs) ReadDir(path string) ([]string, error) {
+	return f.fs.ReadDir(path)
+}
+
+// Walk -
+func (f *FileFuncs) Walk(path string) ([]string, error) {
+	return f.fs.Walk(path)
+}
+
+// Glob -
+func (f *FileFuncs) Glob(pattern string) ([]string, error) {
+	return f.fs.Glob(pattern)
+}
+
+// Write - write the content to a file relative to the output directory.
+// Returns an empty string, so nothing is added to the template's output.
+func (f *FileFuncs) Write(path string, content interface{}) (string, error) {
+	var b []byte
+	switch c := content.(type) {
+	case string:
+		b = []byte(c)
+	case []byte:
+		b = c
+	default:
+		b = []byte(fmt.Sprint(c))
+	}
+	return "", f.w.Write(path, b)
+}
@@ -0,0 +1,74 @@
+package funcs
+
+import (
+	"testing"
+
+	"github.com/hairyhenderson/gomplate/file"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileFuncs(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_ = fs.MkdirAll("/tmpl/sub", 0755)
+	_ = afero.WriteFile(fs, "/tmpl/a.txt", []byte("hello"), 0644)
+	_ = afero.WriteFile(fs, "/tmpl/sub/b.txt", []byte("world"), 0644)
+	_ = afero.WriteFile(fs, "/secret", []byte("hunter2"), 0600)
+
+	fsys, err := file.New(fs, "/tmpl")
+	assert.NoError(t, err)
+	f := &FileFuncs{fs: fsys}
+
+	out, err := f.Read("/tmpl/a.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", out)
+	_, err = f.Read("/secret")
+	assert.Error(t, err)
+
+	assert.True(t, f.Exists("/tmpl/sub/b.txt"))
+	assert.False(t, f.Exists("/secret"))
+	assert.True(t, f.IsDir("/tmpl/sub"))
+
+	fi, err := f.Stat("/tmpl/sub/b.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), fi.Size())
+
+	entries, err := f.ReadDir("/tmpl")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "sub"}, entries)
+
+	paths, err := f.Walk("/tmpl")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/tmpl", "/tmpl/a.txt", "/tmpl/sub", "/tmpl/sub/b.txt"}, paths)
+
+	matches, err := f.Glob("/*")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/tmpl"}, matches)
+}
+
+func TestFileWrite(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_ = fs.MkdirAll("/out", 0755)
+	w, err := file.NewWriter(fs, "/out")
+	assert.NoError(t, err)
+	f := &FileFuncs{w: w}
+
+	out, err := f.Write("a/b.yaml", "foo: bar\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "", out)
+	b, _ := afero.ReadFile(fs, "/out/a/b.yaml")
+	assert.Equal(t, "foo: bar\n", string(b))
+
+	_, err = f.Write("c.bin", []byte{1, 2})
+	assert.NoError(t, err)
+	b, _ = afero.ReadFile(fs, "/out/c.bin")
+	assert.Equal(t, []byte{1, 2}, b)
+
+	_, err = f.Write("n.txt", 42)
+	assert.NoError(t, err)
+	b, _ = afero.ReadFile(fs, "/out/n.txt")
+	assert.Equal(t, "42", string(b))
+
+	_, err = f.Write("../escape", "x")
+	assert.Error(t, err)
+}
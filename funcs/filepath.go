@@ -0,0 +1,105 @@
+package funcs
+
+import (
+	"path"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	fpathNS     *FilePathFuncs
+	fpathNSInit sync.Once
+	pathNS      *PathFuncs
+	pathNSInit  sync.Once
+)
+
+// FilePathNS - the filepath namespace
+func FilePathNS() *FilePathFuncs {
+	fpathNSInit.Do(func() { fpathNS = &FilePathFuncs{} })
+	return fpathNS
+}
+
+// PathNS - the path namespace
+func PathNS() *PathFuncs {
+	pathNSInit.Do(func() { pathNS = &PathFuncs{} })
+	return pathNS
+}
+
+// AddFilePathFuncs - adds the filepath and path namespaces
+func AddFilePathFuncs(f map[string]interface{}) {
+	f["filepath"] = FilePathNS
+	f["path"] = PathNS
+}
+
+// FilePathFuncs - wrappers for the standard library's `path/filepath`
+// package, which uses the operating system's path separator
+type FilePathFuncs struct{}
+
+// Base -
+func (f *FilePathFuncs) Base(in string) string {
+	return filepath.Base(in)
+}
+
+// Clean -
+func (f *FilePathFuncs) Clean(in string) string {
+	return filepath.Clean(in)
+}
+
+// Dir -
+func (f *FilePathFuncs) Dir(in string) string {
+	return filepath.Dir(in)
+}
+
+// Ext -
+func (f *FilePathFuncs) Ext(in string) string {
+	return filepath.Ext(in)
+}
+
+// Join -
+func (f *FilePathFuncs) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+// Match -
+func (f *FilePathFuncs) Match(pattern, name string) (bool, error) {
+	return filepath.Match(pattern, name)
+}
+
+// Rel -
+func (f *FilePathFuncs) Rel(basepath, targpath string) (string, error) {
+	return filepath.Rel(basepath, targpath)
+}
+
+// PathFuncs - wrappers for the standard library's `path` package, which
+// always uses forward slashes (for URLs, for example)
+type PathFuncs struct{}
+
+// Base -
+func (f *PathFuncs) Base(in string) string {
+	return path.Base(in)
+}
+
+// Clean -
+func (f *PathFuncs) Clean(in string) string {
+	return path.Clean(in)
+}
+
+// Dir -
+func (f *PathFuncs) Dir(in string) string {
+	return path.Dir(in)
+}
+
+// Ext -
+func (f *PathFuncs) Ext(in string) string {
+	return path.Ext(in)
+}
+
+// Join -
+func (f *PathFuncs) Join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+// Match -
+func (f *PathFuncs) Match(pattern, name string) (bool, error) {
+	return path.Match(pattern, name)
+}
@@ -0,0 +1,41 @@
+package funcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilePathFuncs(t *testing.T) {
+	f := &FilePathFuncs{}
+	assert.Equal(t, "bar.txt", f.Base("/foo/bar.txt"))
+	assert.Equal(t, "/foo", f.Dir("/foo/bar.txt"))
+	assert.Equal(t, ".txt", f.Ext("/foo/bar.txt"))
+	assert.Equal(t, "/foo/baz", f.Clean("/foo/bar/../baz/"))
+	assert.Equal(t, "foo/bar/baz", f.Join("foo", "bar", "baz"))
+
+	out, err := f.Rel("/foo", "/foo/bar/baz")
+	assert.NoError(t, err)
+	assert.Equal(t, "bar/baz", out)
+	_, err = f.Rel("/foo", "bar")
+	assert.Error(t, err)
+
+	m, err := f.Match("*.txt", "bar.txt")
+	assert.NoError(t, err)
+	assert.True(t, m)
+	_, err = f.Match("[", "bar.txt")
+	assert.Error(t, err)
+}
+
+func TestPathFuncs(t *testing.T) {
+	f := &PathFuncs{}
+	assert.Equal(t, "bar", f.Base("https:/example.com/foo/bar"))
+	assert.Equal(t, "/foo", f.Dir("/foo/bar.txt"))
+	assert.Equal(t, ".txt", f.Ext("/foo/bar.txt"))
+	assert.Equal(t, "/foo/baz", f.Clean("/foo/bar/../baz/"))
+	assert.Equal(t, "foo/bar/baz", f.Join("foo", "bar", "baz"))
+
+	m, err := f.Match("/api/*/users", "/api/v1/users")
+	assert.NoError(t, err)
+	assert.True(t, m)
+}
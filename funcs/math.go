@@ -3,6 +3,7 @@ package funcs
 import (
 	"fmt"
 	gmath "math"
+	"strconv"
 	"sync"
 
 	"github.com/hairyhenderson/gomplate/conv"
@@ -32,46 +33,192 @@ func AddMathFuncs(f map[string]interface{}) {
 	f["rem"] = MathNS().Rem
 	f["pow"] = MathNS().Pow
 	f["seq"] = MathNS().Seq
+	f["abs"] = MathNS().Abs
+	f["ceil"] = MathNS().Ceil
+	f["floor"] = MathNS().Floor
+	f["round"] = MathNS().Round
+	f["max"] = MathNS().Max
+	f["min"] = MathNS().Min
+	f["sqrt"] = MathNS().Sqrt
 }
 
 // MathFuncs -
 type MathFuncs struct{}
 
 // Add -
-func (f *MathFuncs) Add(n ...interface{}) int64 {
-	return math.AddInt(conv.ToInt64s(n...)...)
+func (f *MathFuncs) Add(n ...interface{}) interface{} {
+	if math.AllInts(n...) {
+		if x, ok := math.AddInt(conv.ToInt64s(n...)...); ok {
+			return x
+		}
+	}
+	x := 0.0
+	for _, v := range n {
+		x += conv.ToFloat64(v)
+	}
+	return x
 }
 
 // Mul -
-func (f *MathFuncs) Mul(n ...interface{}) int64 {
-	return math.MulInt(conv.ToInt64s(n...)...)
+func (f *MathFuncs) Mul(n ...interface{}) interface{} {
+	if math.AllInts(n...) {
+		if x, ok := math.MulInt(conv.ToInt64s(n...)...); ok {
+			return x
+		}
+	}
+	x := 1.0
+	for _, v := range n {
+		x *= conv.ToFloat64(v)
+	}
+	return x
 }
 
 // Sub -
-func (f *MathFuncs) Sub(a, b interface{}) int64 {
-	return conv.ToInt64(a) - conv.ToInt64(b)
+func (f *MathFuncs) Sub(a, b interface{}) interface{} {
+	if math.AllInts(a, b) {
+		if x, ok := math.SubInt(conv.ToInt64(a), conv.ToInt64(b)); ok {
+			return x
+		}
+	}
+	return conv.ToFloat64(a) - conv.ToFloat64(b)
 }
 
-// Div -
-func (f *MathFuncs) Div(a, b interface{}) (int64, error) {
-	divisor := conv.ToInt64(a)
-	dividend := conv.ToInt64(b)
-	if dividend == 0 {
+// Div - the result is an integer only when both operands are integers and the
+// division is exact
+func (f *MathFuncs) Div(a, b interface{}) (interface{}, error) {
+	divisor := conv.ToFloat64(b)
+	if divisor == 0 {
 		return 0, fmt.Errorf("Error: division by 0")
 	}
-	return divisor / dividend, nil
+	if math.AllInts(a, b) {
+		x, y := conv.ToInt64(a), conv.ToInt64(b)
+		if x%y == 0 && !(x == gmath.MinInt64 && y == -1) {
+			return x / y, nil
+		}
+	}
+	return conv.ToFloat64(a) / divisor, nil
 }
 
 // Rem -
-func (f *MathFuncs) Rem(a, b interface{}) int64 {
-	return conv.ToInt64(a) % conv.ToInt64(b)
+func (f *MathFuncs) Rem(a, b interface{}) interface{} {
+	if math.AllInts(a, b) && conv.ToInt64(b) != 0 {
+		return conv.ToInt64(a) % conv.ToInt64(b)
+	}
+	return gmath.Mod(conv.ToFloat64(a), conv.ToFloat64(b))
 }
 
 // Pow -
-func (f *MathFuncs) Pow(a, b interface{}) int64 {
-	return conv.ToInt64(gmath.Pow(conv.ToFloat64(a), conv.ToFloat64(b)))
+func (f *MathFuncs) Pow(a, b interface{}) interface{} {
+	r := gmath.Pow(conv.ToFloat64(a), conv.ToFloat64(b))
+	if math.AllInts(a, b) && conv.ToInt64(b) >= 0 && gmath.Abs(r) < maxExactInt {
+		return int64(r)
+	}
+	return r
+}
+
+// Abs -
+func (f *MathFuncs) Abs(n interface{}) interface{} {
+	if math.AllInts(n) {
+		if i := conv.ToInt64(n); i >= 0 {
+			return i
+		} else if i != gmath.MinInt64 {
+			return -i
+		}
+	}
+	return gmath.Abs(conv.ToFloat64(n))
 }
 
+// Ceil -
+func (f *MathFuncs) Ceil(n interface{}) float64 {
+	return gmath.Ceil(conv.ToFloat64(n))
+}
+
+// Floor -
+func (f *MathFuncs) Floor(n interface{}) float64 {
+	return gmath.Floor(conv.ToFloat64(n))
+}
+
+// Round - round to the nearest integer, rounding half away from zero
+func (f *MathFuncs) Round(n interface{}) float64 {
+	return gmath.Round(conv.ToFloat64(n))
+}
+
+// Max -
+func (f *MathFuncs) Max(a interface{}, b ...interface{}) interface{} {
+	n := append([]interface{}{a}, b...)
+	if math.AllInts(n...) {
+		m := conv.ToInt64(a)
+		for _, i := range conv.ToInt64s(b...) {
+			if i > m {
+				m = i
+			}
+		}
+		return m
+	}
+	m := conv.ToFloat64(a)
+	for _, v := range b {
+		m = gmath.Max(m, conv.ToFloat64(v))
+	}
+	return m
+}
+
+// Min -
+func (f *MathFuncs) Min(a interface{}, b ...interface{}) interface{} {
+	n := append([]interface{}{a}, b...)
+	if math.AllInts(n...) {
+		m := conv.ToInt64(a)
+		for _, i := range conv.ToInt64s(b...) {
+			if i < m {
+				m = i
+			}
+		}
+		return m
+	}
+	m := conv.ToFloat64(a)
+	for _, v := range b {
+		m = gmath.Min(m, conv.ToFloat64(v))
+	}
+	return m
+}
+
+// Sqrt -
+func (f *MathFuncs) Sqrt(n interface{}) float64 {
+	return gmath.Sqrt(conv.ToFloat64(n))
+}
+
+// IsInt - whether the value is an integer, or a string representing one
+func (f *MathFuncs) IsInt(n interface{}) bool {
+	return math.IsInt(n)
+}
+
+// IsFloat - whether the value is a floating-point number, or a string
+// representing one (including integers too large for 64 bits)
+func (f *MathFuncs) IsFloat(n interface{}) bool {
+	switch n.(type) {
+	case float32, float64:
+		return true
+	case uint, uint64:
+		// too large for an int64
+		return !math.IsInt(n)
+	case string:
+		if math.IsInt(n) {
+			return false
+		}
+		_, err := strconv.ParseFloat(n.(string), 64)
+		return err == nil
+	}
+	return false
+}
+
+// IsNum - whether the value is a number, or a string representing one
+func (f *MathFuncs) IsNum(n interface{}) bool {
+	return f.IsInt(n) || f.IsFloat(n)
+}
+
+// integers with larger magnitudes can't all be represented exactly by a
+// float64
+const maxExactInt = 1 << 53
+
 // Seq - return a sequence from `start` to `end`, in steps of `step`
 // start and step are optional, and default to 1.
 func (f *MathFuncs) Seq(n ...interface{}) ([]int64, error) {
@@ -1,6 +1,7 @@
 package funcs
 
 import (
+	gmath "math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -30,7 +31,7 @@ func TestSub(t *testing.T) {
 	assert.Equal(t, int64(-41), m.Sub(true, "42"))
 }
 
-func mustDiv(a, b interface{}) int64 {
+func mustDiv(a, b interface{}) interface{} {
 	m := MathNS()
 	r, err := m.Div(a, b)
 	if err != nil {
@@ -43,20 +44,90 @@ func TestDiv(t *testing.T) {
 	m := MathNS()
 	_, err := m.Div(1, 0)
 	assert.Error(t, err)
+	_, err = m.Div(1.5, 0.0)
+	assert.Error(t, err)
 	assert.Equal(t, int64(1), mustDiv(1, 1))
 	assert.Equal(t, int64(-1), mustDiv(-5, 5))
-	assert.Equal(t, int64(0), mustDiv(true, "42"))
+	assert.Equal(t, 1.0/42, mustDiv(true, "42"))
+	assert.Equal(t, 3.5, mustDiv(7, 2))
+	assert.Equal(t, 2.5, mustDiv("5", 2.0))
+	assert.Equal(t, 4.0, mustDiv(8.0, 2))
 }
 
 func TestRem(t *testing.T) {
 	m := MathNS()
 	assert.Equal(t, int64(0), m.Rem(1, 1))
-	assert.Equal(t, int64(2), m.Rem(5, 3.0))
+	assert.Equal(t, 2.0, m.Rem(5, 3.0))
+	assert.Equal(t, int64(-2), m.Rem(-5, 3))
+	assert.Equal(t, 1.5, m.Rem(5.5, 2))
 }
 
 func TestPow(t *testing.T) {
 	m := MathNS()
 	assert.Equal(t, int64(4), m.Pow(2, "2"))
+	assert.Equal(t, 0.5, m.Pow(2, -1))
+	assert.Equal(t, 2.25, m.Pow(1.5, 2))
+	assert.Equal(t, gmath.Pow(2, 64), m.Pow(2, 64))
+}
+
+func TestFloatArithmetic(t *testing.T) {
+	m := MathNS()
+	assert.Equal(t, 5.0, m.Add(2.5, 2.5))
+	assert.Equal(t, 4.5, m.Add(2, "2.5"))
+	assert.Equal(t, -0.5, m.Sub(2, 2.5))
+	assert.Equal(t, 7.5, m.Mul(3, 2.5))
+
+	// integer overflow falls back to floating-point
+	assert.Equal(t, float64(gmath.MaxInt64)+1, m.Add(int64(gmath.MaxInt64), 1))
+	assert.Equal(t, float64(gmath.MaxInt64)*2, m.Mul(int64(gmath.MaxInt64), 2))
+	assert.Equal(t, float64(gmath.MinInt64)-1, m.Sub(int64(gmath.MinInt64), 1))
+	assert.Equal(t, 2e19, m.Add("10000000000000000000", "10000000000000000000"))
+	assert.Equal(t, float64(uint64(gmath.MaxUint64)), m.Add(uint64(gmath.MaxUint64)))
+}
+
+func TestAbsCeilFloorRound(t *testing.T) {
+	m := MathNS()
+	assert.Equal(t, int64(5), m.Abs(-5))
+	assert.Equal(t, int64(5), m.Abs("5"))
+	assert.Equal(t, 5.5, m.Abs(-5.5))
+	assert.Equal(t, -float64(gmath.MinInt64), m.Abs(int64(gmath.MinInt64)))
+
+	assert.Equal(t, 3.0, m.Ceil(2.1))
+	assert.Equal(t, -2.0, m.Ceil("-2.9"))
+	assert.Equal(t, 2.0, m.Floor(2.9))
+	assert.Equal(t, -3.0, m.Floor(-2.1))
+	assert.Equal(t, 3.0, m.Round(2.5))
+	assert.Equal(t, -3.0, m.Round(-2.5))
+	assert.Equal(t, 2.0, m.Round("2.49"))
+	assert.Equal(t, 3.0, m.Sqrt(9))
+	assert.True(t, gmath.IsNaN(m.Sqrt(-1)))
+}
+
+func TestMaxMin(t *testing.T) {
+	m := MathNS()
+	assert.Equal(t, int64(10), m.Max(1, "10", 3))
+	assert.Equal(t, 10.5, m.Max(1, 10.5, 3))
+	assert.Equal(t, int64(-1), m.Max(-1))
+	assert.Equal(t, int64(1), m.Min(5, 1, "3"))
+	assert.Equal(t, -0.5, m.Min(5, -0.5))
+}
+
+func TestIsNum(t *testing.T) {
+	m := MathNS()
+	for _, n := range []interface{}{1, int8(1), uint64(1), "1", "-42", "0x10"} {
+		assert.True(t, m.IsInt(n), "%#v", n)
+		assert.False(t, m.IsFloat(n), "%#v", n)
+		assert.True(t, m.IsNum(n), "%#v", n)
+	}
+	for _, n := range []interface{}{1.0, float32(1.5), "1.5", "1e3", "18446744073709551616", uint64(gmath.MaxUint64)} {
+		assert.False(t, m.IsInt(n), "%#v", n)
+		assert.True(t, m.IsNum(n), "%#v", n)
+	}
+	for _, n := range []interface{}{nil, "", "foo", true, []int{1}, "1.2.3", " 7 "} {
+		assert.False(t, m.IsInt(n), "%#v", n)
+		assert.False(t, m.IsFloat(n), "%#v", n)
+		assert.False(t, m.IsNum(n), "%#v", n)
+	}
 }
 
 func mustSeq(n ...interface{}) []int64 {
@@ -0,0 +1,130 @@
+package funcs
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/hairyhenderson/gomplate/conv"
+	"github.com/hairyhenderson/gomplate/random"
+)
+
+var (
+	randomNS     *RandomFuncs
+	randomNSInit sync.Once
+)
+
+// RandomNS -
+func RandomNS() *RandomFuncs {
+	randomNSInit.Do(func() { randomNS = &RandomFuncs{} })
+	return randomNS
+}
+
+// AddRandomFuncs -
+func AddRandomFuncs(f map[string]interface{}) {
+	f["random"] = RandomNS
+}
+
+// RandomFuncs -
+type RandomFuncs struct{}
+
+// ASCII - a random string of printable ASCII characters
+func (f *RandomFuncs) ASCII(count interface{}) (string, error) {
+	return random.StringRE(conv.ToInt(count), random.ASCIIClass)
+}
+
+// Alpha - a random string of ASCII letters
+func (f *RandomFuncs) Alpha(count interface{}) (string, error) {
+	return random.StringRE(conv.ToInt(count), random.AlphaClass)
+}
+
+// AlphaNum - a random string of ASCII letters and digits
+func (f *RandomFuncs) AlphaNum(count interface{}) (string, error) {
+	return random.StringRE(conv.ToInt(count), random.AlphaNumClass)
+}
+
+// String - a random string. Arguments are count [class] or count lower upper,
+// where class is a regexp character class, and lower and upper are bounding
+// characters (or code points).
+func (f *RandomFuncs) String(count interface{}, args ...interface{}) (string, error) {
+	c := conv.ToInt(count)
+	switch len(args) {
+	case 0:
+		return random.StringRE(c, random.DefaultClass)
+	case 1:
+		class, ok := args[0].(string)
+		if !ok {
+			return "", fmt.Errorf("String: expected a character class, got %T", args[0])
+		}
+		return random.StringRE(c, class)
+	case 2:
+		lower, err := toRune(args[0])
+		if err != nil {
+			return "", err
+		}
+		upper, err := toRune(args[1])
+		if err != nil {
+			return "", err
+		}
+		return random.StringBounds(c, lower, upper)
+	}
+	return "", fmt.Errorf("String: expected 1, 2 or 3 arguments, got %d", len(args)+1)
+}
+
+// toRune - a single-character string, or a number as a code point
+func toRune(in interface{}) (rune, error) {
+	if s, ok := in.(string); ok {
+		if utf8.RuneCountInString(s) != 1 {
+			return 0, fmt.Errorf("String: bound %q must be a single character", s)
+		}
+		r, _ := utf8.DecodeRuneInString(s)
+		return r, nil
+	}
+	return rune(conv.ToInt64(in)), nil
+}
+
+// Item - pick an element from the list at random
+func (f *RandomFuncs) Item(items interface{}) (interface{}, error) {
+	v := reflect.ValueOf(items)
+	if items == nil || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+		return nil, fmt.Errorf("Item: expected a list, got %T", items)
+	}
+	l := make([]interface{}, v.Len())
+	for i := range l {
+		l[i] = v.Index(i).Interface()
+	}
+	return random.Item(l)
+}
+
+// Number - a random integer. Arguments are [min] [max], defaulting to 0 and
+// 100 (inclusive).
+func (f *RandomFuncs) Number(args ...interface{}) (int64, error) {
+	min, max := int64(0), int64(100)
+	switch len(args) {
+	case 0:
+	case 1:
+		max = conv.ToInt64(args[0])
+	case 2:
+		min, max = conv.ToInt64(args[0]), conv.ToInt64(args[1])
+	default:
+		return 0, fmt.Errorf("Number: expected 0, 1 or 2 arguments, got %d", len(args))
+	}
+	return random.Number(min, max)
+}
+
+// Float - a random floating-point number. Arguments are [min] [max],
+// defaulting to 0 and 1 (exclusive).
+func (f *RandomFuncs) Float(args ...interface{}) (float64, error) {
+	min, max := 0.0, 1.0
+	switch len(args) {
+	case 0:
+	case 1:
+		max = conv.ToFloat64(args[0])
+	case 2:
+		min, max = conv.ToFloat64(args[0]), conv.ToFloat64(args[1])
+	default:
+		return 0, fmt.Errorf("Float: expected 0, 1 or 2 arguments, got %d", len(args))
+	}
+	return random.Float(min, max)
+}
@@ -0,0 +1,96 @@
+package funcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomASCII(t *testing.T) {
+	f := &RandomFuncs{}
+	out, err := f.ASCII(12)
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[ -~]{12}$`, out)
+
+	out, err = f.Alpha("8")
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[a-zA-Z]{8}$`, out)
+
+	out, err = f.AlphaNum(8)
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[a-zA-Z0-9]{8}$`, out)
+}
+
+func TestRandomString(t *testing.T) {
+	f := &RandomFuncs{}
+	out, err := f.String(10)
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[a-zA-Z0-9_.-]{10}$`, out)
+
+	out, err = f.String(10, `[[:upper:]]`)
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[A-Z]{10}$`, out)
+
+	out, err = f.String(10, "a", "f")
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[a-f]{10}$`, out)
+
+	out, err = f.String(5, 0x30, 0x39)
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[0-9]{5}$`, out)
+
+	_, err = f.String(5, "ab", "z")
+	assert.Error(t, err)
+	_, err = f.String(5, 42)
+	assert.Error(t, err)
+	_, err = f.String(5, "a", "b", "c")
+	assert.Error(t, err)
+}
+
+func TestRandomItem(t *testing.T) {
+	f := &RandomFuncs{}
+	out, err := f.Item([]string{"foo", "bar"})
+	assert.NoError(t, err)
+	assert.Contains(t, []interface{}{"foo", "bar"}, out)
+
+	_, err = f.Item([]interface{}{})
+	assert.Error(t, err)
+	_, err = f.Item("foo")
+	assert.Error(t, err)
+	_, err = f.Item(nil)
+	assert.Error(t, err)
+}
+
+func TestRandomNumber(t *testing.T) {
+	f := &RandomFuncs{}
+	out, err := f.Number()
+	assert.NoError(t, err)
+	assert.True(t, out >= 0 && out <= 100)
+
+	out, err = f.Number(5)
+	assert.NoError(t, err)
+	assert.True(t, out >= 0 && out <= 5)
+
+	out, err = f.Number("-10", -5)
+	assert.NoError(t, err)
+	assert.True(t, out >= -10 && out <= -5)
+
+	_, err = f.Number(10, 1)
+	assert.Error(t, err)
+	_, err = f.Number(1, 2, 3)
+	assert.Error(t, err)
+}
+
+func TestRandomFloat(t *testing.T) {
+	f := &RandomFuncs{}
+	out, err := f.Float()
+	assert.NoError(t, err)
+	assert.True(t, out >= 0 && out < 1)
+
+	out, err = f.Float(0.5, 1.5)
+	assert.NoError(t, err)
+	assert.True(t, out >= 0.5 && out < 1.5)
+
+	_, err = f.Float(1, 2, 3)
+	assert.Error(t, err)
+}
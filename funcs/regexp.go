@@ -1,8 +1,10 @@
 package funcs
 
 import (
+	"fmt"
 	"sync"
 
+	"github.com/hairyhenderson/gomplate/conv"
 	"github.com/hairyhenderson/gomplate/regexp"
 )
 
@@ -26,11 +28,77 @@ func AddReFuncs(f map[string]interface{}) {
 type ReFuncs struct{}
 
 // Replace -
-func (f *ReFuncs) Replace(re, replacement, input string) string {
+func (f *ReFuncs) Replace(re, replacement, input string) (string, error) {
 	return regexp.Replace(re, replacement, input)
 }
 
+// ReplaceLiteral -
+func (f *ReFuncs) ReplaceLiteral(re, replacement, input string) (string, error) {
+	return regexp.ReplaceLiteral(re, replacement, input)
+}
+
 // Match -
-func (f *ReFuncs) Match(re, input string) bool {
+func (f *ReFuncs) Match(re, input string) (bool, error) {
 	return regexp.Match(re, input)
 }
+
+// Find -
+func (f *ReFuncs) Find(re, input string) (string, error) {
+	return regexp.Find(re, input)
+}
+
+// FindAll - arguments are re [n] input
+func (f *ReFuncs) FindAll(args ...interface{}) ([]string, error) {
+	re, n, input, err := reLimitArgs("FindAll", args)
+	if err != nil {
+		return nil, err
+	}
+	return regexp.FindAll(re, n, input)
+}
+
+// FindSubmatch - return the named capture groups of the first match as a map
+func (f *ReFuncs) FindSubmatch(re, input string) (map[string]interface{}, error) {
+	groups, _, err := regexp.FindSubmatch(re, input)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]interface{}, len(groups))
+	for k, v := range groups {
+		out[k] = v
+	}
+	return out, nil
+}
+
+// Split - arguments are re [n] input
+func (f *ReFuncs) Split(args ...interface{}) ([]string, error) {
+	re, n, input, err := reLimitArgs("Split", args)
+	if err != nil {
+		return nil, err
+	}
+	return regexp.Split(re, n, input)
+}
+
+// QuoteMeta -
+func (f *ReFuncs) QuoteMeta(input string) string {
+	return regexp.QuoteMeta(input)
+}
+
+// reLimitArgs - parse the re [n] input arguments common to FindAll and Split.
+// When n is omitted it defaults to -1 (no limit).
+func reLimitArgs(name string, args []interface{}) (re string, n int, input string, err error) {
+	n = -1
+	switch len(args) {
+	case 2:
+		input = fmt.Sprint(args[1])
+	case 3:
+		n = conv.ToInt(args[1])
+		input = fmt.Sprint(args[2])
+	default:
+		return "", 0, "", fmt.Errorf("%s: expected 2 or 3 arguments, got %d", name, len(args))
+	}
+	re, ok := args[0].(string)
+	if !ok {
+		return "", 0, "", fmt.Errorf("%s: expected a string expression, got %T", name, args[0])
+	}
+	return re, n, input, nil
+}
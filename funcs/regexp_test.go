@@ -8,10 +8,76 @@ import (
 
 func TestReplace(t *testing.T) {
 	re := &ReFuncs{}
-	assert.Equal(t, "hello world", re.Replace("i", "ello", "hi world"))
+	out, err := re.Replace("i", "ello", "hi world")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", out)
+
+	_, err = re.Replace("(", "", "hi world")
+	assert.Error(t, err)
+}
+
+func TestReplaceLiteral(t *testing.T) {
+	re := &ReFuncs{}
+	out, err := re.ReplaceLiteral(`\d+`, "$1", "port 8080")
+	assert.NoError(t, err)
+	assert.Equal(t, "port $1", out)
 }
 
 func TestMatch(t *testing.T) {
 	re := &ReFuncs{}
-	assert.True(t, re.Match(`i\ `, "hi world"))
+	out, err := re.Match(`i\ `, "hi world")
+	assert.NoError(t, err)
+	assert.True(t, out)
+
+	_, err = re.Match(`[`, "hi world")
+	assert.Error(t, err)
+}
+
+func TestFind(t *testing.T) {
+	re := &ReFuncs{}
+	out, err := re.Find(`v\d+`, "release v12 and v13")
+	assert.NoError(t, err)
+	assert.Equal(t, "v12", out)
+}
+
+func TestFindAll(t *testing.T) {
+	re := &ReFuncs{}
+	out, err := re.FindAll(`v\d+`, "release v12 and v13")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v12", "v13"}, out)
+
+	out, err = re.FindAll(`v\d+`, 1, "release v12 and v13")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v12"}, out)
+
+	_, err = re.FindAll(`v\d+`)
+	assert.Error(t, err)
+	_, err = re.FindAll(42, "foo")
+	assert.Error(t, err)
+}
+
+func TestFindSubmatch(t *testing.T) {
+	re := &ReFuncs{}
+	out, err := re.FindSubmatch(`(?P<user>\w+)@(?P<host>[\w.]+)`, "mail jo@example.com now")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"user": "jo", "host": "example.com"}, out)
+
+	_, err = re.FindSubmatch(`(?P<user`, "foo")
+	assert.Error(t, err)
+}
+
+func TestSplit(t *testing.T) {
+	re := &ReFuncs{}
+	out, err := re.Split(`[,;]\s*`, "a, b;c")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, out)
+
+	out, err = re.Split(`[,;]\s*`, 2, "a, b;c")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b;c"}, out)
+}
+
+func TestQuoteMeta(t *testing.T) {
+	re := &ReFuncs{}
+	assert.Equal(t, `example\.com`, re.QuoteMeta("example.com"))
 }
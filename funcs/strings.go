@@ -6,11 +6,16 @@ package funcs
 // in templates easier.
 
 import (
+	"fmt"
 	"log"
+	"reflect"
+	"sort"
 	"sync"
+	"unicode/utf8"
 
 	"strings"
 
+	"github.com/hairyhenderson/gomplate/conv"
 	gompstrings "github.com/hairyhenderson/gomplate/strings"
 )
 
@@ -35,6 +40,9 @@ func AddStringFuncs(f map[string]interface{}) {
 	f["toLower"] = StrNS().ToLower
 	f["trimSpace"] = StrNS().TrimSpace
 	f["indent"] = StrNS().Indent
+	f["quote"] = StrNS().Quote
+	f["squote"] = StrNS().Squote
+	f["shellQuote"] = StrNS().ShellQuote
 
 	// these are legacy aliases with non-pipelinable arg order
 	f["contains"] = strings.Contains
@@ -133,3 +141,168 @@ func (f *StringFuncs) Indent(args ...interface{}) string {
 	}
 	return gompstrings.Indent(width, indent, input)
 }
+
+// TrimPrefix -
+func (f *StringFuncs) TrimPrefix(prefix, s string) string {
+	return strings.TrimPrefix(s, prefix)
+}
+
+// TrimSuffix -
+func (f *StringFuncs) TrimSuffix(suffix, s string) string {
+	return strings.TrimSuffix(s, suffix)
+}
+
+// Repeat -
+func (f *StringFuncs) Repeat(count interface{}, s string) (string, error) {
+	n := conv.ToInt(count)
+	if n < 0 {
+		return "", fmt.Errorf("Repeat: negative count %d", n)
+	}
+	if n > 0 && len(s)*n/n != len(s) {
+		return "", fmt.Errorf("Repeat: count %d is too large", n)
+	}
+	return strings.Repeat(s, n), nil
+}
+
+// Sort - return a sorted copy of the given list of strings
+func (f *StringFuncs) Sort(list interface{}) ([]string, error) {
+	switch l := list.(type) {
+	case []string:
+		out := make([]string, len(l))
+		copy(out, l)
+		sort.Strings(out)
+		return out, nil
+	case []interface{}:
+		out := make([]string, len(l))
+		for i, v := range l {
+			out[i] = fmt.Sprint(v)
+		}
+		sort.Strings(out)
+		return out, nil
+	}
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("Sort: expected a list, got %T", list)
+	}
+	out := make([]string, v.Len())
+	for i := range out {
+		out[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// Truncate - shorten the string to at most length characters
+func (f *StringFuncs) Truncate(length interface{}, s string) string {
+	return gompstrings.Truncate(conv.ToInt(length), s)
+}
+
+// Abbrev - abbreviate the string with an ellipsis ("..."). Arguments are
+// [offset] width input.
+func (f *StringFuncs) Abbrev(args ...interface{}) (string, error) {
+	offset := 0
+	var width int
+	var input string
+	switch len(args) {
+	case 2:
+		width = conv.ToInt(args[0])
+		input = fmt.Sprint(args[1])
+	case 3:
+		offset = conv.ToInt(args[0])
+		width = conv.ToInt(args[1])
+		input = fmt.Sprint(args[2])
+	default:
+		return "", fmt.Errorf("Abbrev: expected 2 or 3 arguments, got %d", len(args))
+	}
+	out, err := gompstrings.Abbrev(offset, width, input)
+	if err != nil {
+		return "", fmt.Errorf("Abbrev: %v", err)
+	}
+	return out, nil
+}
+
+// WordWrap - wrap the input on whitespace. Arguments are [width] [lbseq]
+// input, where width defaults to 80 and lbseq (the line-break sequence)
+// defaults to "\n".
+func (f *StringFuncs) WordWrap(args ...interface{}) (string, error) {
+	if len(args) == 0 || len(args) > 3 {
+		return "", fmt.Errorf("WordWrap: expected 1, 2 or 3 arguments, got %d", len(args))
+	}
+	input := fmt.Sprint(args[len(args)-1])
+	width := 80
+	lbseq := "\n"
+	switch len(args) {
+	case 2:
+		if l, ok := args[0].(string); ok {
+			lbseq = l
+		} else {
+			width = conv.ToInt(args[0])
+		}
+	case 3:
+		width = conv.ToInt(args[0])
+		lbseq = fmt.Sprint(args[1])
+	}
+	if width < 1 {
+		return "", fmt.Errorf("WordWrap: width must be positive, got %d", width)
+	}
+	return gompstrings.WordWrap(width, lbseq, input), nil
+}
+
+// Slug -
+func (f *StringFuncs) Slug(in interface{}) string {
+	return gompstrings.Slug(fmt.Sprint(in))
+}
+
+// CamelCase -
+func (f *StringFuncs) CamelCase(in interface{}) string {
+	return gompstrings.CamelCase(fmt.Sprint(in))
+}
+
+// SnakeCase -
+func (f *StringFuncs) SnakeCase(in interface{}) string {
+	return gompstrings.SnakeCase(fmt.Sprint(in))
+}
+
+// KebabCase -
+func (f *StringFuncs) KebabCase(in interface{}) string {
+	return gompstrings.KebabCase(fmt.Sprint(in))
+}
+
+// Quote - surround the input with double quotes, escaping as necessary
+func (f *StringFuncs) Quote(in interface{}) string {
+	return fmt.Sprintf("%q", fmt.Sprint(in))
+}
+
+// Squote - surround the input with single quotes, doubling any single quotes
+// inside (as in YAML)
+func (f *StringFuncs) Squote(in interface{}) string {
+	return "'" + strings.Replace(fmt.Sprint(in), "'", "''", -1) + "'"
+}
+
+// ShellQuote - quote the input for safe use in a POSIX shell command. Lists
+// are quoted element-by-element and joined with spaces.
+func (f *StringFuncs) ShellQuote(in interface{}) string {
+	v := reflect.ValueOf(in)
+	if in != nil && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) {
+		words := make([]string, v.Len())
+		for i := range words {
+			words[i] = gompstrings.ShellQuote(fmt.Sprint(v.Index(i).Interface()))
+		}
+		return strings.Join(words, " ")
+	}
+	return gompstrings.ShellQuote(fmt.Sprint(in))
+}
+
+// Sprintf -
+func (f *StringFuncs) Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(format, args...)
+}
+
+// RuneCount - the number of characters (runes) in the input(s)
+func (f *StringFuncs) RuneCount(args ...interface{}) int {
+	n := 0
+	for _, a := range args {
+		n += utf8.RuneCountInString(fmt.Sprint(a))
+	}
+	return n
+}
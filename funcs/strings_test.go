@@ -1,6 +1,7 @@
 package funcs
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -22,3 +23,118 @@ func TestIndent(t *testing.T) {
 	assert.Equal(t, "---foo\n---bar\n---baz", sf.Indent(3, "-", "foo\nbar\nbaz"))
 	assert.Equal(t, "   foo\n   bar\n   baz", sf.Indent(3, "foo\nbar\nbaz"))
 }
+
+func TestTrimPrefixSuffix(t *testing.T) {
+	sf := &StringFuncs{}
+	assert.Equal(t, "bar", sf.TrimPrefix("foo", "foobar"))
+	assert.Equal(t, "foobar", sf.TrimPrefix("bar", "foobar"))
+	assert.Equal(t, "foo", sf.TrimSuffix("bar", "foobar"))
+	assert.Equal(t, "foobar", sf.TrimSuffix("foo", "foobar"))
+}
+
+func TestRepeat(t *testing.T) {
+	sf := &StringFuncs{}
+	out, err := sf.Repeat(3, "ab")
+	assert.NoError(t, err)
+	assert.Equal(t, "ababab", out)
+	out, err = sf.Repeat("2", "-")
+	assert.NoError(t, err)
+	assert.Equal(t, "--", out)
+	out, err = sf.Repeat(0, "ab")
+	assert.NoError(t, err)
+	assert.Equal(t, "", out)
+	_, err = sf.Repeat(-1, "ab")
+	assert.Error(t, err)
+	_, err = sf.Repeat(math.MaxInt64, "ab")
+	assert.Error(t, err)
+}
+
+func TestSort(t *testing.T) {
+	sf := &StringFuncs{}
+	in := []string{"c", "a", "b"}
+	out, err := sf.Sort(in)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, out)
+	assert.Equal(t, []string{"c", "a", "b"}, in)
+	out, err = sf.Sort([]interface{}{"foo", "bar", 42})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"42", "bar", "foo"}, out)
+	out, err = sf.Sort([2]string{"b", "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, out)
+	_, err = sf.Sort("foo")
+	assert.Error(t, err)
+}
+
+func TestTruncateAbbrev(t *testing.T) {
+	sf := &StringFuncs{}
+	assert.Equal(t, "hello", sf.Truncate(5, "hello world"))
+	assert.Equal(t, "hello", sf.Truncate("5", "hello world"))
+
+	out, err := sf.Abbrev(8, "hello world")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello...", out)
+	out, err = sf.Abbrev(6, 9, "hello world, goodbye")
+	assert.NoError(t, err)
+	assert.Equal(t, "...wor...", out)
+	_, err = sf.Abbrev(3, "hello world")
+	assert.Error(t, err)
+	_, err = sf.Abbrev(6, 6, "hello world")
+	assert.Error(t, err)
+	_, err = sf.Abbrev("hello world")
+	assert.Error(t, err)
+}
+
+func TestWordWrap(t *testing.T) {
+	sf := &StringFuncs{}
+	in := "the quick brown fox jumps over the lazy dog"
+	out, err := sf.WordWrap(in)
+	assert.NoError(t, err)
+	assert.Equal(t, in, out)
+	out, err = sf.WordWrap(20, in)
+	assert.NoError(t, err)
+	assert.Equal(t, "the quick brown fox\njumps over the lazy\ndog", out)
+	out, err = sf.WordWrap(20, "<br>", in)
+	assert.NoError(t, err)
+	assert.Equal(t, "the quick brown fox<br>jumps over the lazy<br>dog", out)
+	out, err = sf.WordWrap(" \\\n", "the quick brown fox")
+	assert.NoError(t, err)
+	assert.Equal(t, "the quick brown fox", out)
+	_, err = sf.WordWrap(0, in)
+	assert.Error(t, err)
+	_, err = sf.WordWrap()
+	assert.Error(t, err)
+}
+
+func TestCaseFuncs(t *testing.T) {
+	sf := &StringFuncs{}
+	assert.Equal(t, "hello-world", sf.Slug("Hello, World!"))
+	assert.Equal(t, "helloWorld", sf.CamelCase("Hello World"))
+	assert.Equal(t, "hello_world", sf.SnakeCase("HelloWorld"))
+	assert.Equal(t, "hello-world", sf.KebabCase("hello_world"))
+	assert.Equal(t, "42", sf.Slug(42))
+}
+
+func TestQuote(t *testing.T) {
+	sf := &StringFuncs{}
+	assert.Equal(t, `"foo"`, sf.Quote("foo"))
+	assert.Equal(t, `"say \"hi\"\n"`, sf.Quote("say \"hi\"\n"))
+	assert.Equal(t, `"42"`, sf.Quote(42))
+
+	assert.Equal(t, `'foo'`, sf.Squote("foo"))
+	assert.Equal(t, `'it''s'`, sf.Squote("it's"))
+
+	assert.Equal(t, `'foo bar'`, sf.ShellQuote("foo bar"))
+	assert.Equal(t, `'it'"'"'s'`, sf.ShellQuote("it's"))
+	assert.Equal(t, `'a b' 'c' '1'`, sf.ShellQuote([]interface{}{"a b", "c", 1}))
+	assert.Equal(t, `'x' 'y'`, sf.ShellQuote([]string{"x", "y"}))
+}
+
+func TestSprintfRuneCount(t *testing.T) {
+	sf := &StringFuncs{}
+	assert.Equal(t, "foo-42", sf.Sprintf("%s-%d", "foo", 42))
+	assert.Equal(t, 0, sf.RuneCount())
+	assert.Equal(t, 5, sf.RuneCount("hello"))
+	assert.Equal(t, 3, sf.RuneCount("日本語"))
+	assert.Equal(t, 5, sf.RuneCount("foo", 42))
+}
@@ -0,0 +1,86 @@
+package funcs
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// maxTmplDepth - the maximum nesting of tmpl.Exec and tmpl.Inline calls, to
+// catch templates which (indirectly) render themselves
+const maxTmplDepth = 100
+
+// AddTmplFuncs - adds the tmpl namespace for the given template. Unlike most
+// namespaces, this one holds state specific to each template, so it must be
+// added to a separate function map for each.
+func AddTmplFuncs(f map[string]interface{}, root *template.Template, name, path string, ctx interface{}) {
+	t := &TemplateFuncs{root: root, name: name, path: path, ctx: ctx}
+	f["tmpl"] = func() *TemplateFuncs { return t }
+}
+
+// TemplateFuncs -
+type TemplateFuncs struct {
+	root  *template.Template
+	name  string
+	path  string
+	ctx   interface{}
+	depth int
+}
+
+// Exec - render the named template (defined with `define` or `block`),
+// returning the output as a string. The context defaults to the main
+// template's context.
+func (f *TemplateFuncs) Exec(name string, ctx ...interface{}) (string, error) {
+	t := f.root.Lookup(name)
+	if t == nil {
+		return "", fmt.Errorf("Exec: template %q not defined", name)
+	}
+	return f.render("Exec", t, ctx)
+}
+
+// Inline - parse and render the given template text, returning the output as
+// a string. The same functions, delimiters and defined templates are
+// available as in the main template. The context defaults to the main
+// template's context.
+func (f *TemplateFuncs) Inline(text string, ctx ...interface{}) (string, error) {
+	t, err := f.root.New("<inline>").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	return f.render("Inline", t, ctx)
+}
+
+func (f *TemplateFuncs) render(fn string, t *template.Template, ctx []interface{}) (string, error) {
+	var c interface{}
+	switch len(ctx) {
+	case 0:
+		c = f.ctx
+	case 1:
+		c = ctx[0]
+	default:
+		return "", fmt.Errorf("%s: expected at most one context argument, got %d", fn, len(ctx))
+	}
+	if f.depth >= maxTmplDepth {
+		return "", fmt.Errorf("%s: templates nested too deeply (more than %d levels)", fn, maxTmplDepth)
+	}
+	f.depth++
+	defer func() { f.depth-- }()
+
+	out := &bytes.Buffer{}
+	if err := t.Execute(out, c); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// Path - the path of the template file being rendered, or an empty string
+// when the template wasn't read from a file
+func (f *TemplateFuncs) Path() string {
+	return f.path
+}
+
+// Name - the name of the template being rendered: its path, or `<arg>` for
+// templates given with --in
+func (f *TemplateFuncs) Name() string {
+	return f.name
+}
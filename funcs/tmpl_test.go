@@ -0,0 +1,62 @@
+package funcs
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTemplate(t *testing.T, text string, ctx interface{}) (string, error) {
+	root := template.New("root")
+	f := template.FuncMap{}
+	AddTmplFuncs(f, root, "/tmp/root.tmpl", "/tmp/root.tmpl", ctx)
+	root.Funcs(f)
+	_, err := root.Parse(text)
+	assert.NoError(t, err)
+	out := &bytes.Buffer{}
+	err = root.Execute(out, ctx)
+	return out.String(), err
+}
+
+func TestTmplExec(t *testing.T) {
+	out, err := testTemplate(t, `{{ define "T" }}hello, {{ . }}{{ end }}[{{ tmpl.Exec "T" "world" }}]`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "[hello, world]", out)
+
+	out, err = testTemplate(t, `{{ define "T" }}hello, {{ . }}{{ end }}{{ $s := tmpl.Exec "T" }}{{ len $s }}`, "ctx")
+	assert.NoError(t, err)
+	assert.Equal(t, "10", out)
+
+	_, err = testTemplate(t, `{{ tmpl.Exec "missing" }}`, nil)
+	assert.Error(t, err)
+	_, err = testTemplate(t, `{{ define "T" }}{{ end }}{{ tmpl.Exec "T" 1 2 }}`, nil)
+	assert.Error(t, err)
+	_, err = testTemplate(t, `{{ define "T" }}{{ tmpl.Exec "T" }}{{ end }}{{ tmpl.Exec "T" }}`, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "nested too deeply")
+}
+
+func TestTmplInline(t *testing.T) {
+	out, err := testTemplate(t, `{{ tmpl.Inline "{{ . }}!" "hi" }}`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "hi!", out)
+
+	out, err = testTemplate(t, `{{ define "T" }}T{{ end }}{{ tmpl.Inline "{{ template \"T\" }}{{ . }}" }}`, "ctx")
+	assert.NoError(t, err)
+	assert.Equal(t, "Tctx", out)
+
+	out, err = testTemplate(t, `{{ tmpl.Inline "{{ tmpl.Name }}" }}`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/root.tmpl", out)
+
+	_, err = testTemplate(t, `{{ tmpl.Inline "{{ " }}`, nil)
+	assert.Error(t, err)
+}
+
+func TestTmplPathName(t *testing.T) {
+	out, err := testTemplate(t, `{{ tmpl.Path }} {{ tmpl.Name }}`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/root.tmpl /tmp/root.tmpl", out)
+}
@@ -0,0 +1,61 @@
+package funcs
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hairyhenderson/gomplate/uuid"
+)
+
+var (
+	uuidNS     *UUIDFuncs
+	uuidNSInit sync.Once
+)
+
+// UUIDNS -
+func UUIDNS() *UUIDFuncs {
+	uuidNSInit.Do(func() { uuidNS = &UUIDFuncs{} })
+	return uuidNS
+}
+
+// AddUUIDFuncs -
+func AddUUIDFuncs(f map[string]interface{}) {
+	f["uuid"] = UUIDNS
+}
+
+// UUIDFuncs -
+type UUIDFuncs struct{}
+
+// V1 - a time-based UUID
+func (f *UUIDFuncs) V1() (string, error) {
+	u, err := uuid.V1()
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// V4 - a random UUID
+func (f *UUIDFuncs) V4() (string, error) {
+	u, err := uuid.V4()
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// Nil - the nil UUID (all zeroes)
+func (f *UUIDFuncs) Nil() string {
+	return uuid.Nil.String()
+}
+
+// IsValid -
+func (f *UUIDFuncs) IsValid(in interface{}) bool {
+	return uuid.IsValid(fmt.Sprint(in))
+}
+
+// Parse - parse the UUID, returning a value with String, Version and
+// Variant methods
+func (f *UUIDFuncs) Parse(in interface{}) (uuid.UUID, error) {
+	return uuid.Parse(fmt.Sprint(in))
+}
@@ -0,0 +1,30 @@
+package funcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUUID(t *testing.T) {
+	f := &UUIDFuncs{}
+	out, err := f.V1()
+	assert.NoError(t, err)
+	assert.True(t, f.IsValid(out))
+
+	out, err = f.V4()
+	assert.NoError(t, err)
+	assert.True(t, f.IsValid(out))
+
+	assert.Equal(t, "00000000-0000-0000-0000-000000000000", f.Nil())
+	assert.False(t, f.IsValid("foo"))
+	assert.False(t, f.IsValid(nil))
+
+	u, err := f.Parse("urn:uuid:6BA7B810-9DAD-11D1-80B4-00C04FD430C8")
+	assert.NoError(t, err)
+	assert.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", u.String())
+	assert.Equal(t, 1, u.Version())
+
+	_, err = f.Parse(42)
+	assert.Error(t, err)
+}
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
 	"io"
+	"strconv"
 	"text/template"
 
 	"github.com/hairyhenderson/gomplate/data"
+	"github.com/hairyhenderson/gomplate/file"
+	"github.com/hairyhenderson/gomplate/random"
 )
 
 // Gomplate -
@@ -12,12 +17,30 @@ type Gomplate struct {
 	funcMap    template.FuncMap
 	leftDelim  string
 	rightDelim string
+	missingKey string
+	schema     *data.Schema
+	context    Context
+	data       *data.Data
 }
 
 // RunTemplate -
 func (g *Gomplate) RunTemplate(t *tplate) error {
-	context := &Context{}
-	tmpl, err := t.toGoTemplate(g)
+	// datasources defined in front matter are only available to the template
+	// defining them
+	var d *data.Data
+	if fm := t.frontMatter; fm != nil && len(fm.Datasources) > 0 {
+		if g.data == nil {
+			return fmt.Errorf("invalid front matter in %s: datasources can't be defined here", t.name)
+		}
+		var err error
+		d, err = g.data.WithDatasources(fm.Datasources)
+		if err != nil {
+			return fmt.Errorf("invalid front matter in %s: %v", t.name, err)
+		}
+		defer d.Cleanup()
+	}
+	context := g.context.forTemplate(t)
+	tmpl, err := t.toGoTemplate(g, d, context)
 	if err != nil {
 		return err
 	}
@@ -27,27 +50,92 @@ func (g *Gomplate) RunTemplate(t *tplate) error {
 		// nolint: errcheck
 		defer t.target.(io.Closer).Close()
 	}
-	err = tmpl.Execute(t.target, context)
+	_, rightDelim := t.delims(g)
+	if g.schema == nil {
+		if err = t.truncateTarget(); err != nil {
+			return err
+		}
+		if err = tmpl.Execute(t.target, context); err != nil {
+			return newExecError(t, tmpl, rightDelim, err)
+		}
+		return nil
+	}
+
+	// output is only written once it's known to be valid
+	out := &bytes.Buffer{}
+	if err = tmpl.Execute(out, context); err != nil {
+		return newExecError(t, tmpl, rightDelim, err)
+	}
+	if err = g.schema.ValidateDocument(out.String()); err != nil {
+		return fmt.Errorf("output of %s is invalid: %v", t.name, err)
+	}
+	if err = t.truncateTarget(); err != nil {
+		return err
+	}
+	_, err = out.WriteTo(t.target)
 	return err
 }
 
+// missingKeyMode - how templates handle missing map keys: error (the
+// default), zero, or default, as for text/template's missingkey option
+func (g *Gomplate) missingKeyMode() string {
+	if g.missingKey == "" {
+		return "error"
+	}
+	return g.missingKey
+}
+
 // NewGomplate -
-func NewGomplate(d *data.Data, leftDelim, rightDelim string) *Gomplate {
+func NewGomplate(d *data.Data, fsys *file.FS, w *file.Writer, leftDelim, rightDelim string) *Gomplate {
 	return &Gomplate{
 		leftDelim:  leftDelim,
 		rightDelim: rightDelim,
-		funcMap:    initFuncs(d),
+		funcMap:    initFuncs(d, fsys, w),
+		data:       d,
 	}
 }
 
 func runTemplate(o *GomplateOpts) error {
 	defer runCleanupHooks()
-	d := data.NewData(o.dataSources, o.dataSourceHeaders)
+	if o.seed != "" {
+		seed, err := strconv.ParseInt(o.seed, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid seed %q: must be an integer", o.seed)
+		}
+		random.Seed(seed)
+	}
+	d := data.NewData(append(o.dataSources, o.contexts...), o.dataSourceHeaders)
 	addCleanupHook(d.Cleanup)
 
-	g := NewGomplate(d, o.lDelim, o.rDelim)
+	fsys, err := file.New(fs, o.fileRoot)
+	if err != nil {
+		return err
+	}
+
+	w, err := file.NewWriter(fs, o.outputDir)
+	if err != nil {
+		return err
+	}
+
+	g := NewGomplate(d, fsys, w, o.lDelim, o.rDelim)
+	g.missingKey = o.missingKey
+	if o.validateOutput != "" {
+		s, err := readInput(o.validateOutput)
+		if err != nil {
+			return err
+		}
+		g.schema, err = data.ParseSchema(s)
+		if err != nil {
+			return fmt.Errorf("invalid schema %s: %v", o.validateOutput, err)
+		}
+	}
+
+	g.context, err = newContext(d, o.contexts, o.sets)
+	if err != nil {
+		return err
+	}
 
-	tmpl, err := gatherTemplates(o)
+	tmpl, copies, err := gatherTemplates(o)
 	if err != nil {
 		return err
 	}
@@ -56,5 +144,10 @@ func runTemplate(o *GomplateOpts) error {
 			return err
 		}
 	}
+	for _, v := range copies {
+		if err := v.copy(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"text/template"
@@ -12,6 +13,8 @@ import (
 	"github.com/hairyhenderson/gomplate/conv"
 	"github.com/hairyhenderson/gomplate/data"
 	"github.com/hairyhenderson/gomplate/env"
+	"github.com/hairyhenderson/gomplate/funcs"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -145,3 +148,101 @@ func TestCustomDelim(t *testing.T) {
 	}
 	assert.Equal(t, "hi", testTemplate(g, `[print "hi"]`))
 }
+
+func TestRunTemplateValidateOutput(t *testing.T) {
+	schema, err := data.ParseSchema(`{"type": "object", "required": ["name"]}`)
+	assert.NoError(t, err)
+	g := &Gomplate{funcMap: template.FuncMap{}, schema: schema}
+
+	out := &bytes.Buffer{}
+	err = g.RunTemplate(&tplate{name: "valid", contents: `{"name": "{{ "foo" }}"}`, target: out})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name": "foo"}`, out.String())
+
+	out = &bytes.Buffer{}
+	err = g.RunTemplate(&tplate{name: "invalid", contents: `other: {{ "foo" }}`, target: out})
+	assert.EqualError(t, err, "output of invalid is invalid: 1 schema violation(s):\n  #: missing required property \"name\"")
+	assert.Empty(t, out.String())
+
+	// existing output files are left alone when the output is invalid
+	origfs := fs
+	defer func() { fs = origfs }()
+	fs = afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "/out.json", []byte(`{"name": "previous output"}`), 0644)
+
+	tp := &tplate{name: "invalid", contents: `other: {{ "foo" }}`}
+	assert.NoError(t, tp.addTarget("/out.json"))
+	assert.Error(t, g.RunTemplate(tp))
+	b, err := afero.ReadFile(fs, "/out.json")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name": "previous output"}`, string(b))
+
+	tp = &tplate{name: "valid", contents: `{"name": "{{ "foo" }}"}`}
+	assert.NoError(t, tp.addTarget("/out.json"))
+	assert.NoError(t, g.RunTemplate(tp))
+	b, err = afero.ReadFile(fs, "/out.json")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name": "foo"}`, string(b))
+}
+
+func TestRunTemplateTmplNamespace(t *testing.T) {
+	g := &Gomplate{funcMap: template.FuncMap{"toUpper": strings.ToUpper}}
+	out := &bytes.Buffer{}
+	err := g.RunTemplate(&tplate{
+		name:     "in/foo.tmpl",
+		contents: `{{ define "T" }}{{ . | toUpper }}{{ end }}{{ tmpl.Exec "T" "hi" }} {{ tmpl.Inline "{{ tmpl.Path }}" }}`,
+		target:   out,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "HI in/foo.tmpl", out.String())
+
+	out = &bytes.Buffer{}
+	err = g.RunTemplate(&tplate{name: "<arg>", contents: `[{{ tmpl.Path }}] {{ tmpl.Name }}`, target: out})
+	assert.NoError(t, err)
+	assert.Equal(t, "[] <arg>", out.String())
+}
+
+func TestRunTemplateMissingKey(t *testing.T) {
+	f := template.FuncMap{}
+	funcs.AddConvFuncs(f)
+	ctx := Context{"Values": map[string]interface{}{"foo": "bar"}}
+	run := func(mode, contents string) (string, error) {
+		g := &Gomplate{funcMap: f, context: ctx, missingKey: mode}
+		out := &bytes.Buffer{}
+		err := g.RunTemplate(&tplate{name: "<arg>", contents: contents, target: out})
+		return out.String(), err
+	}
+
+	_, err := run("", `{{ .Values.missing }}`)
+	assert.EqualError(t, err, `template: <arg>:1:11: map has no entry for key "missing"
+  1 | {{ .Values.missing }}
+    |           ^`)
+
+	out, err := run("zero", `[{{ .Values.missing }}]`)
+	assert.NoError(t, err)
+	assert.Equal(t, "[<no value>]", out)
+
+	out, err = run("default", `[{{ .Values.missing }}]`)
+	assert.NoError(t, err)
+	assert.Equal(t, "[<no value>]", out)
+
+	for _, mode := range []string{"zero", "default"} {
+		out, err = run(mode, `{{ default "x" .Values.missing }} {{ default "y" (.Values.missing) }} {{ .Values.foo | required "foo is required" }}`)
+		assert.NoError(t, err)
+		assert.Equal(t, "x y bar", out)
+
+		_, err = run(mode, "\n{{ required \"missing must be set\" .Values.missing }}")
+		assert.EqualError(t, err, `template: <arg>:2:4: error calling required: missing must be set
+  2 | {{ required "missing must be set" .Values.missing }}
+    |    ^`)
+	}
+
+	// in error mode, the missing key fails before default or required runs
+	_, err = run("error", `{{ default "x" .Values.missing }}`)
+	assert.EqualError(t, err, `template: <arg>:1:23: map has no entry for key "missing"
+  1 | {{ default "x" .Values.missing }}
+    |                       ^`)
+	out, err = run("error", `{{ default "x" .Values.foo }} {{ .Values.foo | required "foo is required" }}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "bar bar", out)
+}
@@ -0,0 +1,210 @@
+// Package ignore matches paths against gitignore-style patterns
+package ignore
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Matcher - a set of patterns, where later patterns take precedence over
+// earlier ones. A Matcher is never modified once created, so the same one can
+// be shared by sibling directories.
+type Matcher struct {
+	patterns []pattern
+}
+
+type pattern struct {
+	// the directory the pattern is relative to, slash-separated
+	base    string
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// New - create a Matcher with the given patterns, relative to the base
+// directory (see Add)
+func New(base string, patterns ...string) *Matcher {
+	return (&Matcher{}).Add(base, patterns...)
+}
+
+// Add - return a new Matcher with the given patterns (in gitignore syntax)
+// added, relative to the base directory. Blank lines and comments (starting
+// with `#`) are ignored.
+func (m *Matcher) Add(base string, patterns ...string) *Matcher {
+	out := &Matcher{}
+	if m != nil {
+		out.patterns = append(out.patterns, m.patterns...)
+	}
+	base = normalize(base)
+	for _, p := range patterns {
+		if pat, ok := compile(base, p); ok {
+			out.patterns = append(out.patterns, pat)
+		}
+	}
+	return out
+}
+
+// Parse - like Add, with patterns read from the contents of an ignore file
+func (m *Matcher) Parse(base, contents string) *Matcher {
+	return m.Add(base, strings.Split(strings.Replace(contents, "\r\n", "\n", -1), "\n")...)
+}
+
+// Empty - whether the Matcher has no patterns
+func (m *Matcher) Empty() bool {
+	return m == nil || len(m.patterns) == 0
+}
+
+// Match - whether the path (a directory if isDir is set) matches. As with
+// gitignore, the last matching pattern wins, so a negated pattern (starting
+// with `!`) can exclude paths matched by earlier patterns.
+func (m *Matcher) Match(p string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	p = normalize(p)
+	matched := false
+	for _, pat := range m.patterns {
+		if pat.dirOnly && !isDir {
+			continue
+		}
+		rel := p
+		if pat.base != "" {
+			if !strings.HasPrefix(p, pat.base+"/") {
+				continue
+			}
+			rel = p[len(pat.base)+1:]
+		}
+		if pat.re.MatchString(rel) {
+			matched = !pat.negate
+		}
+	}
+	return matched
+}
+
+// normalize - a clean, slash-separated path without a leading slash, so
+// that absolute and relative paths are treated alike
+func normalize(p string) string {
+	p = path.Clean(filepath.ToSlash(p))
+	p = strings.TrimPrefix(p, "/")
+	if p == "." {
+		return ""
+	}
+	return p
+}
+
+func compile(base, line string) (pattern, bool) {
+	p := pattern{base: base}
+	line = trimTrailingSpace(line)
+	if line == "" || line[0] == '#' {
+		return p, false
+	}
+	if line[0] == '!' {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return p, false
+	}
+	// patterns containing a slash (other than at the end) are relative to the
+	// base, others match at any level below it
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(strings.TrimPrefix(line, "./"), "/")
+	expr := globToRegexp(line)
+	if anchored {
+		expr = "^" + expr + "$"
+	} else {
+		expr = "^(?:.*/)?" + expr + "$"
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return p, false
+	}
+	p.re = re
+	return p, true
+}
+
+// trimTrailingSpace - remove trailing spaces, unless escaped with a backslash
+func trimTrailingSpace(line string) string {
+	line = strings.TrimRight(line, "\r")
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// globToRegexp - translate the glob to a regular expression, where `*` and
+// `?` don't match `/`, and `**` matches across directories
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '*' && i+1 < len(glob) && glob[i+1] == '*' && (i == 0 || glob[i-1] == '/'):
+			switch {
+			case i+2 == len(glob):
+				// trailing "/**" matches everything inside
+				sb.WriteString(".*")
+				i++
+			case glob[i+2] == '/':
+				// leading "**/" or inner "/**/" match zero or more directories
+				sb.WriteString("(?:.*/)?")
+				i += 2
+			default:
+				sb.WriteString("[^/]*")
+				i++
+			}
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := classEnd(glob, i)
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : end]
+			sb.WriteByte('[')
+			if strings.HasPrefix(class, "!") || strings.HasPrefix(class, "^") {
+				sb.WriteByte('^')
+				class = class[1:]
+			}
+			sb.WriteString(strings.Replace(strings.Replace(class, `\`, `\\`, -1), "[", `\[`, -1))
+			sb.WriteByte(']')
+			i = end
+		case c == '\\' && i+1 < len(glob):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
+
+// classEnd - the index of the "]" closing the character class starting at
+// i, or -1 if it's unclosed
+func classEnd(glob string, i int) int {
+	j := i + 1
+	if j < len(glob) && (glob[j] == '!' || glob[j] == '^') {
+		j++
+	}
+	// a "]" first in the class is literal
+	if j < len(glob) && glob[j] == ']' {
+		j++
+	}
+	for ; j < len(glob); j++ {
+		if glob[j] == ']' {
+			return j
+		}
+	}
+	return -1
+}
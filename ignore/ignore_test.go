@@ -0,0 +1,105 @@
+package ignore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	testdata := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		match   bool
+	}{
+		{"foo", "foo", false, true},
+		{"foo", "a/b/foo", false, true},
+		{"foo", "a/foo/b", false, false},
+		{"foo", "foobar", false, false},
+		{"*.bak", "a.bak", false, true},
+		{"*.bak", "a/b/c.bak", false, true},
+		{"*.bak", "a.bak/c", false, false},
+		{"/foo", "foo", false, true},
+		{"/foo", "a/foo", false, false},
+		{"a/foo", "a/foo", false, true},
+		{"a/foo", "b/a/foo", false, false},
+		{"a/*.txt", "a/b.txt", false, true},
+		{"a/*.txt", "a/b/c.txt", false, false},
+		{"build/", "build", true, true},
+		{"build/", "build", false, false},
+		{"build/", "a/build", true, true},
+		{"**/foo", "foo", false, true},
+		{"**/foo", "a/b/foo", false, true},
+		{"**/a/foo", "x/a/foo", false, true},
+		{"a/**", "a/b/c", false, true},
+		{"a/**", "a", true, false},
+		{"a/**/b", "a/b", false, true},
+		{"a/**/b", "a/x/y/b", false, true},
+		{"a/**/b", "a/xb", false, false},
+		{"f?o", "foo", false, true},
+		{"f?o", "f/o", false, false},
+		{"[abc].txt", "b.txt", false, true},
+		{"[!abc].txt", "b.txt", false, false},
+		{"[!abc].txt", "d.txt", false, true},
+		{"[a-c]x", "bx", false, true},
+		{"[", "[", false, true},
+		{`\#foo`, "#foo", false, true},
+		{`\!foo`, "!foo", false, true},
+		{`foo\*`, "foo*", false, true},
+		{`foo\*`, "foobar", false, false},
+		{"foo  ", "foo", false, true},
+		{`foo\ `, "foo ", false, true},
+		{"./foo", "foo", false, true},
+		{"/tmp/in/foo", "/tmp/in/foo", false, true},
+	}
+	for _, d := range testdata {
+		assert.Equal(t, d.match, New("", d.pattern).Match(d.path, d.isDir), "%q vs %q", d.pattern, d.path)
+	}
+}
+
+func TestMatchNegation(t *testing.T) {
+	m := New("", "*.bak", "!keep.bak")
+	assert.True(t, m.Match("a.bak", false))
+	assert.False(t, m.Match("keep.bak", false))
+	assert.False(t, m.Match("a/keep.bak", false))
+
+	// the last matching pattern wins
+	m = m.Add("", "keep.bak")
+	assert.True(t, m.Match("keep.bak", false))
+}
+
+func TestMatchBase(t *testing.T) {
+	m := New("/in", "/foo", "*.bak")
+	assert.True(t, m.Match("/in/foo", false))
+	assert.False(t, m.Match("/in/a/foo", false))
+	assert.False(t, m.Match("/foo", false))
+	assert.True(t, m.Match("/in/a/b.bak", false))
+	assert.False(t, m.Match("/out/b.bak", false))
+
+	m2 := m.Add("/in/a", "!b.bak")
+	assert.False(t, m2.Match("/in/a/b.bak", false))
+	assert.True(t, m2.Match("/in/c/b.bak", false))
+	// the original is unchanged
+	assert.True(t, m.Match("/in/a/b.bak", false))
+
+	m = New("in", "sub/*.txt")
+	assert.True(t, m.Match("in/sub/a.txt", false))
+	assert.True(t, m.Match("./in/sub/a.txt", false))
+}
+
+func TestParse(t *testing.T) {
+	m := New("").Parse("", "# comment\r\n\r\n*.bak\n!keep.bak\n")
+	assert.True(t, m.Match("a.bak", false))
+	assert.False(t, m.Match("keep.bak", false))
+	assert.False(t, m.Match("# comment", false))
+	assert.False(t, m.Match("", false))
+}
+
+func TestEmpty(t *testing.T) {
+	var m *Matcher
+	assert.True(t, m.Empty())
+	assert.False(t, m.Match("foo", false))
+	assert.True(t, New("", "", "# foo").Empty())
+	assert.False(t, New("", "foo").Empty())
+}
@@ -0,0 +1,516 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/hairyhenderson/gomplate/data"
+	"github.com/hairyhenderson/gomplate/env"
+	"github.com/hairyhenderson/gomplate/file"
+	"github.com/hairyhenderson/gomplate/funcs"
+	"github.com/spf13/cobra"
+)
+
+// LintOpts - options for the lint command
+type LintOpts struct {
+	dataSources  []string
+	contexts     []string
+	lDelim       string
+	rDelim       string
+	input        string
+	inputFiles   []string
+	inputDir     string
+	excludeGlob  []string
+	includeGlob  []string
+	verbatimGlob []string
+}
+
+var lintOpts LintOpts
+
+const (
+	lintError   = "error"
+	lintWarning = "warning"
+)
+
+// lintProblem - a problem found in a template
+type lintProblem struct {
+	file     string
+	line     int
+	col      int
+	severity string
+	msg      string
+}
+
+func (p lintProblem) String() string {
+	if p.file == "" {
+		return fmt.Sprintf("%s: %s", p.severity, p.msg)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s", p.file, p.line, p.col, p.severity, p.msg)
+}
+
+// builtins - the functions provided by text/template itself
+var builtins = []string{
+	"and", "call", "html", "index", "slice", "js", "len", "not", "or", "print",
+	"printf", "println", "urlquery", "eq", "ge", "gt", "le", "lt", "ne",
+}
+
+// datasourceFuncs - functions taking a datasource alias as their first
+// argument, and whether the alias must be defined
+var datasourceFuncs = map[string]bool{
+	"datasource":       true,
+	"ds":               true,
+	"include":          true,
+	"data.Validate":    true,
+	"datasourceExists": false,
+}
+
+func newLintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint [file...]",
+		Short: "Check templates for errors, without rendering them",
+		Long: `Parses each template, reporting syntax errors, calls to undefined functions,
+references to undefined datasources, and datasources that are never used.
+No datasources are read, and no output is written.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			problems, err := lint(&lintOpts, args)
+			if err != nil {
+				return err
+			}
+			errs := 0
+			for _, p := range problems {
+				fmt.Fprintln(stdout, p)
+				if p.severity == lintError {
+					errs++
+				}
+			}
+			if errs > 0 {
+				return fmt.Errorf("%d error(s) found", errs)
+			}
+			return nil
+		},
+	}
+	initLintFlags(cmd)
+	return cmd
+}
+
+func initLintFlags(command *cobra.Command) {
+	command.Flags().StringArrayVarP(&lintOpts.inputFiles, "file", "f", nil, "Template `file` to check. Files can also be given as arguments. Omit to use standard input, or use --in or --input-dir")
+	command.Flags().StringVarP(&lintOpts.input, "in", "i", "", "Template `string` to check (alternative to --file and --input-dir)")
+	command.Flags().StringVar(&lintOpts.inputDir, "input-dir", "", "`directory` which is examined recursively for templates (alternative to --file and --in)")
+	command.Flags().StringArrayVar(&lintOpts.excludeGlob, "exclude", []string{}, "glob of files to not check")
+	command.Flags().StringArrayVar(&lintOpts.includeGlob, "include", []string{}, "glob of files to check (all others are skipped). Only used for --input-dir")
+	command.Flags().StringArrayVar(&lintOpts.verbatimGlob, "verbatim", []string{}, "glob of files that are copied as-is, and so not checked. Only used for --input-dir")
+
+	command.Flags().StringArrayVarP(&lintOpts.dataSources, "datasource", "d", nil, "`datasource` in alias=URL form. Specify multiple times to add multiple sources.")
+	command.Flags().StringArrayVarP(&lintOpts.contexts, "context", "c", nil, "datasource in alias=URL form, to be added to the template context as .alias. Specify multiple times to add multiple sources.")
+
+	ldDefault := env.Getenv("GOMPLATE_LEFT_DELIM", "{{")
+	rdDefault := env.Getenv("GOMPLATE_RIGHT_DELIM", "}}")
+	command.Flags().StringVar(&lintOpts.lDelim, "left-delim", ldDefault, "override the default left-`delimiter` [$GOMPLATE_LEFT_DELIM]")
+	command.Flags().StringVar(&lintOpts.rDelim, "right-delim", rdDefault, "override the default right-`delimiter` [$GOMPLATE_RIGHT_DELIM]")
+}
+
+// linter - checks templates against the function map and the declared
+// datasources
+type linter struct {
+	funcs    map[string]bool
+	declared map[string]bool
+	// declared with --datasource or in front matter, so expected to be used
+	unused map[string]bool
+	// whether a datasource alias was given other than as a string literal, or
+	// a template couldn't be parsed, so that it's not known which are used
+	dynamic  bool
+	problems []lintProblem
+}
+
+// lint - check the templates given by the options and arguments
+func lint(o *LintOpts, args []string) ([]lintProblem, error) {
+	templates, err := gatherLintTemplates(o, args)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &linter{
+		funcs:    map[string]bool{},
+		declared: map[string]bool{},
+		unused:   map[string]bool{},
+	}
+	if err := l.initFuncs(); err != nil {
+		return nil, err
+	}
+	for _, ds := range o.dataSources {
+		s, err := data.ParseSource(ds)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing datasource %v", err)
+		}
+		l.declared[s.Alias] = true
+		l.unused[s.Alias] = true
+	}
+	for _, c := range o.contexts {
+		s, err := data.ParseSource(c)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing datasource %v", err)
+		}
+		l.declared[s.Alias] = true
+	}
+
+	for _, t := range templates {
+		fm, contents, err := splitFrontMatter(t.contents)
+		if err == nil && fm != nil {
+			t.contents = contents
+			if err = fm.validate(t.name); err != nil {
+				// keep line numbers right, but ignore the settings
+				fm = &frontMatter{lines: fm.lines}
+			}
+			t.frontMatter = fm
+		}
+		if err != nil {
+			l.problems = append(l.problems, lintProblem{
+				file:     t.name,
+				line:     1,
+				col:      1,
+				severity: lintError,
+				msg:      fmt.Sprintf("invalid front matter: %v", err),
+			})
+			continue
+		}
+		if fm != nil {
+			for alias := range fm.Datasources {
+				l.unused[alias] = true
+			}
+		}
+	}
+
+	for _, t := range templates {
+		leftDelim, rightDelim := o.lDelim, o.rDelim
+		contents := t.contents
+		if fm := t.frontMatter; fm != nil {
+			if fm.LeftDelim != "" {
+				leftDelim, rightDelim = fm.LeftDelim, fm.RightDelim
+			}
+			contents = fm.comment(leftDelim, rightDelim) + contents
+		}
+		// front matter datasources are only available to the template
+		// defining them
+		local := []string{}
+		if fm := t.frontMatter; fm != nil {
+			for alias := range fm.Datasources {
+				if !l.declared[alias] {
+					l.declared[alias] = true
+					local = append(local, alias)
+				}
+			}
+		}
+		start := len(l.problems)
+		l.lintTemplate(t.name, contents, leftDelim, rightDelim)
+		for _, alias := range local {
+			delete(l.declared, alias)
+		}
+		for i := start; i < len(l.problems); i++ {
+			l.problems[i].col = t.column(l.problems[i].line, l.problems[i].col, rightDelim)
+		}
+	}
+
+	// a datasource given as a variable could be any of them
+	if !l.dynamic {
+		unused := []string{}
+		for alias := range l.unused {
+			unused = append(unused, alias)
+		}
+		sort.Strings(unused)
+		for _, alias := range unused {
+			l.problems = append(l.problems, lintProblem{
+				severity: lintWarning,
+				msg:      fmt.Sprintf("datasource '%s' is never used", alias),
+			})
+		}
+	}
+	return l.problems, nil
+}
+
+// gatherLintTemplates - read the templates to check
+func gatherLintTemplates(o *LintOpts, args []string) ([]*tplate, error) {
+	names := append(append([]string{}, o.inputFiles...), args...)
+	if o.inputDir != "" {
+		files, err := listTemplates(o.inputDir, o.excludeGlob, o.includeGlob, o.verbatimGlob)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, files...)
+	}
+
+	templates := []*tplate{}
+	if o.input != "" {
+		templates = append(templates, &tplate{name: "<arg>", contents: o.input})
+	} else if len(names) == 0 {
+		names = []string{"-"}
+	}
+	for _, name := range names {
+		templates = append(templates, &tplate{name: name})
+	}
+
+	for _, t := range templates {
+		if err := t.loadContents(); err != nil {
+			return nil, err
+		}
+	}
+	return templates, nil
+}
+
+// initFuncs - gather the names of all functions available to templates
+func (l *linter) initFuncs() error {
+	fsys, err := file.New(fs, "")
+	if err != nil {
+		return err
+	}
+	w, err := file.NewWriter(fs, ".")
+	if err != nil {
+		return err
+	}
+	funcMap := initFuncs(data.NewData(nil, nil), fsys, w)
+	funcs.AddTmplFuncs(funcMap, template.New(""), "", "", nil)
+	for name := range funcMap {
+		l.funcs[name] = true
+	}
+	for _, name := range builtins {
+		l.funcs[name] = true
+	}
+	return nil
+}
+
+func (l *linter) report(name, text string, pos parse.Pos, severity, format string, args ...interface{}) {
+	line, col := position(text, int(pos))
+	l.problems = append(l.problems, lintProblem{
+		file:     name,
+		line:     line,
+		col:      col,
+		severity: severity,
+		msg:      fmt.Sprintf(format, args...),
+	})
+}
+
+// lintTemplate - parse the template, and check the function calls and
+// datasource references in it
+func (l *linter) lintTemplate(name, text, leftDelim, rightDelim string) {
+	start := len(l.problems)
+	defer func() {
+		problems := l.problems[start:]
+		sort.SliceStable(problems, func(i, j int) bool {
+			if problems[i].line != problems[j].line {
+				return problems[i].line < problems[j].line
+			}
+			return problems[i].col < problems[j].col
+		})
+	}()
+
+	trees, err := parseTrees(name, text, leftDelim, rightDelim)
+	if err != nil {
+		line, col, msg := syntaxErrorPosition(name, text, leftDelim, rightDelim, err)
+		l.problems = append(l.problems, lintProblem{
+			file:     name,
+			line:     line,
+			col:      col,
+			severity: lintError,
+			msg:      msg,
+		})
+		l.dynamic = true
+		return
+	}
+
+	treeNames := []string{}
+	for n := range trees {
+		treeNames = append(treeNames, n)
+	}
+	sort.Strings(treeNames)
+	for _, n := range treeNames {
+		(&treeLinter{linter: l, name: name, text: text}).walk(trees[n].Root)
+	}
+}
+
+// undefinedFuncRE - matches the parser's error for a call to an undefined
+// function
+var undefinedFuncRE = regexp.MustCompile(`: function ("(?:[^"\\]|\\.)*") not defined$`)
+
+// parseTrees - parse the template without checking that functions are
+// defined, so that all undefined functions can be reported. The parser has
+// no way to skip that check before Go 1.17, so each undefined function is
+// defined as a placeholder in turn, until the template parses.
+func parseTrees(name, text, leftDelim, rightDelim string) (map[string]*parse.Tree, error) {
+	placeholders := map[string]interface{}{}
+	for _, b := range builtins {
+		placeholders[b] = true
+	}
+	for {
+		trees := map[string]*parse.Tree{}
+		_, err := parse.New(name).Parse(text, leftDelim, rightDelim, trees, placeholders)
+		if err == nil {
+			return trees, nil
+		}
+		m := undefinedFuncRE.FindStringSubmatch(err.Error())
+		if m == nil {
+			return trees, err
+		}
+		fn, uerr := strconv.Unquote(m[1])
+		if _, seen := placeholders[fn]; uerr != nil || seen {
+			return trees, err
+		}
+		placeholders[fn] = true
+	}
+}
+
+// syntaxErrorPosition - the line, column and message of a parse error. The
+// parser only reports the line, so the column is that of the first action on
+// the line where the template, cut off after that action, fails to parse with
+// the same error.
+func syntaxErrorPosition(name, text, leftDelim, rightDelim string, err error) (int, int, string) {
+	msg := strings.TrimPrefix(err.Error(), "template: "+name+":")
+	i := strings.Index(msg, ": ")
+	if i < 0 {
+		return 1, 1, err.Error()
+	}
+	line, cerr := strconv.Atoi(msg[:i])
+	if cerr != nil {
+		return 1, 1, err.Error()
+	}
+	msg = msg[i+2:]
+
+	lines := strings.SplitAfter(text, "\n")
+	if line < 1 || line > len(lines) {
+		return line, 1, msg
+	}
+	start := len(strings.Join(lines[:line-1], ""))
+	l := lines[line-1]
+	first := -1
+	for off := 0; ; {
+		i := strings.Index(l[off:], leftDelim)
+		if i < 0 {
+			break
+		}
+		i += off
+		if first < 0 {
+			first = i
+		}
+		end := len(l)
+		if j := strings.Index(l[i+len(leftDelim):], rightDelim); j >= 0 {
+			end = i + len(leftDelim) + j + len(rightDelim)
+		}
+		if _, perr := parseTrees(name, text[:start+end], leftDelim, rightDelim); perr != nil && perr.Error() == err.Error() {
+			return line, i + 1, msg
+		}
+		off = i + len(leftDelim)
+	}
+	if first >= 0 {
+		return line, first + 1, msg
+	}
+	return line, 1, msg
+}
+
+// position - the 1-based line and column of the byte offset in text
+func position(text string, pos int) (int, int) {
+	if pos > len(text) {
+		pos = len(text)
+	}
+	before := text[:pos]
+	line := 1 + strings.Count(before, "\n")
+	col := pos - strings.LastIndex(before, "\n")
+	return line, col
+}
+
+// treeLinter - walks a parsed template tree
+type treeLinter struct {
+	*linter
+	name string
+	text string
+}
+
+func (t *treeLinter) walk(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			t.walk(c)
+		}
+	case *parse.ActionNode:
+		t.walk(n.Pipe)
+	case *parse.IfNode:
+		t.walkBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		t.walkBranch(&n.BranchNode)
+	case *parse.WithNode:
+		t.walkBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		t.walk(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for i, c := range n.Cmds {
+			t.command(n, i, c)
+		}
+	case *parse.ChainNode:
+		t.walk(n.Node)
+	case *parse.IdentifierNode:
+		if !t.funcs[n.Ident] {
+			t.report(t.name, t.text, n.Position(), lintError, "function %q not defined", n.Ident)
+		}
+	}
+}
+
+func (t *treeLinter) walkBranch(n *parse.BranchNode) {
+	t.walk(n.Pipe)
+	t.walk(n.List)
+	t.walk(n.ElseList)
+}
+
+// command - check the command's arguments, and the datasource alias if it's
+// a datasource function
+func (t *treeLinter) command(pipe *parse.PipeNode, i int, cmd *parse.CommandNode) {
+	for _, arg := range cmd.Args {
+		t.walk(arg)
+	}
+
+	fn := funcName(cmd.Args[0])
+	mustExist, ok := datasourceFuncs[fn]
+	if !ok {
+		return
+	}
+	var aliasArg parse.Node
+	if len(cmd.Args) > 1 {
+		aliasArg = cmd.Args[1]
+	} else if i > 0 && len(pipe.Cmds[i-1].Args) == 1 {
+		// the alias is piped in, as in `"alias" | ds`
+		aliasArg = pipe.Cmds[i-1].Args[0]
+	}
+	s, ok := aliasArg.(*parse.StringNode)
+	if !ok {
+		t.dynamic = true
+		return
+	}
+	delete(t.unused, s.Text)
+	if mustExist && !t.declared[s.Text] {
+		t.report(t.name, t.text, s.Position(), lintError, "undefined datasource '%s'", s.Text)
+	}
+}
+
+// funcName - the name of the function called by the node, like `ds` or
+// `data.Validate`, or "" if it's not a function call
+func funcName(n parse.Node) string {
+	switch n := n.(type) {
+	case *parse.IdentifierNode:
+		return n.Ident
+	case *parse.ChainNode:
+		if id, ok := n.Node.(*parse.IdentifierNode); ok {
+			return id.Ident + "." + strings.Join(n.Field, ".")
+		}
+	}
+	return ""
+}
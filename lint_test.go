@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func lintStrings(problems []lintProblem) []string {
+	out := make([]string, len(problems))
+	for i, p := range problems {
+		out[i] = p.String()
+	}
+	return out
+}
+
+func TestLint(t *testing.T) {
+	origfs := fs
+	defer func() { fs = origfs }()
+	fs = afero.NewMemMapFs()
+
+	afero.WriteFile(fs, "in/good.tmpl", []byte(`{{ ds "cfg" }} {{ "x" | strings.ToUpper }}`), 0644)
+	afero.WriteFile(fs, "in/funcs.tmpl", []byte("{{ foo 1 }}\n{{ if true }}{{ bar | baz }}{{ end }}"), 0644)
+	afero.WriteFile(fs, "in/ds.tmpl", []byte(`{{ ds "nope" }} {{ "piped" | include }} {{ datasourceExists "maybe" }}`), 0644)
+	afero.WriteFile(fs, "in/syntax.tmpl", []byte("ok\n{{ 1 }} {{end}}"), 0644)
+
+	problems, err := lint(&LintOpts{
+		inputDir:    "in",
+		dataSources: []string{"cfg=cfg.json", "extra=extra.json"},
+		lDelim:      "{{",
+		rDelim:      "}}",
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"in/ds.tmpl:1:7: error: undefined datasource 'nope'",
+		"in/ds.tmpl:1:20: error: undefined datasource 'piped'",
+		`in/funcs.tmpl:1:4: error: function "foo" not defined`,
+		`in/funcs.tmpl:2:17: error: function "bar" not defined`,
+		`in/funcs.tmpl:2:23: error: function "baz" not defined`,
+		"in/syntax.tmpl:2:9: error: unexpected {{end}}",
+	}, lintStrings(problems))
+
+	// all templates parse, so unused datasources are known
+	_ = fs.Remove("in/syntax.tmpl")
+	problems, err = lint(&LintOpts{
+		inputDir:    "in",
+		dataSources: []string{"cfg=cfg.json", "extra=extra.json"},
+		lDelim:      "{{",
+		rDelim:      "}}",
+	}, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, lintStrings(problems), "warning: datasource 'extra' is never used")
+
+	// no output is written
+	exists, _ := afero.DirExists(fs, ".")
+	assert.True(t, exists)
+	files, _ := afero.ReadDir(fs, ".")
+	assert.Len(t, files, 1)
+}
+
+func TestLintDynamicDatasource(t *testing.T) {
+	problems, err := lint(&LintOpts{
+		input:       `{{ range slice "a" "b" }}{{ ds . }}{{ end }}`,
+		dataSources: []string{"a=a.json", "b=b.json"},
+		lDelim:      "{{",
+		rDelim:      "}}",
+	}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, problems)
+}
+
+func TestLintFrontMatter(t *testing.T) {
+	origfs := fs
+	defer func() { fs = origfs }()
+	fs = afero.NewMemMapFs()
+
+	afero.WriteFile(fs, "a.tmpl", []byte("---\ndatasources:\n  svc: svc.json\nleftDelim: '[['\nrightDelim: ']]'\n---\n[[ ds \"svc\" ]]\n[[ nope ]] {{ ignored }}"), 0644)
+	afero.WriteFile(fs, "b.tmpl", []byte("---\nmode: abc\n---\n{{ ds \"svc\" }}"), 0644)
+
+	problems, err := lint(&LintOpts{lDelim: "{{", rDelim: "}}"}, []string{"a.tmpl", "b.tmpl"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"b.tmpl:1:1: error: invalid front matter: invalid mode \"abc\": must be an octal number like 0644",
+		`a.tmpl:8:4: error: function "nope" not defined`,
+		// svc is only defined for a.tmpl
+		`b.tmpl:4:7: error: undefined datasource 'svc'`,
+	}, lintStrings(problems))
+}
+
+func TestParseTrees(t *testing.T) {
+	trees, err := parseTrees("t", `{{ define "x" }}{{ foo | bar }}{{ end }}{{ baz 1 }}{{ len "a" }}`, "{{", "}}")
+	assert.NoError(t, err)
+	assert.Len(t, trees, 2)
+	assert.Contains(t, trees, "x")
+
+	_, err = parseTrees("t", `{{ foo }}{{ if }}`, "{{", "}}")
+	assert.EqualError(t, err, "template: t:1: missing value for if")
+}
+
+func TestSyntaxErrorPosition(t *testing.T) {
+	testdata := []struct {
+		text      string
+		line, col int
+		msg       string
+	}{
+		{"{{ 1 }} {{ if }}", 1, 9, "missing value for if"},
+		{"a\nb {{ \"x\" }\n", 2, 3, `unexpected "}" in operand`},
+		{"{{ if true }}\n{{ end }}{{ end }}", 2, 10, "unexpected {{end}}"},
+		{"{{ if true }}", 1, 1, "unexpected EOF"},
+		{"{{ if true }}\n{{ 1 }} {{ end }} {{ if }}", 2, 19, "missing value for if"},
+	}
+	for _, d := range testdata {
+		_, err := parseTrees("t", d.text, "{{", "}}")
+		assert.Error(t, err)
+		line, col, msg := syntaxErrorPosition("t", d.text, "{{", "}}", err)
+		assert.Equal(t, d.line, line, d.text)
+		assert.Equal(t, d.col, col, d.text)
+		assert.Equal(t, d.msg, msg, d.text)
+	}
+}
+
+func TestPosition(t *testing.T) {
+	line, col := position("abc\ndef", 0)
+	assert.Equal(t, 1, line)
+	assert.Equal(t, 1, col)
+	line, col = position("abc\ndef", 5)
+	assert.Equal(t, 2, line)
+	assert.Equal(t, 2, col)
+}
+
+func TestLintCmd(t *testing.T) {
+	defer func() { stdout = os.Stdout }()
+	out := &bytes.Buffer{}
+	stdout = &nopWCloser{out}
+
+	cmd := newLintCmd()
+	cmd.SetArgs([]string{"-i", "{{ nope }}"})
+	err := cmd.Execute()
+	assert.EqualError(t, err, "1 error(s) found")
+	assert.Equal(t, "<arg>:1:4: error: function \"nope\" not defined\n", out.String())
+}
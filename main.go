@@ -14,16 +14,26 @@ import (
 type GomplateOpts struct {
 	version           bool
 	dataSources       []string
+	contexts          []string
+	sets              []string
 	dataSourceHeaders []string
 	lDelim            string
 	rDelim            string
 
-	input       string
-	inputFiles  []string
-	inputDir    string
-	outputFiles []string
-	outputDir   string
-	excludeGlob []string
+	input        string
+	inputFiles   []string
+	inputDir     string
+	outputFiles  []string
+	outputDir    string
+	excludeGlob  []string
+	includeGlob  []string
+	verbatimGlob []string
+
+	validateOutput string
+	seed           string
+	fileRoot       string
+	errorFormat    string
+	missingKey     string
 }
 
 var opts GomplateOpts
@@ -41,6 +51,16 @@ func validateOpts(cmd *cobra.Command, args []string) error {
 		return errors.New("--input-dir can not be used together with --in or --file")
 	}
 
+	if opts.errorFormat != "text" && opts.errorFormat != "json" {
+		return fmt.Errorf("invalid --error-format %q: must be text or json", opts.errorFormat)
+	}
+
+	switch opts.missingKey {
+	case "error", "zero", "default":
+	default:
+		return fmt.Errorf("invalid --missing-key %q: must be error, zero, or default", opts.missingKey)
+	}
+
 	if cmd.Flag("output-dir").Changed {
 		if cmd.Flag("out").Changed {
 			return errors.New("--output-dir can not be used together with --out")
@@ -63,14 +83,23 @@ func newGomplateCmd() *cobra.Command {
 		Short:   "Process text files with Go templates",
 		PreRunE: validateOpts,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// flags have been parsed, so errors from here on aren't usage errors
+			cmd.SilenceUsage = true
 			if opts.version {
 				printVersion(cmd.Name())
 				return nil
 			}
-			return runTemplate(&opts)
+			err := runTemplate(&opts)
+			if err != nil && opts.errorFormat == "json" {
+				// only the JSON object should be printed
+				cmd.SilenceErrors = true
+				return jsonError(err)
+			}
+			return err
 		},
 		Args: cobra.NoArgs,
 	}
+	rootCmd.AddCommand(newLintCmd())
 	return rootCmd
 }
 
@@ -81,12 +110,23 @@ func initFlags(command *cobra.Command) {
 	command.Flags().StringVarP(&opts.input, "in", "i", "", "Template `string` to process (alternative to --file and --input-dir)")
 	command.Flags().StringVar(&opts.inputDir, "input-dir", "", "`directory` which is examined recursively for templates (alternative to --file and --in)")
 	command.Flags().StringArrayVar(&opts.excludeGlob, "exclude", []string{}, "glob of files to not parse")
+	command.Flags().StringArrayVar(&opts.includeGlob, "include", []string{}, "glob of files to parse (all others are skipped). Only used for --input-dir")
+	command.Flags().StringArrayVar(&opts.verbatimGlob, "verbatim", []string{}, "glob of files to copy as-is, without parsing. Only used for --input-dir")
 	command.Flags().StringArrayVarP(&opts.outputFiles, "out", "o", []string{"-"}, "output `file` name. Omit to use standard output.")
 	command.Flags().StringVar(&opts.outputDir, "output-dir", ".", "`directory` to store the processed templates. Only used for --input-dir")
 
+	command.Flags().StringVar(&opts.validateOutput, "validate-output", "", "JSON Schema `file` to validate each rendered JSON or YAML output against")
+
 	command.Flags().StringArrayVarP(&opts.dataSources, "datasource", "d", nil, "`datasource` in alias=URL form. Specify multiple times to add multiple sources.")
+	command.Flags().StringArrayVarP(&opts.contexts, "context", "c", nil, "datasource in alias=URL form, to be added to the template context as .alias. Specify multiple times to add multiple sources.")
+	command.Flags().StringArrayVar(&opts.sets, "set", nil, "value in key=value form to be added to the template context, where key may be a dotted path (like foo.bar). Specify multiple times to set multiple values.")
 	command.Flags().StringArrayVarP(&opts.dataSourceHeaders, "datasource-header", "H", nil, "HTTP `header` field in 'alias=Name: value' form to be provided on HTTP-based data sources. Multiples can be set.")
 
+	command.Flags().StringVar(&opts.fileRoot, "file-root", "", "confine the file functions to this `directory`")
+	command.Flags().StringVar(&opts.errorFormat, "error-format", "text", "`format` for template errors: text, or json for editors and other tools")
+	command.Flags().StringVar(&opts.missingKey, "missing-key", "error", "`mode` for references to missing map keys: error, zero (use the zero value), or default (print <no value>)")
+	command.Flags().StringVar(&opts.seed, "seed", env.Getenv("GOMPLATE_SEED", ""), "integer `seed` for the random and uuid functions, making their output repeatable. Not for use with secrets! [$GOMPLATE_SEED]")
+
 	ldDefault := env.Getenv("GOMPLATE_LEFT_DELIM", "{{")
 	rdDefault := env.Getenv("GOMPLATE_RIGHT_DELIM", "}}")
 	command.Flags().StringVar(&opts.lDelim, "left-delim", ldDefault, "override the default left-`delimiter` [$GOMPLATE_LEFT_DELIM]")
@@ -97,7 +137,11 @@ func main() {
 	command := newGomplateCmd()
 	initFlags(command)
 	if err := command.Execute(); err != nil {
-		fmt.Println(err)
+		if j, ok := err.(errorJSON); ok {
+			fmt.Fprintln(os.Stderr, j)
+		} else {
+			fmt.Println(err)
+		}
 		os.Exit(1)
 	}
 }
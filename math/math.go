@@ -1,21 +1,75 @@
 package math
 
-// AddInt -
-func AddInt(n ...int64) int64 {
+import (
+	gmath "math"
+	"strconv"
+)
+
+// IsInt - whether the value is an integer which fits in an int64, or a string
+// representing one (in the forms conv.ToInt64 accepts)
+func IsInt(n interface{}) bool {
+	switch v := n.(type) {
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
+		return true
+	case uint:
+		return uint64(v) <= gmath.MaxInt64
+	case uint64:
+		return v <= gmath.MaxInt64
+	case string:
+		_, err := strconv.ParseInt(v, 0, 64)
+		return err == nil
+	}
+	return false
+}
+
+// AllInts - whether all of the values are integers (or booleans, which count
+// as 0 and 1), so that integer arithmetic can be used
+func AllInts(n ...interface{}) bool {
+	for _, v := range n {
+		if _, ok := v.(bool); !ok && !IsInt(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// AddInt - the sum of the numbers, and false if it overflows
+func AddInt(n ...int64) (int64, bool) {
 	x := int64(0)
 	for _, i := range n {
-		x += i
+		r := x + i
+		if (i > 0 && r < x) || (i < 0 && r > x) {
+			return 0, false
+		}
+		x = r
+	}
+	return x, true
+}
+
+// SubInt - a - b, and false if the result overflows
+func SubInt(a, b int64) (int64, bool) {
+	if b == gmath.MinInt64 {
+		// -b overflows
+		return 0, false
 	}
-	return x
+	return AddInt(a, -b)
 }
 
-// MulInt -
-func MulInt(n ...int64) int64 {
+// MulInt - the product of the numbers, and false if it overflows
+func MulInt(n ...int64) (int64, bool) {
 	var x int64 = 1
 	for _, i := range n {
-		x *= i
+		if x == 0 || i == 0 {
+			x = 0
+			continue
+		}
+		r := x * i
+		if r/i != x || (x == -1 && i == gmath.MinInt64) || (i == -1 && x == gmath.MinInt64) {
+			return 0, false
+		}
+		x = r
 	}
-	return x
+	return x, true
 }
 
 // Seq - return a sequence from `start` to `end`, in steps of `step`.
@@ -1,14 +1,47 @@
 package math
 
 import (
+	gmath "math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestMath(t *testing.T) {
-	assert.Equal(t, int64(10), AddInt(1, 2, 3, 4))
-	assert.Equal(t, int64(12), MulInt(3, 4, 1))
+	x, ok := AddInt(1, 2, 3, 4)
+	assert.True(t, ok)
+	assert.Equal(t, int64(10), x)
+	x, ok = MulInt(3, 4, 1)
+	assert.True(t, ok)
+	assert.Equal(t, int64(12), x)
+	x, ok = SubInt(3, 5)
+	assert.True(t, ok)
+	assert.Equal(t, int64(-2), x)
+	x, ok = MulInt(0, gmath.MaxInt64, 2)
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), x)
+
+	_, ok = AddInt(gmath.MaxInt64, 1)
+	assert.False(t, ok)
+	_, ok = AddInt(gmath.MinInt64, -1)
+	assert.False(t, ok)
+	_, ok = SubInt(0, gmath.MinInt64)
+	assert.False(t, ok)
+	_, ok = MulInt(gmath.MaxInt64, 2)
+	assert.False(t, ok)
+	_, ok = MulInt(-1, gmath.MinInt64)
+	assert.False(t, ok)
+}
+
+func TestIsInt(t *testing.T) {
+	for _, n := range []interface{}{1, int8(1), uint64(1), "1", "-42", "0x10", "010"} {
+		assert.True(t, IsInt(n), "%#v", n)
+	}
+	for _, n := range []interface{}{nil, "", " 7 ", "1.5", 1.0, true, "18446744073709551616", uint64(gmath.MaxUint64)} {
+		assert.False(t, IsInt(n), "%#v", n)
+	}
+	assert.True(t, AllInts(1, "2", true))
+	assert.False(t, AllInts(1, 2.5))
 }
 
 func TestSeq(t *testing.T) {
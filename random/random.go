@@ -0,0 +1,174 @@
+// Package random contains functions for generating random values. By default
+// values come from crypto/rand, and are suitable for secrets, but a
+// deterministic (and insecure) source can be selected with Seed for
+// reproducible output in tests.
+package random
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"math/big"
+	mrand "math/rand"
+	"regexp/syntax"
+	"sync"
+	"unicode"
+)
+
+// Default charsets, as regexp character classes
+const (
+	ASCIIClass    = `[ -~]`
+	AlphaClass    = `[[:alpha:]]`
+	AlphaNumClass = `[[:alnum:]]`
+	DefaultClass  = `[a-zA-Z0-9_.-]`
+)
+
+var (
+	mu     sync.Mutex
+	seeded *mrand.Rand
+)
+
+// Seed - switch to a deterministic pseudo-random source seeded with the given
+// value, so the same sequence of values is generated on every run. The output
+// is predictable, so must not be used for secrets!
+func Seed(seed int64) {
+	mu.Lock()
+	defer mu.Unlock()
+	seeded = mrand.New(mrand.NewSource(seed))
+}
+
+// Read - fill p with random bytes from the current source
+func Read(p []byte) (int, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if seeded != nil {
+		return seeded.Read(p)
+	}
+	return crand.Read(p)
+}
+
+// intn - a random number in [0,n)
+func intn(n int64) (int64, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid range size %d", n)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if seeded != nil {
+		return seeded.Int63n(n), nil
+	}
+	i, err := crand.Int(crand.Reader, big.NewInt(n))
+	if err != nil {
+		return 0, err
+	}
+	return i.Int64(), nil
+}
+
+// StringRE - generate a string of count characters, chosen from those matched
+// by the given character class (like `[a-z]` or `[[:punct:]]`). A single
+// literal character, `.`, or `\d`-style escapes are also accepted.
+func StringRE(count int, class string) (string, error) {
+	ranges, err := classRanges(class)
+	if err != nil {
+		return "", err
+	}
+	return fromRanges(count, ranges)
+}
+
+// StringBounds - generate a string of count characters between lower and
+// upper (inclusive)
+func StringBounds(count int, lower, upper rune) (string, error) {
+	if upper < lower {
+		return "", fmt.Errorf("upper bound %q is less than lower bound %q", upper, lower)
+	}
+	return fromRanges(count, []rune{lower, upper})
+}
+
+// fromRanges - generate count characters from the given rune ranges, in the
+// same [lo, hi, lo, hi, ...] format used by regexp/syntax
+func fromRanges(count int, ranges []rune) (string, error) {
+	if count < 0 {
+		return "", fmt.Errorf("invalid count %d", count)
+	}
+	total := int64(0)
+	for i := 0; i < len(ranges); i += 2 {
+		total += int64(ranges[i+1]-ranges[i]) + 1
+	}
+	if total == 0 {
+		return "", fmt.Errorf("no characters to choose from")
+	}
+	out := make([]rune, count)
+	for i := range out {
+		n, err := intn(total)
+		if err != nil {
+			return "", err
+		}
+		for j := 0; j < len(ranges); j += 2 {
+			size := int64(ranges[j+1]-ranges[j]) + 1
+			if n < size {
+				out[i] = ranges[j] + rune(n)
+				break
+			}
+			n -= size
+		}
+	}
+	return string(out), nil
+}
+
+func classRanges(class string) ([]rune, error) {
+	re, err := syntax.Parse(class, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	switch re.Op {
+	case syntax.OpCharClass:
+		return re.Rune, nil
+	case syntax.OpLiteral:
+		if len(re.Rune) == 1 {
+			return []rune{re.Rune[0], re.Rune[0]}, nil
+		}
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		return []rune{0x20, 0x7e, 0xa0, unicode.MaxRune}, nil
+	}
+	return nil, fmt.Errorf("%q is not a single character class", class)
+}
+
+// Item - pick an element from the list at random
+func Item(items []interface{}) (interface{}, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("can't pick from an empty list")
+	}
+	n, err := intn(int64(len(items)))
+	if err != nil {
+		return nil, err
+	}
+	return items[n], nil
+}
+
+// Number - a random integer in [min, max]
+func Number(min, max int64) (int64, error) {
+	if min > max {
+		return 0, fmt.Errorf("min %d is greater than max %d", min, max)
+	}
+	size := max - min + 1
+	if size <= 0 {
+		return 0, fmt.Errorf("range %d-%d is too large", min, max)
+	}
+	n, err := intn(size)
+	if err != nil {
+		return 0, err
+	}
+	return min + n, nil
+}
+
+// Float - a random floating-point number in [min, max)
+func Float(min, max float64) (float64, error) {
+	if min > max {
+		return 0, fmt.Errorf("min %g is greater than max %g", min, max)
+	}
+	const precision = 1 << 53
+	n, err := intn(precision)
+	if err != nil {
+		return 0, err
+	}
+	return min + float64(n)/precision*(max-min), nil
+}
@@ -0,0 +1,123 @@
+package random
+
+import (
+	"regexp"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func unseed() {
+	mu.Lock()
+	seeded = nil
+	mu.Unlock()
+}
+
+func TestStringRE(t *testing.T) {
+	testdata := []struct {
+		class, match string
+	}{
+		{ASCIIClass, `^[ -~]{20}$`},
+		{AlphaClass, `^[a-zA-Z]{20}$`},
+		{AlphaNumClass, `^[a-zA-Z0-9]{20}$`},
+		{DefaultClass, `^[a-zA-Z0-9_.-]{20}$`},
+		{`[[:xdigit:]]`, `^[0-9a-fA-F]{20}$`},
+		{`\d`, `^[0-9]{20}$`},
+		{`x`, `^x{20}$`},
+	}
+	for _, d := range testdata {
+		out, err := StringRE(20, d.class)
+		assert.NoError(t, err)
+		assert.Regexp(t, regexp.MustCompile(d.match), out, d.class)
+	}
+
+	out, err := StringRE(0, AlphaClass)
+	assert.NoError(t, err)
+	assert.Equal(t, "", out)
+
+	out, err = StringRE(5, `[日本語]`)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, utf8.RuneCountInString(out))
+
+	_, err = StringRE(5, `[a-`)
+	assert.Error(t, err)
+	_, err = StringRE(5, `abc`)
+	assert.Error(t, err)
+	_, err = StringRE(-1, AlphaClass)
+	assert.Error(t, err)
+}
+
+func TestStringBounds(t *testing.T) {
+	out, err := StringBounds(10, 'a', 'c')
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[a-c]{10}$`, out)
+
+	out, err = StringBounds(3, 'z', 'z')
+	assert.NoError(t, err)
+	assert.Equal(t, "zzz", out)
+
+	_, err = StringBounds(3, 'z', 'a')
+	assert.Error(t, err)
+}
+
+func TestItem(t *testing.T) {
+	items := []interface{}{"a", "b", "c"}
+	for i := 0; i < 10; i++ {
+		out, err := Item(items)
+		assert.NoError(t, err)
+		assert.Contains(t, items, out)
+	}
+	_, err := Item(nil)
+	assert.Error(t, err)
+}
+
+func TestNumber(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		out, err := Number(-3, 3)
+		assert.NoError(t, err)
+		assert.True(t, out >= -3 && out <= 3, out)
+	}
+	out, err := Number(7, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), out)
+
+	_, err = Number(5, 1)
+	assert.Error(t, err)
+	_, err = Number(-1<<63, 1<<63-1)
+	assert.Error(t, err)
+}
+
+func TestFloat(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		out, err := Float(-1.5, 2.5)
+		assert.NoError(t, err)
+		assert.True(t, out >= -1.5 && out < 2.5, out)
+	}
+	_, err := Float(2, 1)
+	assert.Error(t, err)
+}
+
+func TestSeed(t *testing.T) {
+	defer unseed()
+
+	Seed(42)
+	s1, _ := StringRE(16, AlphaNumClass)
+	n1, _ := Number(0, 1000)
+	b1 := make([]byte, 8)
+	_, _ = Read(b1)
+
+	Seed(42)
+	s2, _ := StringRE(16, AlphaNumClass)
+	n2, _ := Number(0, 1000)
+	b2 := make([]byte, 8)
+	_, _ = Read(b2)
+
+	assert.Equal(t, s1, s2)
+	assert.Equal(t, n1, n2)
+	assert.Equal(t, b1, b2)
+
+	Seed(43)
+	s3, _ := StringRE(16, AlphaNumClass)
+	assert.NotEqual(t, s1, s3)
+}
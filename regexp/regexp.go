@@ -1,15 +1,125 @@
 package regexp
 
-import stdre "regexp"
+import (
+	stdre "regexp"
+	"sync"
+)
+
+var (
+	cache   = map[string]*stdre.Regexp{}
+	cacheMu sync.RWMutex
+)
+
+// compile - compile the expression, reusing a previously-compiled pattern
+// when possible, since templates often evaluate the same expression many
+// times (in a range, for example)
+func compile(expression string) (*stdre.Regexp, error) {
+	cacheMu.RLock()
+	re, ok := cache[expression]
+	cacheMu.RUnlock()
+	if ok {
+		return re, nil
+	}
+	re, err := stdre.Compile(expression)
+	if err != nil {
+		return nil, err
+	}
+	cacheMu.Lock()
+	cache[expression] = re
+	cacheMu.Unlock()
+	return re, nil
+}
 
 // Replace -
-func Replace(expression, replacement, input string) string {
-	re := stdre.MustCompile(expression)
-	return re.ReplaceAllString(input, replacement)
+func Replace(expression, replacement, input string) (string, error) {
+	re, err := compile(expression)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(input, replacement), nil
+}
+
+// ReplaceLiteral - like Replace, but the replacement is used literally,
+// without expanding `$` references to capture groups
+func ReplaceLiteral(expression, replacement, input string) (string, error) {
+	re, err := compile(expression)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllLiteralString(input, replacement), nil
 }
 
 // Match -
-func Match(expression, input string) bool {
-	re := stdre.MustCompile(expression)
-	return re.MatchString(input)
+func Match(expression, input string) (bool, error) {
+	re, err := compile(expression)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(input), nil
+}
+
+// Find - return the leftmost match, or an empty string if there is none
+func Find(expression, input string) (string, error) {
+	re, err := compile(expression)
+	if err != nil {
+		return "", err
+	}
+	return re.FindString(input), nil
+}
+
+// FindAll - return at most n successive matches, or all of them if n is
+// negative
+func FindAll(expression string, n int, input string) ([]string, error) {
+	re, err := compile(expression)
+	if err != nil {
+		return nil, err
+	}
+	out := re.FindAllString(input, n)
+	if out == nil {
+		out = []string{}
+	}
+	return out, nil
+}
+
+// FindSubmatch - return the values of the named capture groups in the leftmost
+// match, along with the group names in the order they appear in the
+// expression. Groups which didn't participate in the match are empty. The map
+// is empty when there is no match.
+func FindSubmatch(expression, input string) (map[string]string, []string, error) {
+	re, err := compile(expression)
+	if err != nil {
+		return nil, nil, err
+	}
+	out := map[string]string{}
+	names := []string{}
+	m := re.FindStringSubmatch(input)
+	if m == nil {
+		return out, names, nil
+	}
+	for i, name := range re.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if _, ok := out[name]; !ok {
+			names = append(names, name)
+		}
+		out[name] = m[i]
+	}
+	return out, names, nil
+}
+
+// Split - split the input into substrings separated by the expression,
+// returning at most n substrings (or all of them if n is negative)
+func Split(expression string, n int, input string) ([]string, error) {
+	re, err := compile(expression)
+	if err != nil {
+		return nil, err
+	}
+	return re.Split(input, n), nil
+}
+
+// QuoteMeta - escape all regular expression metacharacters in the input, so
+// it can be used to match the literal text
+func QuoteMeta(input string) string {
+	return stdre.QuoteMeta(input)
 }
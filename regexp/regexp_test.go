@@ -7,17 +7,134 @@ import (
 )
 
 func TestReplace(t *testing.T) {
-	assert.Equal(t, "-T-T-", Replace("a(x*)b", "T", "-ab-axxb-"))
-	assert.Equal(t, "--xx-", Replace("a(x*)b", "$1", "-ab-axxb-"))
-	assert.Equal(t, "---", Replace("a(x*)b", "$1W", "-ab-axxb-"))
-	assert.Equal(t, "-W-xxW-", Replace("a(x*)b", "${1}W", "-ab-axxb-"))
+	testdata := []struct {
+		expected, expression, replacement, input string
+	}{
+		{"-T-T-", "a(x*)b", "T", "-ab-axxb-"},
+		{"--xx-", "a(x*)b", "$1", "-ab-axxb-"},
+		{"---", "a(x*)b", "$1W", "-ab-axxb-"},
+		{"-W-xxW-", "a(x*)b", "${1}W", "-ab-axxb-"},
+		{"Turing, Alan", "(?P<first>[a-zA-Z]+) (?P<last>[a-zA-Z]+)", "${last}, ${first}", "Alan Turing"},
+	}
+	for _, d := range testdata {
+		out, err := Replace(d.expression, d.replacement, d.input)
+		assert.NoError(t, err)
+		assert.Equal(t, d.expected, out)
+	}
 
-	assert.Equal(t, "Turing, Alan", Replace("(?P<first>[a-zA-Z]+) (?P<last>[a-zA-Z]+)", "${last}, ${first}", "Alan Turing"))
+	_, err := Replace("(", "", "foo")
+	assert.Error(t, err)
+}
+
+func TestReplaceLiteral(t *testing.T) {
+	out, err := ReplaceLiteral("a(x*)b", "${1}", "-ab-axxb-")
+	assert.NoError(t, err)
+	assert.Equal(t, "-${1}-${1}-", out)
+
+	_, err = ReplaceLiteral("(", "", "foo")
+	assert.Error(t, err)
 }
 
 func TestMatch(t *testing.T) {
-	assert.True(t, Match(`^[a-z]+\[[0-9]+\]$`, "adam[23]"))
-	assert.True(t, Match(`^[a-z]+\[[0-9]+\]$`, "eve[7]"))
-	assert.False(t, Match(`^[a-z]+\[[0-9]+\]$`, "Job[48]"))
-	assert.False(t, Match(`^[a-z]+\[[0-9]+\]$`, "snakey"))
+	testdata := []struct {
+		expected bool
+		input    string
+	}{
+		{true, "adam[23]"},
+		{true, "eve[7]"},
+		{false, "Job[48]"},
+		{false, "snakey"},
+	}
+	for _, d := range testdata {
+		out, err := Match(`^[a-z]+\[[0-9]+\]$`, d.input)
+		assert.NoError(t, err)
+		assert.Equal(t, d.expected, out, d.input)
+	}
+
+	_, err := Match("[a-", "foo")
+	assert.Error(t, err)
+}
+
+func TestFind(t *testing.T) {
+	out, err := Find("[0-9]+", "abc123def456")
+	assert.NoError(t, err)
+	assert.Equal(t, "123", out)
+
+	out, err = Find("[0-9]+", "abcdef")
+	assert.NoError(t, err)
+	assert.Equal(t, "", out)
+
+	_, err = Find("*", "foo")
+	assert.Error(t, err)
+}
+
+func TestFindAll(t *testing.T) {
+	out, err := FindAll("[0-9]+", -1, "a1b22c333")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "22", "333"}, out)
+
+	out, err = FindAll("[0-9]+", 2, "a1b22c333")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "22"}, out)
+
+	out, err = FindAll("[0-9]+", -1, "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, out)
+
+	_, err = FindAll("(", -1, "foo")
+	assert.Error(t, err)
+}
+
+func TestFindSubmatch(t *testing.T) {
+	out, names, err := FindSubmatch(`(?P<key>\w+)=(?P<value>\w*)(?P<comment>#.*)?`, "foo=bar")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"key": "foo", "value": "bar", "comment": ""}, out)
+	assert.Equal(t, []string{"key", "value", "comment"}, names)
+
+	out, names, err = FindSubmatch(`(?P<key>\w+)=`, "nothing here")
+	assert.NoError(t, err)
+	assert.Empty(t, out)
+	assert.Empty(t, names)
+
+	out, _, err = FindSubmatch(`(\w+)-(?P<n>\d+)`, "node-42")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"n": "42"}, out)
+
+	_, _, err = FindSubmatch("(?P<", "foo")
+	assert.Error(t, err)
+}
+
+func TestSplit(t *testing.T) {
+	out, err := Split(`\s*,\s*`, -1, "a , b,c ,  d")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, out)
+
+	out, err = Split(`\s*,\s*`, 2, "a , b,c")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b,c"}, out)
+
+	_, err = Split("(", -1, "foo")
+	assert.Error(t, err)
+}
+
+func TestQuoteMeta(t *testing.T) {
+	assert.Equal(t, `1\.2\.3\+build\[1\]`, QuoteMeta("1.2.3+build[1]"))
+	out, err := Match("^"+QuoteMeta("a.b")+"$", "axb")
+	assert.NoError(t, err)
+	assert.False(t, out)
+}
+
+func TestCompileCache(t *testing.T) {
+	re1, err := compile("foo+")
+	assert.NoError(t, err)
+	re2, err := compile("foo+")
+	assert.NoError(t, err)
+	assert.True(t, re1 == re2)
+
+	_, err = compile("foo(")
+	assert.Error(t, err)
+	cacheMu.RLock()
+	_, ok := cache["foo("]
+	cacheMu.RUnlock()
+	assert.False(t, ok)
 }
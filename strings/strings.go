@@ -1,6 +1,10 @@
 package strings
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
 
 // Indent - indent each line of the string with the given indent string
 func Indent(width int, indent, s string) string {
@@ -19,3 +23,154 @@ func Indent(width int, indent, s string) string {
 	}
 	return string(res)
 }
+
+// ShellQuote - quote the string for safe use as a single word in a POSIX
+// shell command line
+func ShellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'"'"'`, -1) + "'"
+}
+
+// Truncate - shorten the string to at most length characters (runes)
+func Truncate(length int, s string) string {
+	if length < 0 {
+		length = 0
+	}
+	r := []rune(s)
+	if len(r) <= length {
+		return s
+	}
+	return string(r[:length])
+}
+
+// Abbrev - abbreviate the string to at most width characters, using an
+// ellipsis ("...") to show where text was removed. When offset is greater than
+// 0, text is also removed from the start of the string so that the character
+// at offset is included. The width must be at least 4 (or 7 when the start is
+// abbreviated too), and an error is returned otherwise.
+func Abbrev(offset, width int, s string) (string, error) {
+	if width < 4 {
+		return "", fmt.Errorf("width must be at least 4, got %d", width)
+	}
+	if offset > 4 && width < 7 {
+		return "", fmt.Errorf("width must be at least 7 when offset is given, got %d", width)
+	}
+	return abbrev(offset, width, []rune(s)), nil
+}
+
+// abbrev - Abbrev, for widths that are known to be valid
+func abbrev(offset, width int, r []rune) string {
+	const ellipsis = "..."
+	if len(r) <= width {
+		return string(r)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(r) {
+		offset = len(r)
+	}
+	if len(r)-offset < width-3 {
+		offset = len(r) - (width - 3)
+	}
+	if offset <= 4 {
+		return string(r[:width-3]) + ellipsis
+	}
+	if offset+width-3 < len(r) {
+		return ellipsis + abbrev(0, width-3, r[offset:])
+	}
+	return ellipsis + string(r[len(r)-(width-3):])
+}
+
+// WordWrap - insert line breaks (lbseq) so that no line is longer than width
+// characters, breaking on whitespace. Words longer than width aren't broken.
+// Existing line breaks are kept.
+func WordWrap(width int, lbseq, s string) string {
+	out := []string{}
+	for _, line := range strings.Split(s, "\n") {
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			out = append(out, "")
+			continue
+		}
+		cur := words[0]
+		curLen := len([]rune(cur))
+		for _, w := range words[1:] {
+			wl := len([]rune(w))
+			if curLen+1+wl > width {
+				out = append(out, cur)
+				cur, curLen = w, wl
+				continue
+			}
+			cur += " " + w
+			curLen += 1 + wl
+		}
+		out = append(out, cur)
+	}
+	return strings.Join(out, lbseq)
+}
+
+// Slug - convert the string to a form suitable for use in URLs and file
+// names: lower-case letters and digits, with runs of anything else replaced
+// by single hyphens
+func Slug(s string) string {
+	return strings.Join(lowerWords(splitWords(s)), "-")
+}
+
+// CamelCase - convert the string to camelCase, removing spaces, hyphens,
+// underscores and other punctuation
+func CamelCase(s string) string {
+	words := lowerWords(splitWords(s))
+	for i := 1; i < len(words); i++ {
+		r := []rune(words[i])
+		words[i] = strings.ToUpper(string(r[0])) + string(r[1:])
+	}
+	return strings.Join(words, "")
+}
+
+// SnakeCase - convert the string to snake_case
+func SnakeCase(s string) string {
+	return strings.Join(lowerWords(splitWords(s)), "_")
+}
+
+// KebabCase - convert the string to kebab-case
+func KebabCase(s string) string {
+	return strings.Join(lowerWords(splitWords(s)), "-")
+}
+
+func lowerWords(words []string) []string {
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return words
+}
+
+// splitWords - split the string into words at any character that isn't a
+// letter or a digit, and at changes in case (so "HTTPServerName" becomes
+// "HTTP", "Server", "Name")
+func splitWords(s string) []string {
+	words := []string{}
+	cur := []rune{}
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, string(cur))
+			cur = []rune{}
+		}
+	}
+	r := []rune(s)
+	for i, c := range r {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(c) && len(cur) > 0 {
+			prev := cur[len(cur)-1]
+			nextLower := i+1 < len(r) && unicode.IsLower(r[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		cur = append(cur, c)
+	}
+	flush()
+	return words
+}
@@ -15,3 +15,89 @@ func TestIndent(t *testing.T) {
 	assert.Equal(t, "   foo", Indent(1, "   ", "foo"))
 	assert.Equal(t, "   foo", Indent(3, " ", "foo"))
 }
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, "''", ShellQuote(""))
+	assert.Equal(t, "'foo bar'", ShellQuote("foo bar"))
+	assert.Equal(t, `'it'"'"'s'`, ShellQuote("it's"))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "", Truncate(-1, "foo"))
+	assert.Equal(t, "", Truncate(0, "foo"))
+	assert.Equal(t, "fo", Truncate(2, "foo"))
+	assert.Equal(t, "foo", Truncate(5, "foo"))
+	assert.Equal(t, "日本", Truncate(2, "日本語"))
+}
+
+func mustAbbrev(t *testing.T, offset, width int, s string) string {
+	out, err := Abbrev(offset, width, s)
+	assert.NoError(t, err)
+	return out
+}
+
+func TestAbbrev(t *testing.T) {
+	assert.Equal(t, "abcdefg", mustAbbrev(t, 0, 7, "abcdefg"))
+	assert.Equal(t, "abc...", mustAbbrev(t, 0, 6, "abcdefg"))
+	assert.Equal(t, "abcdefg...", mustAbbrev(t, 0, 10, "abcdefghijklmno"))
+	assert.Equal(t, "abcdefg...", mustAbbrev(t, 4, 10, "abcdefghijklmno"))
+	assert.Equal(t, "...fghi...", mustAbbrev(t, 5, 10, "abcdefghijklmno"))
+	assert.Equal(t, "...ijklmno", mustAbbrev(t, 12, 10, "abcdefghijklmno"))
+	assert.Equal(t, "...ijklmno", mustAbbrev(t, 100, 10, "abcdefghijklmno"))
+	assert.Equal(t, "a...", mustAbbrev(t, -3, 4, "abcdefghij"))
+	assert.Equal(t, "...ghij", mustAbbrev(t, 6, 7, "abcdefghij"))
+	assert.Equal(t, "...f...", mustAbbrev(t, 5, 7, "abcdefghijk"))
+	assert.Equal(t, "héllo...", mustAbbrev(t, 0, 8, "héllo wörld"))
+
+	for _, d := range []struct{ offset, width int }{{5, 4}, {0, 3}, {0, -1}, {5, 6}, {100, 5}} {
+		_, err := Abbrev(d.offset, d.width, "abcdefghij")
+		assert.Error(t, err, "%d %d", d.offset, d.width)
+	}
+	_, err := Abbrev(5, 4, "abcdefghij")
+	assert.EqualError(t, err, "width must be at least 7 when offset is given, got 4")
+	_, err = Abbrev(0, 3, "abcdefghij")
+	assert.EqualError(t, err, "width must be at least 4, got 3")
+}
+
+func TestWordWrap(t *testing.T) {
+	assert.Equal(t, "", WordWrap(10, "\n", ""))
+	assert.Equal(t, "hello world", WordWrap(80, "\n", "hello world"))
+	assert.Equal(t, "hello\nworld", WordWrap(5, "\n", "hello world"))
+	assert.Equal(t, "the quick\nbrown fox\njumps over\nthe lazy\ndog",
+		WordWrap(10, "\n", "the quick brown fox jumps over the lazy dog"))
+	assert.Equal(t, "the quick \\\nbrown fox",
+		WordWrap(10, " \\\n", "the quick brown fox"))
+	assert.Equal(t, "a\nsupercalifragilistic\nword",
+		WordWrap(5, "\n", "a supercalifragilistic word"))
+	assert.Equal(t, "one two\nthree\n\nfour",
+		WordWrap(8, "\n", "one two three\n\nfour"))
+}
+
+func TestSlug(t *testing.T) {
+	assert.Equal(t, "", Slug(""))
+	assert.Equal(t, "hello-world", Slug("Hello, World!"))
+	assert.Equal(t, "foo-bar-baz", Slug("  foo_bar--baz  "))
+	assert.Equal(t, "http-server-2", Slug("HTTPServer 2"))
+	assert.Equal(t, "café-olé", Slug("Café Olé"))
+}
+
+func TestCaseConversion(t *testing.T) {
+	testdata := []struct {
+		in, camel, snake, kebab string
+	}{
+		{"", "", "", ""},
+		{"foo", "foo", "foo", "foo"},
+		{"Hello World", "helloWorld", "hello_world", "hello-world"},
+		{"hello_world-foo bar", "helloWorldFooBar", "hello_world_foo_bar", "hello-world-foo-bar"},
+		{"HelloWorld", "helloWorld", "hello_world", "hello-world"},
+		{"helloWorld", "helloWorld", "hello_world", "hello-world"},
+		{"HTTPServerName", "httpServerName", "http_server_name", "http-server-name"},
+		{"version2Beta", "version2Beta", "version2_beta", "version2-beta"},
+		{"ALL_CAPS", "allCaps", "all_caps", "all-caps"},
+	}
+	for _, d := range testdata {
+		assert.Equal(t, d.camel, CamelCase(d.in), d.in)
+		assert.Equal(t, d.snake, SnakeCase(d.in), d.in)
+		assert.Equal(t, d.kebab, KebabCase(d.in), d.in)
+	}
+}
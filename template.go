@@ -6,8 +6,14 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"text/template"
 
+	"github.com/hairyhenderson/gomplate/data"
+	"github.com/hairyhenderson/gomplate/file"
+	"github.com/hairyhenderson/gomplate/funcs"
+	"github.com/hairyhenderson/gomplate/ignore"
 	"github.com/spf13/afero"
 )
 
@@ -18,17 +24,102 @@ var fs = afero.NewOsFs()
 
 // tplate - models a tplate file...
 type tplate struct {
-	name     string
-	target   io.Writer
-	contents string
+	name        string
+	target      io.Writer
+	targetPath  string
+	contents    string
+	frontMatter *frontMatter
 }
 
-func (t *tplate) toGoTemplate(g *Gomplate) (*template.Template, error) {
+// toGoTemplate - parse the template, with the data functions using d instead of
+// the datasources the Gomplate's functions were set up with, if it's not nil
+func (t *tplate) toGoTemplate(g *Gomplate, d *data.Data, ctx interface{}) (*template.Template, error) {
+	leftDelim, rightDelim := t.delims(g)
+	contents := t.contents
+	if fm := t.frontMatter; fm != nil {
+		contents = fm.comment(leftDelim, rightDelim) + contents
+	}
 	tmpl := template.New(t.name)
-	tmpl.Option("missingkey=error")
-	tmpl.Funcs(g.funcMap)
-	tmpl.Delims(g.leftDelim, g.rightDelim)
-	return tmpl.Parse(t.contents)
+	tmpl.Option("missingkey=" + g.missingKeyMode())
+	// the tmpl namespace (and the data functions, when the template defines
+	// its own datasources) are specific to each template, so it needs its own
+	// copy of the function map
+	funcMap := template.FuncMap{}
+	for k, v := range g.funcMap {
+		funcMap[k] = v
+	}
+	if d != nil {
+		funcs.AddDataFuncs(funcMap, d)
+	}
+	funcs.AddTmplFuncs(funcMap, tmpl, t.name, t.path(), ctx)
+	tmpl.Funcs(funcMap)
+	tmpl.Delims(leftDelim, rightDelim)
+	if _, err := tmpl.Parse(contents); err != nil {
+		return nil, newParseError(t, leftDelim, rightDelim, contents, err)
+	}
+	return tmpl, nil
+}
+
+// delims - the delimiters for the template, which may be set in its front
+// matter
+func (t *tplate) delims(g *Gomplate) (string, string) {
+	if fm := t.frontMatter; fm != nil && fm.LeftDelim != "" {
+		return fm.LeftDelim, fm.RightDelim
+	}
+	return g.leftDelim, g.rightDelim
+}
+
+// path - the template's file path, or "" when it wasn't read from a file
+func (t *tplate) path() string {
+	if t.name == "<arg>" || t.name == "-" {
+		return ""
+	}
+	return t.name
+}
+
+// sourceLine - the given (1-based) line of the template file, if it's not
+// part of the front matter
+func (t *tplate) sourceLine(line int) (string, bool) {
+	if t.frontMatter != nil {
+		line -= t.frontMatter.lines
+	}
+	lines := strings.Split(t.contents, "\n")
+	if line < 1 || line > len(lines) {
+		return "", false
+	}
+	return strings.TrimRight(lines[line-1], "\r"), true
+}
+
+// column - the (1-based) column in the template file, given the column in
+// the text that was parsed. The front matter is replaced with a comment, and
+// the end of it shares the first line after the front matter.
+func (t *tplate) column(line, col int, rightDelim string) int {
+	if fm := t.frontMatter; fm != nil && line == fm.lines+1 && col > 0 {
+		col -= len("*/" + rightDelim)
+	}
+	return col
+}
+
+// location - convert a `name:line:col` location from text/template, where
+// the column is 0-based and relative to the parsed text, to a location in the
+// template file, with a 1-based column
+func (t *tplate) location(loc, rightDelim string) string {
+	parts := strings.Split(loc, ":")
+	if len(parts) < 3 {
+		return loc
+	}
+	n := len(parts)
+	line, lerr := strconv.Atoi(parts[n-2])
+	col, cerr := strconv.Atoi(parts[n-1])
+	if lerr != nil || cerr != nil {
+		return loc
+	}
+	name := strings.Join(parts[:n-2], ":")
+	col++
+	if name == t.name {
+		col = t.column(line, col, rightDelim)
+	}
+	return fmt.Sprintf("%s:%d:%d", name, line, col)
 }
 
 // loadContents - reads the template in _once_ if it hasn't yet been read. Uses the name!
@@ -39,7 +130,25 @@ func (t *tplate) loadContents() (err error) {
 	return err
 }
 
+// parseFrontMatter - strip the front matter (if any) from the contents
+func (t *tplate) parseFrontMatter() error {
+	fm, contents, err := splitFrontMatter(t.contents)
+	if err != nil {
+		return fmt.Errorf("invalid front matter in %s: %v", t.name, err)
+	}
+	if fm == nil {
+		return nil
+	}
+	if err := fm.validate(t.name); err != nil {
+		return fmt.Errorf("invalid front matter in %s: %v", t.name, err)
+	}
+	t.frontMatter = fm
+	t.contents = contents
+	return nil
+}
+
 func (t *tplate) addTarget(outFile string) error {
+	t.targetPath = outFile
 	if t.target == nil {
 		target, err := openOutFile(outFile)
 		if err != nil {
@@ -54,8 +163,10 @@ func (t *tplate) addTarget(outFile string) error {
 	return nil
 }
 
-// gatherTemplates - gather and prepare input template(s) and output file(s) for rendering
-func gatherTemplates(o *GomplateOpts) (templates []*tplate, err error) {
+// gatherTemplates - gather and prepare input template(s) and output file(s) for
+// rendering, along with the files to copy as-is (in input-dir mode), which
+// aren't copied until the templates have been rendered
+func gatherTemplates(o *GomplateOpts) (templates []*tplate, copies []verbatimFile, err error) {
 	// the arg-provided input string gets a special name
 	if o.input != "" {
 		templates = []*tplate{{
@@ -66,9 +177,9 @@ func gatherTemplates(o *GomplateOpts) (templates []*tplate, err error) {
 
 	// input dirs presume output dirs are set too
 	if o.inputDir != "" {
-		o.inputFiles, o.outputFiles, err = walkDir(o.inputDir, o.outputDir, o.excludeGlob)
+		o.inputFiles, o.outputFiles, copies, err = walkDir(o.inputDir, o.outputDir, o.excludeGlob, o.includeGlob, o.verbatimGlob)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
@@ -85,83 +196,260 @@ func gatherTemplates(o *GomplateOpts) (templates []*tplate, err error) {
 
 	for i, t := range templates {
 		if err := t.loadContents(); err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+
+		if err := t.parseFrontMatter(); err != nil {
+			return nil, nil, err
+		}
+
+		outFile := o.outputFiles[i]
+		if t.frontMatter != nil && t.frontMatter.Out != "" {
+			// an output file given with --out isn't silently overridden
+			if o.inputDir == "" && outFile != "-" {
+				return nil, nil, fmt.Errorf("invalid front matter in %s: out can't be set when --out is given", t.name)
+			}
+			outFile, err = frontMatterOutFile(o.outputDir, t.frontMatter.Out)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid front matter in %s: %v", t.name, err)
+			}
 		}
 
-		if err := t.addTarget(o.outputFiles[i]); err != nil {
-			return nil, err
+		if err := t.addTarget(outFile); err != nil {
+			return nil, nil, err
 		}
+
+		if t.frontMatter != nil && t.frontMatter.Mode != "" && outFile != "-" {
+			if err := fs.Chmod(outFile, os.FileMode(t.frontMatter.mode)); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return templates, copies, nil
+}
+
+// frontMatterOutFile - the output path set in a template's front matter,
+// relative to the output directory (which it can't be outside of)
+func frontMatterOutFile(outputDir, out string) (string, error) {
+	w, err := file.NewWriter(fs, outputDir)
+	if err != nil {
+		return "", err
+	}
+	p, err := w.Resolve(out)
+	if err != nil {
+		return "", err
+	}
+	if err := fs.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return "", err
+	}
+	return p, nil
+}
+
+// ignoreFile - the name of the files listing paths (in gitignore syntax)
+// that walkDir should skip
+const ignoreFile = ".gomplateignore"
+
+// walkDir - given an input dir `dir` and an output dir `outDir`, and lists
+// of exclude, include and verbatim patterns (if any), walk the input directory
+// and create a list of input and output files, a list of files to be copied
+// as-is, and an error, if any.
+//
+// Patterns use gitignore syntax, and `.gomplateignore` files found along the
+// way are honoured for the directory they're in and everything below it.
+// Files matching a verbatim pattern, binary files, and symbolic links to
+// directories are copied rather than rendered.
+func walkDir(dir, outDir string, excludes, includes, verbatim []string) ([]string, []string, []verbatimFile, error) {
+	w := newDirWalker(dir, excludes, includes, verbatim)
+	if err := w.walk(dir, outDir, nil); err != nil {
+		return nil, nil, nil, err
+	}
+	return w.inFiles, w.outFiles, w.copies, nil
+}
+
+// listTemplates - like walkDir, but only lists the templates found in the
+// input directory, without creating anything
+func listTemplates(dir string, excludes, includes, verbatim []string) ([]string, error) {
+	w := newDirWalker(dir, excludes, includes, verbatim)
+	w.readOnly = true
+	if err := w.walk(dir, dir, nil); err != nil {
+		return nil, err
+	}
+	return w.inFiles, nil
+}
+
+// argPatterns - patterns given on the commandline, which can be relative to
+// the input directory, or to the current directory
+type argPatterns struct {
+	rel *ignore.Matcher
+	abs *ignore.Matcher
+}
+
+func newArgPatterns(dir string, patterns []string) argPatterns {
+	return argPatterns{
+		rel: ignore.New(dir, patterns...),
+		abs: ignore.New("", patterns...),
 	}
+}
+
+func (a argPatterns) Match(p string, isDir bool) bool {
+	return a.rel.Match(p, isDir) || a.abs.Match(p, isDir)
+}
+
+func (a argPatterns) Empty() bool {
+	return a.abs.Empty()
+}
+
+type dirWalker struct {
+	excludes argPatterns
+	includes argPatterns
+	verbatim argPatterns
+
+	// when set, output directories aren't created
+	readOnly bool
 
-	return templates, nil
+	inFiles  []string
+	outFiles []string
+	copies   []verbatimFile
 }
 
-// walkDir - given an input dir `dir` and an output dir `outDir`, and a list
-// of exclude globs (if any), walk the input directory and create a list of
-// input and output files, and an error, if any.
-func walkDir(dir, outDir string, excludeGlob []string) ([]string, []string, error) {
+func newDirWalker(dir string, excludes, includes, verbatim []string) *dirWalker {
+	return &dirWalker{
+		excludes: newArgPatterns(dir, excludes),
+		includes: newArgPatterns(dir, includes),
+		verbatim: newArgPatterns(dir, verbatim),
+		inFiles:  []string{},
+		outFiles: []string{},
+	}
+}
+
+func (w *dirWalker) walk(dir, outDir string, ignores *ignore.Matcher) error {
 	dir = filepath.Clean(dir)
 	outDir = filepath.Clean(outDir)
 
 	si, err := fs.Stat(dir)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
 	entries, err := afero.ReadDir(fs, dir)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
-	if err = fs.MkdirAll(outDir, si.Mode()); err != nil {
-		return nil, nil, err
+	if !w.readOnly {
+		if err = fs.MkdirAll(outDir, si.Mode()); err != nil {
+			return err
+		}
 	}
 
-	excludes, err := executeCombinedGlob(excludeGlob)
+	ignores, err = loadIgnoreFile(dir, ignores)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
-	inFiles := []string{}
-	outFiles := []string{}
 	for _, entry := range entries {
 		nextInPath := filepath.Join(dir, entry.Name())
 		nextOutPath := filepath.Join(outDir, entry.Name())
 
-		if inList(excludes, nextInPath) {
+		if w.skip(nextInPath, entry, ignores) {
 			continue
 		}
 
 		if entry.IsDir() {
-			i, o, err := walkDir(nextInPath, nextOutPath, excludes)
-			if err != nil {
-				return nil, nil, err
+			if err := w.walk(nextInPath, nextOutPath, ignores); err != nil {
+				return err
 			}
-			inFiles = append(inFiles, i...)
-			outFiles = append(outFiles, o...)
+			continue
+		}
+
+		verbatim, err := w.isVerbatim(nextInPath, entry)
+		if err != nil {
+			return err
+		}
+		if verbatim {
+			w.copies = append(w.copies, verbatimFile{in: nextInPath, out: nextOutPath, info: entry})
 		} else {
-			inFiles = append(inFiles, nextInPath)
-			outFiles = append(outFiles, nextOutPath)
+			w.inFiles = append(w.inFiles, nextInPath)
+			w.outFiles = append(w.outFiles, nextOutPath)
 		}
 	}
-	return inFiles, outFiles, nil
+	return nil
 }
 
-func inList(list []string, entry string) bool {
-	for _, file := range list {
-		if file == entry {
-			return true
+// isVerbatim - whether the file at path p should be copied as-is
+func (w *dirWalker) isVerbatim(p string, entry os.FileInfo) (bool, error) {
+	if w.verbatim.Match(p, false) {
+		return true, nil
+	}
+	if isSymlink(entry) {
+		fi, err := fs.Stat(p)
+		if err != nil {
+			return false, err
 		}
+		if fi.IsDir() {
+			return true, nil
+		}
+	}
+	return isBinary(p)
+}
+
+// skip - whether the entry at path p should be left out. Excluded or ignored
+// directories aren't descended into, and when there are include patterns,
+// only files matching one of them are kept.
+func (w *dirWalker) skip(p string, entry os.FileInfo, ignores *ignore.Matcher) bool {
+	isDir := entry.IsDir()
+	if !isDir && entry.Name() == ignoreFile {
+		return true
+	}
+	if w.excludes.Match(p, isDir) || ignores.Match(p, isDir) {
+		return true
 	}
+	return !isDir && !w.includes.Empty() && !w.includes.Match(p, false)
+}
 
-	return false
+// loadIgnoreFile - add the patterns from the ignore file in dir (if there is
+// one) to those inherited from the parent directories
+func loadIgnoreFile(dir string, parent *ignore.Matcher) (*ignore.Matcher, error) {
+	b, err := afero.ReadFile(fs, filepath.Join(dir, ignoreFile))
+	if os.IsNotExist(err) {
+		return parent, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parent.Parse(dir, string(b)), nil
 }
 
 func openOutFile(filename string) (out io.WriteCloser, err error) {
 	if filename == "-" {
 		return stdout, nil
 	}
-	return fs.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	f, err := fs.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &outFile{f}, nil
+}
+
+// outFile - an output file, which is created when it's opened but isn't
+// truncated until the output is ready to be written (see truncateTarget), so
+// existing contents survive templates which fail
+type outFile struct {
+	afero.File
+}
+
+// truncateTarget - empty the template's output file (if it is one), ready for
+// the output to be written
+func (t *tplate) truncateTarget() error {
+	if f, ok := t.target.(*outFile); ok {
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		_, err := f.Seek(0, io.SeekStart)
+		return err
+	}
+	return nil
 }
 
 func readInput(filename string) (string, error) {
@@ -184,19 +472,3 @@ func readInput(filename string) (string, error) {
 	}
 	return string(bytes), nil
 }
-
-// takes an array of glob strings and executes it as a whole,
-// returning a merged list of globbed files
-func executeCombinedGlob(globArray []string) ([]string, error) {
-	var combinedExcludes []string
-	for _, glob := range globArray {
-		excludeList, err := afero.Glob(fs, glob)
-		if err != nil {
-			return nil, err
-		}
-
-		combinedExcludes = append(combinedExcludes, excludeList...)
-	}
-
-	return combinedExcludes, nil
-}
@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/spf13/afero"
 
@@ -68,55 +69,108 @@ func TestOpenOutFile(t *testing.T) {
 	assert.Equal(t, stdout, f)
 }
 
-func TestInList(t *testing.T) {
-	list := []string{}
-	assert.False(t, inList(list, ""))
+func TestWalkDir(t *testing.T) {
+	origfs := fs
+	defer func() { fs = origfs }()
+	fs = afero.NewMemMapFs()
+
+	_, _, _, err := walkDir("/indir", "/outdir", nil, nil, nil)
+	assert.Error(t, err)
 
-	list = nil
-	assert.False(t, inList(list, ""))
+	_ = fs.MkdirAll("/indir/one", 0777)
+	_ = fs.MkdirAll("/indir/two", 0777)
+	afero.WriteFile(fs, "/indir/one/foo", []byte("foo"), 0644)
+	afero.WriteFile(fs, "/indir/one/bar", []byte("bar"), 0644)
+	afero.WriteFile(fs, "/indir/two/baz", []byte("baz"), 0644)
 
-	list = []string{"foo", "baz", "qux"}
-	assert.False(t, inList(list, "bar"))
+	in, out, _, err := walkDir("/indir", "/outdir", []string{"/*/two"}, nil, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/indir/one/bar", "/indir/one/foo"}, in)
+	assert.Equal(t, []string{"/outdir/one/bar", "/outdir/one/foo"}, out)
+
+	in, _, _, err = walkDir("/indir", "/outdir", []string{"**/ba?"}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/indir/one/foo"}, in)
+
+	in, _, _, err = walkDir("/indir", "/outdir", nil, []string{"ba*"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/indir/one/bar", "/indir/two/baz"}, in)
+
+	in, _, _, err = walkDir("/indir", "/outdir", []string{"two/"}, []string{"ba*"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/indir/one/bar"}, in)
 
-	list = []string{"foo", "bar", "baz"}
-	assert.True(t, inList(list, "bar"))
+	// patterns can be relative to the input directory
+	in, _, _, err = walkDir("/indir", "/outdir", []string{"/one/b*"}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/indir/one/foo", "/indir/two/baz"}, in)
 }
 
-func TestExecuteCombinedGlob(t *testing.T) {
+func TestWalkDirIgnoreFiles(t *testing.T) {
 	origfs := fs
 	defer func() { fs = origfs }()
 	fs = afero.NewMemMapFs()
-	_ = fs.MkdirAll("/tmp/one", 0777)
-	_ = fs.MkdirAll("/tmp/two", 0777)
-	_ = fs.MkdirAll("/tmp/three", 0777)
-	afero.WriteFile(fs, "/tmp/one/a", []byte("file a"), 0644)
-	afero.WriteFile(fs, "/tmp/two/b", []byte("file b"), 0644)
-	afero.WriteFile(fs, "/tmp/three/c", []byte("file c"), 0644)
 
-	excludes, err := executeCombinedGlob([]string{"/tmp/o*/*", "/*/*/b"})
+	_ = fs.MkdirAll("/indir/one/sub", 0777)
+	_ = fs.MkdirAll("/indir/two", 0777)
+	_ = fs.MkdirAll("/indir/build", 0777)
+	afero.WriteFile(fs, "/indir/.gomplateignore", []byte("# comment\n*.bak\n/build/\n!keep.bak\n"), 0644)
+	afero.WriteFile(fs, "/indir/a.bak", []byte(""), 0644)
+	afero.WriteFile(fs, "/indir/keep.bak", []byte(""), 0644)
+	afero.WriteFile(fs, "/indir/build/out", []byte(""), 0644)
+	afero.WriteFile(fs, "/indir/one/.gomplateignore", []byte("sub/*.txt\n!/b.bak\n"), 0644)
+	afero.WriteFile(fs, "/indir/one/b.bak", []byte(""), 0644)
+	afero.WriteFile(fs, "/indir/one/sub/c.bak", []byte(""), 0644)
+	afero.WriteFile(fs, "/indir/one/sub/d.txt", []byte(""), 0644)
+	afero.WriteFile(fs, "/indir/one/sub/e.tmpl", []byte(""), 0644)
+	afero.WriteFile(fs, "/indir/two/d.txt", []byte(""), 0644)
+	afero.WriteFile(fs, "/indir/two/f.bak", []byte(""), 0644)
+
+	in, out, _, err := walkDir("/indir", "/outdir", nil, nil, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, []string{"/tmp/one/a", "/tmp/two/b"}, excludes)
+	assert.Equal(t, []string{
+		"/indir/keep.bak",
+		"/indir/one/b.bak",
+		"/indir/one/sub/e.tmpl",
+		"/indir/two/d.txt",
+	}, in)
+	assert.Equal(t, []string{
+		"/outdir/keep.bak",
+		"/outdir/one/b.bak",
+		"/outdir/one/sub/e.tmpl",
+		"/outdir/two/d.txt",
+	}, out)
+
+	// ignored directories aren't created in the output
+	_, err = fs.Stat("/outdir/build")
+	assert.True(t, os.IsNotExist(err))
 }
 
-func TestWalkDir(t *testing.T) {
+func TestWalkDirVerbatim(t *testing.T) {
 	origfs := fs
 	defer func() { fs = origfs }()
 	fs = afero.NewMemMapFs()
 
-	_, _, err := walkDir("/indir", "/outdir", nil)
-	assert.Error(t, err)
-
-	_ = fs.MkdirAll("/indir/one", 0777)
-	_ = fs.MkdirAll("/indir/two", 0777)
-	afero.WriteFile(fs, "/indir/one/foo", []byte("foo"), 0644)
-	afero.WriteFile(fs, "/indir/one/bar", []byte("bar"), 0644)
-	afero.WriteFile(fs, "/indir/two/baz", []byte("baz"), 0644)
-
-	in, out, err := walkDir("/indir", "/outdir", []string{"/*/two"})
+	_ = fs.MkdirAll("/indir/img", 0777)
+	afero.WriteFile(fs, "/indir/a.tmpl", []byte("{{ 1 }}"), 0644)
+	afero.WriteFile(fs, "/indir/literal.txt", []byte("{{ not a template"), 0600)
+	afero.WriteFile(fs, "/indir/img/logo.png", []byte("\x89PNG\r\n\x1a\n\x00\x00"), 0644)
 
+	in, out, copies, err := walkDir("/indir", "/outdir", nil, nil, []string{"literal.*"})
 	assert.NoError(t, err)
-	assert.Equal(t, []string{"/indir/one/bar", "/indir/one/foo"}, in)
-	assert.Equal(t, []string{"/outdir/one/bar", "/outdir/one/foo"}, out)
+	assert.Equal(t, []string{"/indir/a.tmpl"}, in)
+	assert.Equal(t, []string{"/outdir/a.tmpl"}, out)
+	assert.Len(t, copies, 2)
+	assert.Equal(t, "/indir/img/logo.png", copies[0].in)
+	assert.Equal(t, "/outdir/img/logo.png", copies[0].out)
+	assert.Equal(t, "/indir/literal.txt", copies[1].in)
+	assert.Equal(t, "/outdir/literal.txt", copies[1].out)
+
+	// excluded files aren't copied either
+	_, _, copies, err = walkDir("/indir", "/outdir", []string{"*.png"}, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, copies, 0)
 }
 
 func TestLoadContents(t *testing.T) {
@@ -153,11 +207,11 @@ func TestGatherTemplates(t *testing.T) {
 	afero.WriteFile(fs, "in/2", []byte("bar"), 0644)
 	afero.WriteFile(fs, "in/3", []byte("baz"), 0644)
 
-	templates, err := gatherTemplates(&GomplateOpts{})
+	templates, _, err := gatherTemplates(&GomplateOpts{})
 	assert.NoError(t, err)
 	assert.Len(t, templates, 0)
 
-	templates, err = gatherTemplates(&GomplateOpts{
+	templates, _, err = gatherTemplates(&GomplateOpts{
 		input: "foo",
 	})
 	assert.NoError(t, err)
@@ -165,7 +219,7 @@ func TestGatherTemplates(t *testing.T) {
 	assert.Equal(t, "foo", templates[0].contents)
 	assert.Equal(t, stdout, templates[0].target)
 
-	templates, err = gatherTemplates(&GomplateOpts{
+	templates, _, err = gatherTemplates(&GomplateOpts{
 		inputFiles:  []string{"foo"},
 		outputFiles: []string{"out"},
 	})
@@ -174,11 +228,34 @@ func TestGatherTemplates(t *testing.T) {
 	assert.Equal(t, "bar", templates[0].contents)
 	assert.NotEqual(t, stdout, templates[0].target)
 
-	templates, err = gatherTemplates(&GomplateOpts{
+	templates, _, err = gatherTemplates(&GomplateOpts{
 		inputDir:  "in",
 		outputDir: "out",
 	})
 	assert.NoError(t, err)
 	assert.Len(t, templates, 3)
 	assert.Equal(t, "foo", templates[0].contents)
+
+	mtime := time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC)
+	afero.WriteFile(fs, "in/4", []byte("{{ qux"), 0600)
+	fs.Chtimes("in/4", mtime, mtime)
+	templates, copies, err := gatherTemplates(&GomplateOpts{
+		inputDir:     "in",
+		outputDir:    "out",
+		verbatimGlob: []string{"4"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, templates, 3)
+	// files are only copied once the templates are rendered
+	_, err = fs.Stat("out/4")
+	assert.True(t, os.IsNotExist(err))
+	assert.Len(t, copies, 1)
+	assert.NoError(t, copies[0].copy())
+	b, err := afero.ReadFile(fs, "out/4")
+	assert.NoError(t, err)
+	assert.Equal(t, "{{ qux", string(b))
+	fi, err := fs.Stat("out/4")
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+	assert.True(t, mtime.Equal(fi.ModTime()))
 }
@@ -0,0 +1,38 @@
+#!/usr/bin/env bats
+
+load helper
+
+tmpdir=$(mktemp -u)
+
+function setup () {
+  mkdir -p $tmpdir
+  echo '{"name": "app", "db": {"host": "localhost"}}' > $tmpdir/config.json
+}
+
+function teardown () {
+  rm -rf $tmpdir || true
+}
+
+@test "'--context' datasources are in the context" {
+  gomplate -c config=$tmpdir/config.json -i '{{ .config.name }} {{ .config.db.host }}'
+  [ "$status" -eq 0 ]
+  [[ "${output}" == "app localhost" ]]
+}
+
+@test "'--set' overrides values in the context" {
+  gomplate -c config=$tmpdir/config.json --set config.db.host=prod --set a.b=c -i '{{ .config.db.host }} {{ .a.b }}'
+  [ "$status" -eq 0 ]
+  [[ "${output}" == "prod c" ]]
+}
+
+@test "'.Template' describes the template" {
+  gomplate -i '{{ .Template.Name }} {{ .Template.Output }}'
+  [ "$status" -eq 0 ]
+  [[ "${output}" == "<arg> -" ]]
+}
+
+@test "errors given a reserved --set key" {
+  gomplate --set Env.FOO=bar -i 'hi'
+  [ "$status" -eq 1 ]
+  [[ "${output}" == *"Env is reserved"* ]]
+}
@@ -41,6 +41,13 @@ function teardown () {
 bar" ]]
 }
 
+@test "ordered datasources keep their key order" {
+  echo '{"zebra": 1, "apple": {"z": true, "a": false}}' > $tmpdir/config.json
+  gomplate -d config=file://$tmpdir/config.json?ordered=true -i '{{ ds "config" | toJSON }} {{ ((ds "config").Get "apple").Keys }} {{ (ds "config" | data.SortKeys).apple.a }}'
+  [ "$status" -eq 0 ]
+  [[ "${output}" == '{"zebra":1,"apple":{"z":true,"a":false}} [z a] false' ]]
+}
+
 @test "'include' doesn't parse file" {
   echo 'foo: bar' > $tmpdir/config.yml
   gomplate -d config=$tmpdir/config.yml -i '{{include "config"}}'
@@ -0,0 +1,49 @@
+#!/usr/bin/env bats
+
+load helper
+
+tmpdir=$(mktemp -d)
+
+function setup () {
+  cat <<"EOT" > $tmpdir/nested.tmpl
+{{ define "inner" }}
+  val: {{ ds "cfg" "missing.json" }}
+{{ end -}}
+{{ template "inner" . }}
+EOT
+}
+
+function teardown () {
+  rm -rf $tmpdir
+}
+
+@test "errors show the template source and datasource" {
+  gomplate -f $tmpdir/nested.tmpl -d cfg=$tmpdir/
+  [ "$status" -eq 1 ]
+  [[ "${output}" == *"Error: template: $tmpdir/nested.tmpl:2:11: error calling ds: Couldn't read datasource 'cfg'"* ]]
+  [[ "${output}" == *'  2 |   val: {{ ds "cfg" "missing.json" }}'* ]]
+  [[ "${output}" == *'    |           ^'* ]]
+  [[ "${output}" == *"in template \"inner\", called from $tmpdir/nested.tmpl:4:13"* ]]
+  [[ "${output}" == *"reading datasource 'cfg' with args [\"missing.json\"]"* ]]
+}
+
+@test "errors can be printed as JSON" {
+  run bash -c "bin/gomplate -i '{{ .nope }}' --error-format json 2>&1 >/dev/null"
+  [ "$status" -eq 1 ]
+  [[ "${output}" == '{"template":"<arg>","line":1,"column":4,"message":"map has no entry for key \"nope\"","source":"{{ .nope }}"}' ]]
+}
+
+@test "rejects unknown error formats" {
+  gomplate -i 'x' --error-format xml
+  [ "$status" -eq 1 ]
+  [[ "${output}" == "Error: invalid --error-format \"xml\": must be text or json"* ]]
+}
+
+@test "datasource parse errors are reported without usage" {
+  echo '{"a":' > $tmpdir/bad.json
+  gomplate -d bad=$tmpdir/bad.json -i '{{ ds "bad" }}'
+  [ "$status" -eq 1 ]
+  [[ "${output}" == *"error calling ds: Couldn't parse datasource 'bad'"* ]]
+  [[ "${output}" == *"reading datasource 'bad'"* ]]
+  [[ "${output}" != *"Usage:"* ]]
+}
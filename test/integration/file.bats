@@ -0,0 +1,49 @@
+#!/usr/bin/env bats
+
+load helper
+
+tmpdir=$(mktemp -u)
+
+function setup () {
+  mkdir -p $tmpdir/root/sub
+  echo "hello" > $tmpdir/root/a.txt
+  echo "world" > $tmpdir/root/sub/b.txt
+  echo "hunter2" > $tmpdir/secret
+}
+
+function teardown () {
+  rm -rf $tmpdir || true
+}
+
+@test "'file.Read' and 'file.Walk'" {
+  cd $tmpdir
+  gomplate -i '{{ file.Read "root/a.txt" }}{{ range file.Walk "root" }}{{ filepath.Rel "root" . }} {{ end }}'
+  [ "$status" -eq 0 ]
+  [[ "${output}" == "hello
+. a.txt sub sub/b.txt " ]]
+}
+
+@test "'file.Read' outside of --file-root" {
+  cd $tmpdir
+  gomplate --file-root root -i '{{ file.Read "secret" }}'
+  [ "$status" -eq 1 ]
+  [[ "${output}" == *"access to secret denied"* ]]
+}
+
+@test "'file.Write' writes extra outputs in --output-dir" {
+  mkdir -p $tmpdir/in
+  echo '{{ range coll.List "a" "b" }}{{ file.Write (printf "gen/%s.txt" .) . }}{{ end }}main' > $tmpdir/in/main.tmpl
+  gomplate --input-dir $tmpdir/in --output-dir $tmpdir/out
+  [ "$status" -eq 0 ]
+  [[ "$(cat $tmpdir/out/main.tmpl)" == "main" ]]
+  [[ "$(cat $tmpdir/out/gen/a.txt)" == "a" ]]
+  [[ "$(cat $tmpdir/out/gen/b.txt)" == "b" ]]
+}
+
+@test "'file.Write' refuses to write outside the output directory" {
+  cd $tmpdir/root
+  gomplate -i '{{ file.Write "../escaped.txt" "x" }}'
+  [ "$status" -eq 1 ]
+  [[ "${output}" == *"refusing to write ../escaped.txt"* ]]
+  [ ! -f $tmpdir/escaped.txt ]
+}
@@ -0,0 +1,71 @@
+#!/usr/bin/env bats
+
+load helper
+
+tmpdir=$(mktemp -u)
+
+function setup () {
+  mkdir -p $tmpdir/in
+  echo '{"name": "api"}' > $tmpdir/in/svc.json
+}
+
+function teardown () {
+  rm -rf $tmpdir || true
+}
+
+@test "front matter sets the output path, mode, datasources and context" {
+  cat > $tmpdir/in/a.tmpl <<'TMPL'
+---
+datasources:
+  svc: svc.json
+context:
+  replicas: 2
+out: gen/a.yaml
+mode: "0600"
+---
+{{ (ds "svc").name }} {{ .replicas }}
+TMPL
+  gomplate --input-dir $tmpdir/in --output-dir $tmpdir/out --exclude $tmpdir/in/svc.json
+  [ "$status" -eq 0 ]
+  [[ "$(cat $tmpdir/out/gen/a.yaml)" == "api 2" ]]
+  [[ "$(stat -c %a $tmpdir/out/gen/a.yaml)" == "600" ]]
+}
+
+@test "YAML document separators aren't front matter" {
+  gomplate -i '---
+foo: bar
+---
+baz: {{ "qux" }}'
+  [ "$status" -eq 0 ]
+  [[ "${output}" == "---
+foo: bar
+---
+baz: qux" ]]
+}
+
+@test "front matter datasources are only available to their template" {
+  mkdir -p $tmpdir/in/a $tmpdir/in/b
+  echo '{"name": "a"}' > $tmpdir/in/a/config.json
+  echo '{"name": "b"}' > $tmpdir/in/b/config.json
+  for d in a b; do
+    printf -- '---\ndatasources:\n  config: config.json\n---\n{{ (ds "config").name }}' > $tmpdir/in/$d/t.tmpl
+  done
+  gomplate --input-dir $tmpdir/in --output-dir $tmpdir/out --include '*.tmpl'
+  [ "$status" -eq 0 ]
+  [[ "$(cat $tmpdir/out/a/t.tmpl)" == "a" ]]
+  [[ "$(cat $tmpdir/out/b/t.tmpl)" == "b" ]]
+}
+
+@test "misspelled front matter settings are errors" {
+  printf -- '---\nout: a.yaml\nmdoe: "0600"\n---\nhello' > $tmpdir/in/a.tmpl
+  gomplate -f $tmpdir/in/a.tmpl
+  [ "$status" -eq 1 ]
+  [[ "${output}" == *"unknown setting(s) mdoe"* ]]
+}
+
+@test "front matter out can't override --out" {
+  printf -- '---\nout: a.yaml\n---\nhello' > $tmpdir/in/a.tmpl
+  gomplate -f $tmpdir/in/a.tmpl -o $tmpdir/out.txt
+  [ "$status" -eq 1 ]
+  [[ "${output}" == *"out can't be set when --out is given"* ]]
+}
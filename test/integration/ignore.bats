@@ -0,0 +1,42 @@
+#!/usr/bin/env bats
+
+load helper
+
+tmpdir=$(mktemp -d)
+
+function setup () {
+  rm -rf $tmpdir/in $tmpdir/out
+  mkdir -p $tmpdir/in/sub/drafts $tmpdir/in/drafts
+  echo -n "{{ 1 }}" > $tmpdir/in/a.tmpl
+  echo -n "{{ 2 }}" > $tmpdir/in/b.bak
+  echo -n "{{ 3 }}" > $tmpdir/in/keep.bak
+  echo -n "{{ 4 }}" > $tmpdir/in/drafts/c.tmpl
+  echo -n "{{ 5 }}" > $tmpdir/in/sub/d.tmpl
+  echo -n "{{ 6 }}" > $tmpdir/in/sub/e.txt
+  echo -n "{{ 7 }}" > $tmpdir/in/sub/drafts/f.tmpl
+  printf '# backups\n*.bak\n!keep.bak\n/drafts/\n' > $tmpdir/in/.gomplateignore
+  printf '*.txt\n' > $tmpdir/in/sub/.gomplateignore
+}
+
+function teardown () {
+  rm -rf $tmpdir
+}
+
+@test "honours .gomplateignore files" {
+  gomplate --input-dir $tmpdir/in --output-dir $tmpdir/out
+  [ "$status" -eq 0 ]
+  [[ "$(cd $tmpdir/out && find . -type f | sort | xargs)" == "./a.tmpl ./keep.bak ./sub/d.tmpl ./sub/drafts/f.tmpl" ]]
+  [[ "$(cat $tmpdir/out/sub/d.tmpl)" == "5" ]]
+}
+
+@test "--exclude supports ** and directories" {
+  gomplate --input-dir $tmpdir/in --output-dir $tmpdir/out --exclude '**/*.tmpl' --exclude 'sub/'
+  [ "$status" -eq 0 ]
+  [[ "$(cd $tmpdir/out && find . -type f | sort | xargs)" == "./keep.bak" ]]
+}
+
+@test "--include only processes matching files" {
+  gomplate --input-dir $tmpdir/in --output-dir $tmpdir/out --include '*.tmpl'
+  [ "$status" -eq 0 ]
+  [[ "$(cd $tmpdir/out && find . -type f | sort | xargs)" == "./a.tmpl ./sub/d.tmpl ./sub/drafts/f.tmpl" ]]
+}
@@ -0,0 +1,43 @@
+#!/usr/bin/env bats
+
+load helper
+
+tmpdir=$(mktemp -d)
+
+function setup () {
+  rm -rf $tmpdir/in
+  mkdir -p $tmpdir/in
+  echo '{{ (ds "cfg").name }}' > $tmpdir/in/good.tmpl
+  printf 'ok\n{{ nope 1 }} {{ ds "missing" }}\n' > $tmpdir/in/bad.tmpl
+}
+
+function teardown () {
+  rm -rf $tmpdir
+}
+
+@test "lint passes valid templates" {
+  gomplate lint -d cfg=$tmpdir/cfg.json $tmpdir/in/good.tmpl
+  [ "$status" -eq 0 ]
+  [[ "${output}" == "" ]]
+}
+
+@test "lint reports undefined functions and datasources" {
+  gomplate lint -d cfg=$tmpdir/cfg.json --input-dir $tmpdir/in
+  [ "$status" -eq 1 ]
+  [[ "${lines[0]}" == "$tmpdir/in/bad.tmpl:2:4: error: function \"nope\" not defined" ]]
+  [[ "${lines[1]}" == "$tmpdir/in/bad.tmpl:2:20: error: undefined datasource 'missing'" ]]
+  [[ "${lines[2]}" == "2 error(s) found" ]]
+  [ ! -e "$tmpdir/cfg.json" ]
+}
+
+@test "lint reports syntax errors with a column" {
+  gomplate lint -i '{{ if true }}{{ end }}{{ end }}'
+  [ "$status" -eq 1 ]
+  [[ "${lines[0]}" == "<arg>:1:23: error: unexpected {{end}}" ]]
+}
+
+@test "lint warns about unused datasources" {
+  gomplate lint -d cfg=$tmpdir/cfg.json -d extra=$tmpdir/extra.json $tmpdir/in/good.tmpl
+  [ "$status" -eq 0 ]
+  [[ "${output}" == "warning: datasource 'extra' is never used" ]]
+}
@@ -23,7 +23,7 @@ load helper
 @test "'math.Div'" {
   gomplate -i '{{ math.Div 5 3 }} {{ div -5 5 }}'
   [ "$status" -eq 0 ]
-  [[ "${output}" == "1 -1" ]]
+  [[ "${output}" == "1.6666666666666667 -1" ]]
 }
 
 @test "'math.Rem'" {
@@ -42,4 +42,10 @@ load helper
   gomplate -i '{{ math.Seq 0 }}, {{ seq 0 3 }}, {{ seq -5 -10 2 }}'
   [ "$status" -eq 0 ]
   [[ "${output}" == "[1 0], [0 1 2 3], [-5 -7 -9]" ]]
-}
\ No newline at end of file
+}
+
+@test "floating-point math" {
+  gomplate -i '{{ math.Add 1.5 2 }} {{ div 7 2 }} {{ math.Round 2.5 }} {{ math.Max 1 2.5 }} {{ math.Sqrt 16 }} {{ math.IsFloat "1.5" }}'
+  [ "$status" -eq 0 ]
+  [[ "${output}" == "3.5 3.5 3 2.5 4 true" ]]
+}
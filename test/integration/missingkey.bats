@@ -0,0 +1,40 @@
+#!/usr/bin/env bats
+
+load helper
+
+@test "missing keys are errors by default" {
+  gomplate -i '{{ .foo }}'
+  [ "$status" -eq 1 ]
+  [[ "${output}" == *'Error: template: <arg>:1:4: map has no entry for key "foo"'* ]]
+}
+
+@test "'--missing-key zero' renders missing keys" {
+  gomplate --missing-key zero -i '[{{ .foo }}]'
+  [ "$status" -eq 0 ]
+  [[ "${output}" == "[<no value>]" ]]
+}
+
+@test "rejects unknown --missing-key modes" {
+  gomplate --missing-key nope -i 'x'
+  [ "$status" -eq 1 ]
+  [[ "${output}" == 'Error: invalid --missing-key "nope": must be error, zero, or default'* ]]
+}
+
+@test "'default' handles missing keys with '--missing-key zero'" {
+  gomplate --missing-key zero --set port=443 -i '{{ .host | default "localhost" }}:{{ default 80 .port }}'
+  [ "$status" -eq 0 ]
+  [[ "${output}" == "localhost:443" ]]
+}
+
+@test "'required' fails with the given message" {
+  gomplate --missing-key zero -i '{{ .name | required "name must be set" }}'
+  [ "$status" -eq 1 ]
+  [[ "${output}" == *'Error: template: <arg>:1:12: error calling required: name must be set'* ]]
+  [[ "${output}" == *'    |            ^'* ]]
+}
+
+@test "missing keys given to 'default' are errors by default" {
+  gomplate -i '{{ .host | default "localhost" }}'
+  [ "$status" -eq 1 ]
+  [[ "${output}" == *'Error: template: <arg>:1:4: map has no entry for key "host"'* ]]
+}
@@ -0,0 +1,25 @@
+#!/usr/bin/env bats
+
+load helper
+
+@test "'random.AlphaNum' is repeatable with --seed" {
+  gomplate --seed 42 -i '{{ random.AlphaNum 12 }}'
+  [ "$status" -eq 0 ]
+  first="${output}"
+  GOMPLATE_SEED=42 gomplate -i '{{ random.AlphaNum 12 }}'
+  [ "$status" -eq 0 ]
+  [[ "${output}" == "${first}" ]]
+  [[ "${output}" =~ ^[a-zA-Z0-9]{12}$ ]]
+}
+
+@test "'uuid.V4'" {
+  gomplate -i '{{ uuid.V4 }}'
+  [ "$status" -eq 0 ]
+  [[ "${output}" =~ ^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$ ]]
+}
+
+@test "invalid --seed" {
+  gomplate --seed foo -i 'hi'
+  [ "$status" -eq 1 ]
+  [[ "${output}" == *"invalid seed"* ]]
+}
@@ -17,3 +17,15 @@ function teardown () {
   [ "$status" -eq 0 ]
   [[ "${output}" == "1.2.3.59" ]]
 }
+
+@test "'regexp.FindSubmatch'" {
+  gomplate -i '{{ $m := "jo@example.com" | regexp.FindSubmatch `(?P<user>\w+)@(?P<host>[\w.]+)` }}{{ $m.user }} at {{ $m.host }}'
+  [ "$status" -eq 0 ]
+  [[ "${output}" == "jo at example.com" ]]
+}
+
+@test "'regexp.Match' with invalid expression" {
+  gomplate -i '{{ regexp.Match "(" "foo" }}'
+  [ "$status" -eq 1 ]
+  [[ "${output}" == *"missing closing )"* ]]
+}
@@ -28,3 +28,19 @@ function teardown () {
     hello
      world" ]]
 }
+
+@test "'strings.WordWrap' and case conversion" {
+  gomplate -i '{{ "the quick brown fox jumps over the lazy dog" | strings.WordWrap 20 }}
+{{ "Hello, World!" | strings.Slug }} {{ "Hello World" | strings.CamelCase }} {{ "HelloWorld" | strings.SnakeCase }}'
+  [ "$status" -eq 0 ]
+  [[ "${output}" == "the quick brown fox
+jumps over the lazy
+dog
+hello-world helloWorld hello_world" ]]
+}
+
+@test "'strings.ShellQuote'" {
+  gomplate -i "{{ \"it's a test\" | shellQuote }} {{ coll.List \"a b\" \"c\" | strings.ShellQuote }}"
+  [ "$status" -eq 0 ]
+  [[ "${output}" == "'it'\"'\"'s a test' 'a b' 'c'" ]]
+}
@@ -0,0 +1,35 @@
+#!/usr/bin/env bats
+
+load helper
+
+tmpdir=$(mktemp -u)
+
+function setup () {
+  mkdir -p $tmpdir/in/sub
+  echo '# from {{ tmpl.Path }}' > $tmpdir/in/sub/a.tmpl
+}
+
+function teardown () {
+  rm -rf $tmpdir || true
+}
+
+@test "'tmpl.Exec' output can be piped" {
+  gomplate -i '{{ define "T" }}a
+b{{ end }}{{ tmpl.Exec "T" | strings.Indent 2 }}'
+  [ "$status" -eq 0 ]
+  [[ "${output}" == "  a
+  b" ]]
+}
+
+@test "'tmpl.Inline'" {
+  gomplate -i '{{ tmpl.Inline "{{ . | toUpper }}" "hello" }}'
+  [ "$status" -eq 0 ]
+  [[ "${output}" == "HELLO" ]]
+}
+
+@test "'tmpl.Path'" {
+  cd $tmpdir
+  gomplate --input-dir in --output-dir out
+  [ "$status" -eq 0 ]
+  [[ "$(cat out/sub/a.tmpl)" == "# from in/sub/a.tmpl" ]]
+}
@@ -0,0 +1,42 @@
+#!/usr/bin/env bats
+
+load helper
+
+tmpdir=$(mktemp -d)
+
+function setup () {
+  rm -rf $tmpdir/in $tmpdir/out
+  mkdir -p $tmpdir/in/static/dir
+  echo -n '{{ "rendered" }}' > $tmpdir/in/a.tmpl
+  echo -n '{{ literal' > $tmpdir/in/static/b.txt
+  chmod 0700 $tmpdir/in/static/b.txt
+  touch -d '2015-05-05 05:05:05' $tmpdir/in/static/b.txt
+  printf 'GIF89a\000\001' > $tmpdir/in/logo.gif
+  ln -s dir $tmpdir/in/static/link
+}
+
+function teardown () {
+  rm -rf $tmpdir
+}
+
+@test "copies --verbatim files as-is" {
+  gomplate --input-dir $tmpdir/in --output-dir $tmpdir/out --verbatim 'static/**'
+  [ "$status" -eq 0 ]
+  [[ "$(cat $tmpdir/out/a.tmpl)" == "rendered" ]]
+  [[ "$(cat $tmpdir/out/static/b.txt)" == "{{ literal" ]]
+  [[ "$(stat -c %a $tmpdir/out/static/b.txt)" == "700" ]]
+  [[ "$(stat -c %Y $tmpdir/out/static/b.txt)" == "$(stat -c %Y $tmpdir/in/static/b.txt)" ]]
+  [[ "$(readlink $tmpdir/out/static/link)" == "dir" ]]
+}
+
+@test "copies binary files as-is" {
+  gomplate --input-dir $tmpdir/in --output-dir $tmpdir/out --exclude static/
+  [ "$status" -eq 0 ]
+  cmp $tmpdir/in/logo.gif $tmpdir/out/logo.gif
+}
+
+@test "fails on unparseable files without --verbatim" {
+  gomplate --input-dir $tmpdir/in --output-dir $tmpdir/out
+  [ "$status" -eq 1 ]
+  [[ "${output}" == *"b.txt"* ]]
+}
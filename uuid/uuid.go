@@ -0,0 +1,145 @@
+// Package uuid generates and parses RFC 4122 UUIDs
+package uuid
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hairyhenderson/gomplate/random"
+)
+
+// UUID - an RFC 4122 UUID
+type UUID [16]byte
+
+// Nil - the nil UUID, with all bits set to zero
+var Nil UUID
+
+// the number of 100ns intervals between the UUID epoch (1582-10-15) and the
+// Unix epoch
+const epochOffset = 122192928000000000
+
+var (
+	v1Mu       sync.Mutex
+	v1Node     []byte
+	v1ClockSeq uint16
+	v1Last     uint64
+)
+
+// String - the canonical form, like 6ba7b810-9dad-11d1-80b4-00c04fd430c8
+func (u UUID) String() string {
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf)
+}
+
+// Version - the UUID version (1-5, or 0 for the nil UUID)
+func (u UUID) Version() int {
+	return int(u[6] >> 4)
+}
+
+// Variant - the UUID variant: "RFC4122", "NCS", "Microsoft" or "Future"
+func (u UUID) Variant() string {
+	switch {
+	case u[8]&0x80 == 0:
+		return "NCS"
+	case u[8]&0xc0 == 0x80:
+		return "RFC4122"
+	case u[8]&0xe0 == 0xc0:
+		return "Microsoft"
+	}
+	return "Future"
+}
+
+// V4 - generate a random (version 4) UUID
+func V4() (UUID, error) {
+	var u UUID
+	if _, err := random.Read(u[:]); err != nil {
+		return Nil, err
+	}
+	u.setVersion(4)
+	return u, nil
+}
+
+// V1 - generate a time-based (version 1) UUID. Rather than exposing a MAC
+// address, a random node ID is used (with the multicast bit set, as RFC 4122
+// section 4.5 recommends), chosen once per process.
+func V1() (UUID, error) {
+	v1Mu.Lock()
+	defer v1Mu.Unlock()
+	if v1Node == nil {
+		b := make([]byte, 8)
+		if _, err := random.Read(b); err != nil {
+			return Nil, err
+		}
+		b[0] |= 0x01
+		v1Node = b[:6]
+		v1ClockSeq = uint16(b[6])<<8 | uint16(b[7])
+	}
+	now := uint64(time.Now().UnixNano()/100) + epochOffset
+	if now <= v1Last {
+		// the clock hasn't advanced (or went backwards), so change the
+		// clock sequence to avoid generating a duplicate
+		v1ClockSeq++
+	}
+	v1Last = now
+
+	var u UUID
+	u[0], u[1], u[2], u[3] = byte(now>>24), byte(now>>16), byte(now>>8), byte(now)
+	u[4], u[5] = byte(now>>40), byte(now>>32)
+	u[6], u[7] = byte(now>>56), byte(now>>48)
+	u[8], u[9] = byte(v1ClockSeq>>8), byte(v1ClockSeq)
+	copy(u[10:], v1Node)
+	u.setVersion(1)
+	return u, nil
+}
+
+func (u *UUID) setVersion(v byte) {
+	u[6] = u[6]&0x0f | v<<4
+	u[8] = u[8]&0x3f | 0x80
+}
+
+// Parse - parse a UUID in canonical form, or with a "urn:uuid:" prefix,
+// surrounding braces, or without hyphens
+func Parse(s string) (UUID, error) {
+	in := s
+	if strings.HasPrefix(strings.ToLower(in), "urn:uuid:") {
+		in = in[9:]
+	} else if strings.HasPrefix(in, "{") && strings.HasSuffix(in, "}") {
+		in = in[1 : len(in)-1]
+	}
+	switch len(in) {
+	case 36:
+		if in[8] != '-' || in[13] != '-' || in[18] != '-' || in[23] != '-' {
+			return Nil, fmt.Errorf("invalid UUID %q", s)
+		}
+		in = strings.Replace(in, "-", "", -1)
+	case 32:
+	default:
+		return Nil, fmt.Errorf("invalid UUID %q: wrong length", s)
+	}
+	var u UUID
+	if len(in) != 32 {
+		return Nil, fmt.Errorf("invalid UUID %q", s)
+	}
+	if _, err := hex.Decode(u[:], []byte(in)); err != nil {
+		return Nil, fmt.Errorf("invalid UUID %q: %v", s, err)
+	}
+	return u, nil
+}
+
+// IsValid - whether the string can be parsed as a UUID
+func IsValid(s string) bool {
+	_, err := Parse(s)
+	return err == nil
+}
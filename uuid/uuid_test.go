@@ -0,0 +1,73 @@
+package uuid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV4(t *testing.T) {
+	u, err := V4()
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, u.String())
+	assert.Equal(t, 4, u.Version())
+	assert.Equal(t, "RFC4122", u.Variant())
+
+	u2, err := V4()
+	assert.NoError(t, err)
+	assert.NotEqual(t, u, u2)
+}
+
+func TestV1(t *testing.T) {
+	u, err := V1()
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-1[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, u.String())
+	assert.Equal(t, 1, u.Version())
+	assert.Equal(t, "RFC4122", u.Variant())
+	// the multicast bit is set on random node IDs
+	assert.Equal(t, byte(1), u[10]&0x01)
+
+	seen := map[UUID]bool{u: true}
+	for i := 0; i < 100; i++ {
+		u, err = V1()
+		assert.NoError(t, err)
+		assert.False(t, seen[u], "duplicate UUID %s", u)
+		seen[u] = true
+	}
+}
+
+func TestNil(t *testing.T) {
+	assert.Equal(t, "00000000-0000-0000-0000-000000000000", Nil.String())
+	assert.Equal(t, 0, Nil.Version())
+	assert.Equal(t, "NCS", Nil.Variant())
+}
+
+func TestParse(t *testing.T) {
+	expected := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	for _, in := range []string{
+		expected,
+		"6BA7B810-9DAD-11D1-80B4-00C04FD430C8",
+		"urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"{6ba7b810-9dad-11d1-80b4-00c04fd430c8}",
+		"6ba7b8109dad11d180b400c04fd430c8",
+	} {
+		u, err := Parse(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, expected, u.String())
+		assert.Equal(t, 1, u.Version())
+		assert.True(t, IsValid(in))
+	}
+
+	for _, in := range []string{
+		"",
+		"foo",
+		"6ba7b810-9dad-11d1-80b4-00c04fd430c",
+		"6ba7b810x9dad-11d1-80b4-00c04fd430c8",
+		"6ba7b810-9dad-11d1-80b4-00c04fd430cg",
+		"6ba7b8-10-9dad-11d1-80b400c04fd430c8",
+	} {
+		_, err := Parse(in)
+		assert.Error(t, err, in)
+		assert.False(t, IsValid(in))
+	}
+}
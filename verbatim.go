@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/spf13/afero"
+)
+
+// binarySniffLen - how much of a file is checked for NUL bytes when deciding
+// whether it's binary (the same heuristic git uses)
+const binarySniffLen = 8000
+
+// verbatimFile - a file found in the input directory that's copied as-is,
+// rather than rendered as a template
+type verbatimFile struct {
+	in   string
+	out  string
+	info os.FileInfo
+}
+
+// isBinary - whether the file looks like binary content, and so can't be a
+// template
+func isBinary(filename string) (bool, error) {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return false, err
+	}
+	// nolint: errcheck
+	defer f.Close()
+	buf := make([]byte, binarySniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	return bytes.IndexByte(buf[:n], 0) >= 0, nil
+}
+
+// isSymlink - whether the file info describes a symbolic link
+func isSymlink(fi os.FileInfo) bool {
+	return fi.Mode()&os.ModeSymlink != 0
+}
+
+// copy - copy the file to its output path byte-for-byte, preserving its mode
+// and modification time. Symbolic links are recreated, pointing at the same
+// target.
+func (v verbatimFile) copy() error {
+	if isSymlink(v.info) {
+		return v.copySymlink()
+	}
+	in, err := fs.Open(v.in)
+	if err != nil {
+		return err
+	}
+	// nolint: errcheck
+	defer in.Close()
+
+	out, err := fs.OpenFile(v.out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, v.info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		// nolint: errcheck
+		out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	// the file may have already existed, or the mode may have been masked
+	if err = fs.Chmod(v.out, v.info.Mode().Perm()); err != nil {
+		return err
+	}
+	return fs.Chtimes(v.out, v.info.ModTime(), v.info.ModTime())
+}
+
+// copySymlink - afero doesn't support symbolic links, and they're only found
+// on the OS filesystem, so this works with it directly
+func (v verbatimFile) copySymlink() error {
+	if _, ok := fs.(*afero.OsFs); !ok {
+		return nil
+	}
+	target, err := os.Readlink(v.in)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(v.out); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(target, v.out)
+}
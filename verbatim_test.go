@@ -0,0 +1,105 @@
+// +build !windows
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBinary(t *testing.T) {
+	origfs := fs
+	defer func() { fs = origfs }()
+	fs = afero.NewMemMapFs()
+
+	afero.WriteFile(fs, "text", []byte("hello {{ world }}\n"), 0644)
+	afero.WriteFile(fs, "empty", []byte{}, 0644)
+	afero.WriteFile(fs, "bin", []byte("GIF89a\x00\x01"), 0644)
+	// only the start of the file is checked
+	afero.WriteFile(fs, "late", append([]byte(strings.Repeat("a", binarySniffLen)), 0), 0644)
+
+	b, err := isBinary("text")
+	assert.NoError(t, err)
+	assert.False(t, b)
+
+	b, err = isBinary("empty")
+	assert.NoError(t, err)
+	assert.False(t, b)
+
+	b, err = isBinary("bin")
+	assert.NoError(t, err)
+	assert.True(t, b)
+
+	b, err = isBinary("late")
+	assert.NoError(t, err)
+	assert.False(t, b)
+
+	_, err = isBinary("missing")
+	assert.Error(t, err)
+}
+
+func TestVerbatimCopySymlink(t *testing.T) {
+	origfs := fs
+	defer func() { fs = origfs }()
+	fs = afero.NewOsFs()
+
+	dir, err := ioutil.TempDir("", "gomplate-verbatim")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	in := filepath.Join(dir, "in")
+	assert.NoError(t, os.MkdirAll(filepath.Join(in, "target"), 0755))
+	assert.NoError(t, os.Symlink("target", filepath.Join(in, "link")))
+
+	_, _, copies, err := walkDir(in, filepath.Join(dir, "out"), nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, copies, 1)
+	assert.NoError(t, copies[0].copy())
+
+	target, err := os.Readlink(filepath.Join(dir, "out", "link"))
+	assert.NoError(t, err)
+	assert.Equal(t, "target", target)
+
+	// copying again replaces the link
+	assert.NoError(t, copies[0].copy())
+}
+
+func TestVerbatimCopiedAfterRendering(t *testing.T) {
+	origfs := fs
+	defer func() { fs = origfs }()
+	fs = afero.NewMemMapFs()
+
+	_ = fs.MkdirAll("/in", 0755)
+	_ = fs.MkdirAll("/out", 0755)
+	afero.WriteFile(fs, "/in/bin", []byte("GIF89a\x00\x01"), 0644)
+	afero.WriteFile(fs, "/in/literal.txt", []byte("{{ not rendered }}"), 0644)
+	afero.WriteFile(fs, "/in/tmpl.txt", []byte(`{{ .missing }}`), 0644)
+
+	o := &GomplateOpts{
+		inputDir:     "/in",
+		outputDir:    "/out",
+		verbatimGlob: []string{"literal.txt"},
+		lDelim:       "{{",
+		rDelim:       "}}",
+	}
+	assert.Error(t, runTemplate(o))
+	for _, name := range []string{"/out/bin", "/out/literal.txt"} {
+		_, err := fs.Stat(name)
+		assert.True(t, os.IsNotExist(err), name)
+	}
+
+	afero.WriteFile(fs, "/in/tmpl.txt", []byte(`{{ "ok" }}`), 0644)
+	o.inputFiles, o.outputFiles = nil, nil
+	assert.NoError(t, runTemplate(o))
+	out, err := afero.ReadFile(fs, "/out/literal.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "{{ not rendered }}", string(out))
+	_, err = fs.Stat("/out/bin")
+	assert.NoError(t, err)
+}